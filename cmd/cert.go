@@ -1,28 +1,93 @@
 package cmd
 
 import (
+	"crypto/x509"
+
 	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/crl"
 	"github.com/marco-introini/certinfo/pkg/utils"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+var certCRLs []string
+var certCRLIssuers []string
+var certIssuer string
+
 var certCmd = &cobra.Command{
-	Use:   "cert [file]",
+	Use:   "cert [file...]",
 	Short: "Show detailed certificate information",
-	Long:  "Show detailed information about a single X.509 certificate file",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Show detailed information about every X.509 certificate in one or more files (- reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cert, err := certificate.ParseCertificate(args[0])
-		if err != nil {
-			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		var issuers []*x509.Certificate
+		if certIssuer != "" {
+			var err error
+			if issuers, err = certificate.LoadX509Certificates(certIssuer); err != nil {
+				os.Stderr.WriteString("Error: " + err.Error() + "\n")
+				os.Exit(1)
+			}
+		}
+		var set *crl.Set
+		if len(certCRLs) > 0 {
+			set = loadCRLSet(certCRLs, certCRLIssuers, issuers...)
+		}
+
+		var certs []*certificate.CertificateInfo
+		ok := eachInput(args, func(name string, data []byte) error {
+			fileCerts, err := certificate.ParseCertificatesFromBytes(data, name)
+			if err != nil {
+				return err
+			}
+			if set != nil {
+				for _, cert := range fileCerts {
+					set.Check(cert)
+				}
+			}
+			if issuers != nil {
+				if err := checkSignatures(data, name, issuers, fileCerts); err != nil {
+					return err
+				}
+			}
+			certs = append(certs, fileCerts...)
+			return nil
+		})
+
+		if len(certs) > 0 {
+			utils.PrintCertificateInfos(certs, utils.OutputFormat(format))
+		}
+		for _, cert := range certs {
+			// A certificate whose issuer was not supplied, as the leaf
+			// of a chain checked against the root, is not a failure.
+			if s := cert.Signature; s != nil && !s.IsValid() && s.Status != certificate.SignatureNoIssuer {
+				ok = false
+			}
+		}
+		if !ok {
 			os.Exit(1)
 		}
-		utils.PrintCertificateInfo(cert, utils.OutputFormat(format))
 	},
 }
 
+// checkSignatures verifies each certificate of data against the issuer
+// certificates.
+func checkSignatures(data []byte, name string, issuers []*x509.Certificate, certs []*certificate.CertificateInfo) error {
+	raw, err := certificate.LoadX509CertificatesFromBytes(data, name)
+	if err != nil {
+		return err
+	}
+	for i, cert := range raw {
+		if i < len(certs) {
+			certs[i].Signature = certificate.VerifySignatureWith(cert, issuers)
+		}
+	}
+	return nil
+}
+
 func init() {
+	certCmd.Flags().StringSliceVar(&certCRLs, "crl", nil, "CRL files to check the certificates against")
+	certCmd.Flags().StringSliceVar(&certCRLIssuers, "crl-issuer", nil, "CA certificate files to verify the CRL signatures with (the --issuer certificates are also used)")
+	certCmd.Flags().StringVar(&certIssuer, "issuer", "", "Issuer certificate file to verify the signatures against")
 	rootCmd.AddCommand(certCmd)
 }
@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
 	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -26,9 +34,16 @@ func getTestKeyPath(relPath string) string {
 }
 
 func runCertinfo(args ...string) (stdout, stderr string, exitCode int) {
+	return runCertinfoStdin(nil, args...)
+}
+
+func runCertinfoStdin(stdin []byte, args ...string) (stdout, stderr string, exitCode int) {
 	wd, _ := os.Getwd()
 	cmd := exec.Command("go", append([]string{"run", "./main.go"}, args...)...)
 	cmd.Dir = filepath.Join(wd, "..")
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
@@ -123,6 +138,34 @@ func TestCertCommandJSONFormat(t *testing.T) {
 	assert.Contains(t, stdout, `"KeyType": "RSA"`)
 }
 
+func TestCertCommandIssuer(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("cert", getTestCertPath("chain/server.crt"), "--issuer", getTestCertPath("chain/intermediate-ca.crt"))
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "Signature:")
+	assert.Contains(t, stdout, "valid")
+
+	stdout, _, exitCode = runCertinfo("cert", getTestCertPath("chain/server.crt"), "--issuer", getTestCertPath("chain/root-ca.crt"), "-f", "json")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, `"Status": "issuer not supplied"`)
+	assert.NotContains(t, stdout, `"Status": "invalid"`)
+
+	// The leaf's issuer is not in the root-only file; the intermediate's is.
+	stdout, _, exitCode = runCertinfo("cert", getTestCertPath("chain/fullchain.crt"), "--issuer", getTestCertPath("chain/root-ca.crt"), "-f", "json")
+	assert.Equal(t, 0, exitCode)
+	var certs []struct {
+		CommonName string
+		Signature  struct{ Status string }
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &certs))
+	require.Len(t, certs, 2)
+	assert.Equal(t, "issuer not supplied", certs[0].Signature.Status)
+	assert.Equal(t, "valid", certs[1].Signature.Status)
+
+	_, stderr, exitCode := runCertinfo("cert", getTestCertPath("chain/server.crt"), "--issuer", "missing.crt")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Error:")
+}
+
 func TestKeyCommand(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -229,6 +272,267 @@ func TestDirCommandRecursive(t *testing.T) {
 	assert.Contains(t, stdout, "server-ecdsa-p256.crt")
 }
 
+func TestDirCommandTrustedCertificate(t *testing.T) {
+	data, err := os.ReadFile(getTestCertPath("chain/root-ca.crt"))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	trusted := strings.ReplaceAll(string(data), "CERTIFICATE", "TRUSTED CERTIFICATE")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "root.pem"), []byte(trusted), 0644))
+
+	stdout, stderr, exitCode := runCertinfo("dir", dir, "--show-errors")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, "root.pem")
+	assert.NotContains(t, stdout+stderr, "no certificate found")
+}
+
+func TestDirCommandJobs(t *testing.T) {
+	sequential, _, exitCode := runCertinfo("dir", getTestCertPath(""), "-r", "-j", "1")
+	assert.Equal(t, 0, exitCode)
+	parallel, _, exitCode := runCertinfo("dir", getTestCertPath(""), "-r", "--jobs", "8")
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, sequential, parallel)
+}
+
+func TestDirCommandCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	cold, stderr, exitCode := runCertinfo("dir", getTestCertPath("traditional/rsa"), "--cache", cacheFile, "--no-color")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stderr, "Cache: 0 hits")
+
+	warm, stderr, exitCode := runCertinfo("dir", getTestCertPath("traditional/rsa"), "--cache", cacheFile, "--no-color")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stderr, "hits, 0 misses")
+	assert.Equal(t, cold, warm)
+
+	_, stderr, exitCode = runCertinfo("dir", getTestCertPath("traditional/rsa"), "--cache", cacheFile, "--no-cache")
+	assert.Equal(t, 0, exitCode)
+	assert.NotContains(t, stderr, "Cache:")
+}
+
+func TestDirCommandWalkFilters(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("dir", getTestCertPath(""), "--max-depth", "2", "--exclude", "ecdsa", "--include", "*.crt")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "fullchain.crt")
+	assert.NotContains(t, stdout, "server-rsa2048.crt", "files three levels down are not walked")
+
+	stdout, _, exitCode = runCertinfo("dir", getTestCertPath("traditional"), "-r", "--exclude", "ecdsa")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "server-rsa2048.crt")
+	assert.NotContains(t, stdout, "server-ecdsa-p256.crt")
+
+	dir := t.TempDir()
+	data, err := os.ReadFile(getTestCertPath("traditional/rsa/server-rsa2048.crt"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.crt"), data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fixture.crt"), data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".certinfoignore"), []byte("fixture.crt\n"), 0644))
+
+	stdout, _, exitCode = runCertinfo("dir", dir)
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "server.crt")
+	assert.NotContains(t, stdout, "fixture.crt")
+
+	stdout, stderr, exitCode := runCertinfo("dir", dir, "--max-file-size", "1K", "--show-errors")
+	assert.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout, "server.crt")
+	assert.Contains(t, stderr, "skipped")
+
+	_, stderr, exitCode = runCertinfo("dir", dir, "--max-file-size", "lots")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "invalid size")
+}
+
+func TestArchivesFlag(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"chain/server.crt", "chain/server.key"} {
+		data, err := os.ReadFile(getTestCertPath(name))
+		require.NoError(t, err)
+		w, err := zw.Create("WEB-INF/" + filepath.Base(name))
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.war"), buf.Bytes(), 0644))
+
+	stdout, _, exitCode := runCertinfo("dir", dir, "-c")
+	assert.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout, "localhost")
+
+	stdout, stderr, exitCode := runCertinfo("dir", dir, "--archives", "-c")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, "app.war!/WEB-INF/server.crt")
+	assert.Contains(t, stdout, "localhost")
+
+	stdout, stderr, exitCode = runCertinfo("keydir", dir, "--archives", "-c")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, "app.war!/WEB-INF/server.key")
+}
+
+// writeTar writes a tarball of the named files, in the order given.
+func writeTar(t *testing.T, w *bytes.Buffer, files ...string) {
+	tw := tar.NewWriter(w)
+	for i := 0; i < len(files); i += 2 {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: files[i], Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(files[i+1]))}))
+		_, err := tw.Write([]byte(files[i+1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+}
+
+func TestImageCommand(t *testing.T) {
+	cert, err := os.ReadFile(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+	key, err := os.ReadFile(getTestCertPath("chain/server.key"))
+	require.NoError(t, err)
+
+	var base, app, save bytes.Buffer
+	writeTar(t, &base, "etc/ssl/certs/server.pem", string(cert), "root/server.key", string(key), "opt/app.key", string(key))
+	writeTar(t, &app, "root/.wh.server.key", "")
+	writeTar(t, &save,
+		"manifest.json", `[{"Config":"config.json","RepoTags":["example/app:1.0"],"Layers":["base/layer.tar","app/layer.tar"]}]`,
+		"config.json", `{"history":[{"created_by":"ADD base"},{"created_by":"RUN cleanup"}]}`,
+		"base/layer.tar", base.String(),
+		"app/layer.tar", app.String(),
+	)
+	path := filepath.Join(t.TempDir(), "app.tar")
+	require.NoError(t, os.WriteFile(path, save.Bytes(), 0644))
+
+	stdout, stderr, exitCode := runCertinfo("image", path, "-c")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, "example/app:1.0")
+	assert.Contains(t, stdout, "/etc/ssl/certs/server.pem")
+	assert.Contains(t, stdout, "/opt/app.key")
+	assert.NotContains(t, stdout, "/root/server.key")
+
+	stdout, stderr, exitCode = runCertinfo("image", path, "--format", "json")
+	assert.Equal(t, 0, exitCode, stderr)
+	var result struct {
+		Image        string
+		Certificates []struct {
+			Layer    int
+			Filename string
+		}
+		Keys []struct {
+			Layer    int
+			Filename string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	require.Len(t, result.Certificates, 1)
+	assert.Equal(t, 1, result.Certificates[0].Layer)
+	require.Len(t, result.Keys, 1)
+	assert.Equal(t, "/opt/app.key", result.Keys[0].Filename)
+
+	_, stderr, exitCode = runCertinfo("image", getTestCertPath("chain/server.crt"))
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Error:")
+
+	_, stderr, exitCode = runCertinfo("image", path, "--cache", filepath.Join(t.TempDir(), "cache.json"))
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "--cache does not apply to image")
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{"": 0, "512": 512, "4K": 4096, "10m": 10 << 20, "1G": 1 << 30} {
+		got, err := parseSize(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	for _, s := range []string{"K", "-1", "ten"} {
+		_, err := parseSize(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDirCommandMinStrength(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("dir", getTestCertPath("traditional/rsa"), "--min-strength", "128")
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "STRENGTH")
+	assert.Contains(t, stdout, "server-rsa2048.crt")
+	assert.NotContains(t, stdout, "server-rsa3072.crt")
+}
+
+func TestKeydirCommandMinStrength(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("keydir", getTestKeyPath("traditional/rsa"), "--min-strength", "128")
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "server-rsa2048.key")
+	assert.NotContains(t, stdout, "server-rsa4096.key")
+}
+
+func TestPQCReportCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("pqc-report", getTestCertPath(""), "-p", "testpass", "--top", "3")
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "Quantum Ready:")
+	assert.Contains(t, stdout, "By issuer:")
+	assert.Contains(t, stdout, "Migrate first:")
+	assert.Contains(t, stdout, "\n3 ")
+	assert.NotContains(t, stdout, "\n4 ")
+
+	stdout, _, exitCode = runCertinfo("pqc-report", getTestCertPath(""), "-f", "json")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, `"MigrateFirst"`)
+
+	_, stderr, exitCode := runCertinfo("pqc-report", "/nonexistent/path")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Error:")
+}
+
+func TestScanCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("scan", getTestCertPath("crl"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "ca-der.crl")
+	assert.Contains(t, stdout, "good.ocsp")
+	assert.Contains(t, stdout, "4 crl")
+
+	stdout, _, exitCode = runCertinfo("scan", getTestCertPath(""), "-r", "-p", "testpass", "-f", "json")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, `"Kind": "pkcs12"`)
+	assert.Contains(t, stdout, `"Kind": "bundle"`)
+
+	_, stderr, exitCode := runCertinfo("scan", "/nonexistent/path")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Error:")
+}
+
+func TestScanCommandCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	cold, stderr, exitCode := runCertinfo("scan", getTestCertPath("crl"), "--cache", cacheFile, "--no-color")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stderr, "Cache: 0 hits")
+
+	warm, stderr, exitCode := runCertinfo("scan", getTestCertPath("crl"), "--cache", cacheFile, "--no-color")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stderr, "hits, 0 misses")
+	assert.Equal(t, cold, warm)
+}
+
+func TestShowErrors(t *testing.T) {
+	stdout, stderr, exitCode := runCertinfo("keydir", getTestKeyPath("traditional/rsa-encrypted"), "--no-color")
+	assert.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout, "ca-rsa2048-encrypted.key")
+	assert.Contains(t, stderr, "Warning: 1 files could not be read (1 encrypted)")
+
+	_, stderr, exitCode = runCertinfo("keydir", getTestKeyPath("traditional/rsa-encrypted"), "--show-errors")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stderr, "Skipped files:")
+	assert.Contains(t, stderr, "ca-rsa2048-encrypted.key")
+
+	stdout, stderr, exitCode = runCertinfo("dir", getTestCertPath("traditional/rsa"), "--show-errors")
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "server-rsa2048.crt")
+	assert.Contains(t, stderr, "server-rsa2048.key")
+	assert.Contains(t, stderr, "other type")
+}
+
 func TestKeydirCommand(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -302,7 +606,7 @@ func TestCertCommandHelp(t *testing.T) {
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout, "certinfo cert")
-	assert.Contains(t, stdout, "[file]")
+	assert.Contains(t, stdout, "[file...]")
 }
 
 func TestInvalidCommand(t *testing.T) {
@@ -330,3 +634,270 @@ func TestDirCommandEmptyDirectory(t *testing.T) {
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout, "FILENAME")
 }
+
+func TestVerifyCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("verify", getTestCertPath("chain/server.crt"),
+		"--intermediates", getTestCertPath("chain/intermediate-ca.crt"),
+		"--roots", getTestCertPath("chain/root-ca.crt"),
+		"--host", "localhost", "-c")
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "VALID")
+	assert.Contains(t, stdout, "Test Intermediate CA")
+	assert.Contains(t, stdout, "Test Root CA")
+}
+
+func TestVerifyCommandFailure(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("verify", getTestCertPath("chain/server.crt"),
+		"--intermediates", getTestCertPath("chain/intermediate-ca.crt"),
+		"--roots", getTestCertPath("chain/root-ca.crt"),
+		"--at", "+1000d", "-f", "json")
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, `"Valid": false`)
+	assert.Contains(t, stdout, `"Kind": "expired"`)
+}
+
+func TestCertCommandBundle(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("cert", getTestCertPath("chain/fullchain.crt"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "--- Certificate 1 ---")
+	assert.Contains(t, stdout, "--- Certificate 2 ---")
+	assert.Contains(t, stdout, "Test Intermediate CA")
+}
+
+func TestDirCommandSANFilter(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("dir", getTestCertPath("san-types"), "--san", "db.apps.test.local")
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "san-mixed.crt")
+	assert.NotContains(t, stdout, "san-rsa.crt")
+}
+
+func TestFingerprintCommand(t *testing.T) {
+	certOut, _, exitCode := runCertinfo("fingerprint", getTestCertPath("chain/server.crt"), "-f", "json")
+	assert.Equal(t, 0, exitCode)
+	keyOut, _, exitCode := runCertinfo("fingerprint", getTestCertPath("chain/server.key"), "-f", "json")
+	assert.Equal(t, 0, exitCode)
+
+	var certFps, keyFps []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(certOut), &certFps))
+	require.NoError(t, json.Unmarshal([]byte(keyOut), &keyFps))
+	require.Len(t, certFps, 1)
+	require.Len(t, keyFps, 1)
+	assert.Equal(t, "certificate", certFps[0]["Type"])
+	assert.Equal(t, "private key", keyFps[0]["Type"])
+	assert.Equal(t, certFps[0]["SPKISHA256"], keyFps[0]["SPKISHA256"])
+}
+
+func TestFingerprintCommandUnknownFile(t *testing.T) {
+	_, stderr, exitCode := runCertinfo("fingerprint", getTestCertPath("README.md"))
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "not a certificate")
+}
+
+func TestMatchCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("match", getTestCertPath("chain/server.crt"), getTestCertPath("chain/server.key"))
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "MATCH")
+
+	stdout, _, exitCode = runCertinfo("match", getTestCertPath("chain/server.crt"), getTestCertPath("chain/root-ca.key"))
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, "MISMATCH")
+}
+
+func TestMatchCommandDirectory(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("match", getTestCertPath("chain"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "intermediate-ca.key")
+	assert.Contains(t, stdout, "0 mismatched")
+
+	dir := t.TempDir()
+	data, err := os.ReadFile(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.crt"), data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.crt"), []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n"), 0644))
+
+	_, stderr, _ := runCertinfo("match", dir)
+	assert.Contains(t, stderr, "1 files could not be read")
+
+	_, stderr, _ = runCertinfo("match", dir, "--show-errors")
+	assert.Contains(t, stderr, "Skipped files:")
+	assert.Contains(t, stderr, "broken.crt")
+}
+
+func TestCSRCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("csr", getTestCertPath("csr/server-rsa.csr"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "csr.test.local")
+	assert.Contains(t, stdout, "Signature:")
+	assert.Contains(t, stdout, "Requested Extension:")
+}
+
+func TestCRLCommand(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("crl", getTestCertPath("crl/ca.crl"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "Test CRL CA")
+	assert.Contains(t, stdout, "keyCompromise")
+}
+
+func TestDirCommandCRL(t *testing.T) {
+	stdout, stderr, exitCode := runCertinfo("dir", getTestCertPath("crl"), "--crl", getTestCertPath("crl/stale.crl"))
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "revoked")
+	assert.Contains(t, stderr, "is stale")
+}
+
+func TestCertCommandCRLIssuer(t *testing.T) {
+	stdout, stderr, exitCode := runCertinfo("cert", getTestCertPath("crl/revoked.crt"), "--crl", getTestCertPath("crl/ca.crl"))
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "keyCompromise")
+	assert.Contains(t, stdout, "CRL signature not verified")
+	assert.Contains(t, stderr, "signature not verified")
+
+	stdout, stderr, exitCode = runCertinfo("cert", getTestCertPath("crl/revoked.crt"), "--crl", getTestCertPath("crl/ca.crl"), "--crl-issuer", getTestCertPath("crl/ca.crt"))
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "keyCompromise")
+	assert.NotContains(t, stdout, "not verified")
+	assert.Empty(t, stderr)
+
+	_, stderr, _ = runCertinfo("cert", getTestCertPath("crl/revoked.crt"), "--crl", getTestCertPath("crl/ca.crl"), "--crl-issuer", getTestCertPath("chain/root-ca.crt"))
+	assert.Contains(t, stderr, "see --crl-issuer")
+}
+
+func TestOCSPCommandResponseFile(t *testing.T) {
+	stdout, _, exitCode := runCertinfo("ocsp", getTestCertPath("crl/good.ocsp"), "--issuer", getTestCertPath("crl/ca.crt"))
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout, "good")
+	assert.Contains(t, stdout, "Responder ID:")
+
+	stdout, _, exitCode = runCertinfo("ocsp", getTestCertPath("crl/revoked.ocsp"), "--issuer", getTestCertPath("crl/ca.crt"))
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, "keyCompromise")
+}
+
+func TestRemoteCommand(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "https://")
+	stdout, _, exitCode := runCertinfo("remote", addr, "-f", "json")
+
+	assert.Equal(t, 0, exitCode)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "TLS 1.3", result["TLSVersion"])
+	assert.NotEmpty(t, result["Chain"])
+}
+
+func TestStartTLSCommand(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair(getTestCertPath("chain/server.crt"), getTestKeyPath("chain/server.key"))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("+OK POP3 ready\r\n"))
+		if _, err := r.ReadString('\n'); err != nil {
+			return
+		}
+		conn.Write([]byte("+OK Begin TLS negotiation\r\n"))
+		_ = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}}).Handshake()
+	}()
+
+	stdout, stderr, exitCode := runCertinfo("starttls", listener.Addr().String(), "--protocol", "pop3", "-f", "json")
+
+	assert.Equal(t, 0, exitCode, stderr)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "pop3", result["Protocol"])
+	assert.NotEmpty(t, result["Chain"])
+}
+
+func TestStartTLSCommandMissingProtocol(t *testing.T) {
+	_, _, exitCode := runCertinfo("starttls", "127.0.0.1:1")
+	assert.NotEqual(t, 0, exitCode)
+}
+
+func TestStdinInput(t *testing.T) {
+	data, err := os.ReadFile(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+	stdout, stderr, exitCode := runCertinfoStdin(data, "cert", "-")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, "(stdin)")
+	assert.Contains(t, stdout, "localhost")
+
+	data, err = os.ReadFile(getTestKeyPath("chain/server.key"))
+	require.NoError(t, err)
+	stdout, stderr, exitCode = runCertinfoStdin(data, "key", "-", "-f", "json")
+	assert.Equal(t, 0, exitCode, stderr)
+	var key map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &key))
+	assert.Equal(t, "(stdin)", key["Filename"])
+
+	_, stderr, exitCode = runCertinfoStdin([]byte("not a certificate"), "cert", "-")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "Error:")
+}
+
+func TestMultipleInputs(t *testing.T) {
+	stdout, stderr, exitCode := runCertinfo("cert", getTestCertPath("chain/server.crt"), getTestCertPath("client/client.crt"), "-f", "json")
+	assert.Equal(t, 0, exitCode, stderr)
+	var certs []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &certs))
+	require.Len(t, certs, 2)
+	assert.Equal(t, "testclient", certs[1]["CommonName"])
+
+	stdout, stderr, exitCode = runCertinfo("csr", getTestCertPath("csr/*.csr"), getTestCertPath("csr/*.der"), "-f", "json", "-c")
+	assert.Equal(t, 0, exitCode, stderr)
+	var reqs []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &reqs), "quoted globs are expanded")
+	assert.Len(t, reqs, 2)
+
+	stdout, stderr, exitCode = runCertinfo("cert", getTestCertPath("chain/root-ca.c*t"), "-f", "json")
+	assert.Equal(t, 0, exitCode, stderr)
+	certs = nil
+	require.NoError(t, json.Unmarshal([]byte(stdout), &certs), "a glob prints an array even when it matches one file")
+	require.Len(t, certs, 1)
+
+	stdout, stderr, exitCode = runCertinfo("cert", getTestCertPath("chain/root-ca.crt"), "-f", "json")
+	assert.Equal(t, 0, exitCode, stderr)
+	var cert map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &cert), "a single input prints a single object")
+	assert.Equal(t, "Test Root CA", cert["CommonName"])
+
+	stdout, stderr, exitCode = runCertinfo("key", getTestKeyPath("chain/server.key"), getTestKeyPath("missing.key"), "-c")
+	assert.Equal(t, 1, exitCode, "a missing input fails the command")
+	assert.Contains(t, stdout, "server.key", "the other inputs are still printed")
+	assert.Contains(t, stderr, "missing.key")
+
+	stdout, stderr, exitCode = runCertinfo("dir", getTestCertPath("chain"), getTestCertPath("client"), "-c")
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Contains(t, stdout, filepath.Join(getTestCertPath("chain"), "server.crt"), "the files of several roots are named by their full path")
+	assert.Contains(t, stdout, filepath.Join(getTestCertPath("client"), "client.crt"))
+}
+
+func TestExpandArgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.pem", "b.pem", "c.key"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	assert.Equal(t, []string{filepath.Join(dir, "a.pem"), filepath.Join(dir, "b.pem"), "-", filepath.Join(dir, "c.key")},
+		expandArgs([]string{filepath.Join(dir, "*.pem"), "-", filepath.Join(dir, "c.key")}))
+	assert.Equal(t, []string{filepath.Join(dir, "*.crt")}, expandArgs([]string{filepath.Join(dir, "*.crt")}), "a pattern matching nothing is kept")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/crl"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var crlCmd = &cobra.Command{
+	Use:   "crl [file...]",
+	Short: "Show certificate revocation list information",
+	Long:  "Show information about certificate revocation lists (CRLs) and their revoked entries, and check whether they are stale (- reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var lists []*crl.CRLInfo
+		ok := eachInput(args, func(name string, data []byte) error {
+			list, err := crl.ParseCRLFromBytes(data, name)
+			if err != nil {
+				return err
+			}
+			lists = append(lists, list)
+			return nil
+		})
+
+		if len(lists) > 0 {
+			utils.PrintCRLInfos(lists, utils.OutputFormat(format))
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadCRLSet loads the CRLs of --crl with the CA certificates of
+// --crl-issuer, and any other issuers, to verify their signatures. The CRLs
+// that are stale, unverified or ignored are reported on stderr.
+func loadCRLSet(paths, issuerPaths []string, issuers ...*x509.Certificate) *crl.Set {
+	set, err := crl.LoadSet(paths...)
+	if err != nil {
+		os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+	for _, path := range issuerPaths {
+		certs, err := certificate.LoadX509Certificates(path)
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+		issuers = append(issuers, certs...)
+	}
+	set.AddIssuers(issuers...)
+
+	for _, list := range set.Stale() {
+		fmt.Fprintf(os.Stderr, "Warning: CRL %s is stale (next update %s)\n", list.Filename, list.NextUpdate.Format("2006-01-02 15:04:05"))
+	}
+	for _, list := range set.Rejected() {
+		fmt.Fprintf(os.Stderr, "Warning: CRL %s is ignored: its signature does not verify with the issuer certificates\n", list.Filename)
+	}
+	for _, list := range set.Unverified() {
+		fmt.Fprintf(os.Stderr, "Warning: CRL %s signature not verified (no issuer certificate for %s, see --crl-issuer)\n", list.Filename, list.IssuerDN)
+	}
+	return set
+}
+
+func init() {
+	rootCmd.AddCommand(crlCmd)
+}
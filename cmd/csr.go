@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/csr"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var csrCmd = &cobra.Command{
+	Use:   "csr [file...]",
+	Short: "Show certificate signing request information",
+	Long:  "Show information about PKCS#10 certificate signing requests and check their self-signatures (- reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var reqs []*csr.CSRInfo
+		ok := eachInput(args, func(name string, data []byte) error {
+			req, err := csr.ParseCSRFromBytes(data, name)
+			if err != nil {
+				return err
+			}
+			reqs = append(reqs, req)
+			return nil
+		})
+
+		if len(reqs) > 0 {
+			utils.PrintCSRInfos(reqs, utils.OutputFormat(format))
+		}
+		for _, req := range reqs {
+			if !req.SignatureValid {
+				ok = false
+			}
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(csrCmd)
+}
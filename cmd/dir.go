@@ -2,37 +2,63 @@ package cmd
 
 import (
 	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
 	"github.com/marco-introini/certinfo/pkg/utils"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+var dirSAN string
+var dirCRLs []string
+var dirCRLIssuers []string
+var dirMinStrength int
+
 var dirCmd = &cobra.Command{
-	Use:   "dir [directory]",
+	Use:   "dir [directory...]",
 	Short: "Summarize certificates in a directory",
-	Long:  "Summarize all X.509 certificates in a directory (CN and expiration)",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Summarize all X.509 certificates in one or more directories or files (CN and expiration), one row per certificate",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var summaries []certificate.CertificateSummary
-		var err error
+		var diags []diag.Diagnostic
+		var filters []certificate.Filter
 
-		if recursive {
-			summaries, err = certificate.SummarizeDirectoryRecursive(args[0])
-		} else {
-			summaries, err = certificate.SummarizeDirectory(args[0])
+		if dirSAN != "" {
+			filters = append(filters, certificate.MatchingSAN(dirSAN))
+		}
+		if dirMinStrength > 0 {
+			filters = append(filters, certificate.WeakerThan(dirMinStrength))
 		}
 
-		if err != nil {
-			os.Stderr.WriteString("Error: " + err.Error() + "\n")
-			os.Exit(1)
+		opts := walkOptions()
+		opts.Cache = openCache()
+		opts.FullPaths = len(expandArgs(args)) > 1
+		ok := eachArg(args, func(root string) error {
+			s, d, err := certificate.SummarizeDirectoryContext(cmd.Context(), root, opts, filters...)
+			summaries, diags = append(summaries, s...), append(diags, d...)
+			return err
+		})
+
+		if len(dirCRLs) > 0 {
+			set := loadCRLSet(dirCRLs, dirCRLIssuers)
+			set.CheckSummaries(summaries)
 		}
 
 		utils.PrintCertificateSummaries(summaries, utils.OutputFormat(format))
+		utils.PrintDiagnostics(diags, utils.OutputFormat(format), showErrors)
+		saveCache(opts)
+		if !ok {
+			os.Exit(1)
+		}
 	},
 }
 
 func init() {
 	dirCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Search recursively")
+	dirCmd.Flags().StringVar(&dirSAN, "san", "", "Only list certificates whose SANs cover this name (DNS, IP, email or URI)")
+	dirCmd.Flags().StringSliceVar(&dirCRLs, "crl", nil, "CRL files to check the certificates against")
+	dirCmd.Flags().StringSliceVar(&dirCRLIssuers, "crl-issuer", nil, "CA certificate files to verify the CRL signatures with")
+	dirCmd.Flags().IntVar(&dirMinStrength, "min-strength", 0, "Only list certificates weaker than this many bits of security (key and signature hash)")
 	rootCmd.AddCommand(dirCmd)
 }
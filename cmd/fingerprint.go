@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pkcs12"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var fingerprintPassword string
+
+var fingerprintCmd = &cobra.Command{
+	Use:   "fingerprint [file...]",
+	Short: "Show fingerprints and SPKI pins",
+	Long:  "Show SHA-1/SHA-256 fingerprints and SHA-256 SPKI pins of certificates, private keys, CSRs and PKCS#12 files (- reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		password := fingerprintPassword
+		prompt := !usesStdin(args)
+
+		var fps []fingerprint.Fingerprint
+		ok := eachInput(args, func(name string, data []byte) error {
+			fileFps, err := collectFingerprints(data, name, &password, prompt)
+			if err != nil {
+				return err
+			}
+			fps = append(fps, fileFps...)
+			return nil
+		})
+
+		if len(fps) > 0 {
+			utils.PrintFingerprints(fps, utils.OutputFormat(format))
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func certificateFingerprint(cert *certificate.CertificateInfo, filename, fpType string) fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		Filename:      filename,
+		Type:          fpType,
+		Subject:       cert.Subject,
+		SHA1:          cert.SHA1Fingerprint,
+		SHA256:        cert.SHA256Fingerprint,
+		SPKISHA256:    cert.SPKISHA256,
+		SPKISHA256Hex: cert.SPKISHA256Hex,
+	}
+}
+
+func keyFingerprint(key *privatekey.KeyInfo, filename, fpType string) fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		Filename:      filename,
+		Type:          fpType,
+		SPKISHA256:    key.SPKISHA256,
+		SPKISHA256Hex: key.SPKISHA256Hex,
+	}
+}
+
+func csrFingerprint(data []byte, filename string) (fingerprint.Fingerprint, bool) {
+	der := data
+	if pem.IsPEM(data) {
+		var found bool
+		der, found = pem.FindBlock(data, pem.TypeCSR, pem.TypeNewCSR)
+		if !found {
+			return fingerprint.Fingerprint{}, false
+		}
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return fingerprint.Fingerprint{}, false
+	}
+
+	spki, spkiHex := fingerprint.SPKI(csr.RawSubjectPublicKeyInfo)
+	return fingerprint.Fingerprint{
+		Filename:      filename,
+		Type:          "certificate request",
+		Subject:       csr.Subject.String(),
+		SHA1:          fingerprint.SHA1(csr.Raw),
+		SHA256:        fingerprint.SHA256(csr.Raw),
+		SPKISHA256:    spki,
+		SPKISHA256Hex: spkiHex,
+	}, true
+}
+
+func isP12File(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".p12" || ext == ".pfx"
+}
+
+// collectFingerprints detects whether data holds certificates, a CSR, a
+// private key or a PKCS#12 file and returns the fingerprints of its contents.
+// The password of an encrypted key is prompted for when prompt is set, and
+// kept for the next files.
+func collectFingerprints(data []byte, filePath string, password *string, prompt bool) ([]fingerprint.Fingerprint, error) {
+	if !isP12File(filePath) {
+		if certs, err := certificate.ParseCertificatesFromBytes(data); err == nil {
+			fps := make([]fingerprint.Fingerprint, 0, len(certs))
+			for _, cert := range certs {
+				fps = append(fps, certificateFingerprint(cert, filePath, "certificate"))
+			}
+			return fps, nil
+		}
+
+		if fp, ok := csrFingerprint(data, filePath); ok {
+			return []fingerprint.Fingerprint{fp}, nil
+		}
+
+		key, err := privatekey.ParsePrivateKeyFromBytes(data, filePath, *password)
+		if err == privatekey.ErrEncryptedKey {
+			if !prompt {
+				return nil, err
+			}
+			*password = promptPassword("Enter password for encrypted key: ")
+			key, err = privatekey.ParsePrivateKeyFromBytes(data, filePath, *password)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err == nil && key.SPKISHA256 != "" {
+			return []fingerprint.Fingerprint{keyFingerprint(key, filePath, "private key")}, nil
+		}
+	}
+
+	p12, err := pkcs12.ParseP12FromBytes(data, filePath, *password)
+	if err != nil {
+		if isP12File(filePath) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: not a certificate, CSR, private key or PKCS#12 file", filePath)
+	}
+
+	var fps []fingerprint.Fingerprint
+	for _, c := range p12.Certificates {
+		fps = append(fps, certificateFingerprint(c.Cert, filePath, "PKCS#12 certificate"))
+	}
+	for _, k := range p12.PrivateKeys {
+		fps = append(fps, keyFingerprint(k.Key, filePath, "PKCS#12 private key"))
+	}
+	return fps, nil
+}
+
+func init() {
+	fingerprintCmd.Flags().StringVarP(&fingerprintPassword, "password", "p", "", "Password for encrypted private keys or PKCS#12 files")
+	rootCmd.AddCommand(fingerprintCmd)
+}
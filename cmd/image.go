@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/image"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var imagePassword string
+
+var imageCmd = &cobra.Command{
+	Use:   "image [oci-layout | docker-save.tar...]",
+	Short: "List the certificates and private keys in a container image",
+	Long:  "Read a docker save tarball, gzipped or not, or an OCI image layout directory or tarball offline, apply its layers in order honoring whiteout files, and list the certificates and private keys of the final filesystem with the layer that added each one",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// The files of an image are read from its layers, which have no
+		// path and modification time to cache them by.
+		for _, name := range []string{"cache", "no-cache"} {
+			if cmd.Flags().Changed(name) {
+				os.Stderr.WriteString("Error: --" + name + " does not apply to image\n")
+				os.Exit(1)
+			}
+		}
+		opts := image.Options{Options: walkOptions(), Password: imagePassword}
+
+		var results []*image.Result
+		var diags []diag.Diagnostic
+		ok := eachArg(args, func(path string) error {
+			pathResults, err := image.ScanContext(cmd.Context(), path, opts)
+			if err != nil {
+				return err
+			}
+			for _, r := range pathResults {
+				diags = append(diags, r.Diagnostics...)
+			}
+			results = append(results, pathResults...)
+			return nil
+		})
+
+		if len(results) > 0 {
+			utils.PrintImageResults(results, utils.OutputFormat(format))
+			utils.PrintDiagnostics(diags, utils.OutputFormat(format), showErrors)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	imageCmd.Flags().StringVarP(&imagePassword, "password", "p", "", "Password for encrypted private keys")
+	rootCmd.AddCommand(imageCmd)
+}
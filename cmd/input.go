@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// stdinName is the file name reported for the data read from "-".
+const stdinName = "(stdin)"
+
+// readStdin reads standard input once, however many times "-" is given.
+var readStdin = sync.OnceValues(func() ([]byte, error) {
+	return io.ReadAll(os.Stdin)
+})
+
+// expandArgs expands the glob patterns among args, for shells that leave
+// them alone. A pattern that matches nothing is kept, so that opening it
+// reports the missing file.
+func expandArgs(args []string) []string {
+	var paths []string
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if arg == "-" || err != nil || len(matches) == 0 {
+			paths = append(paths, arg)
+			continue
+		}
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// severalInputs reports whether args name more than one input or use a
+// glob. The JSON output of such commands is then always an array, however
+// many files matched.
+func severalInputs(args []string) bool {
+	if len(args) > 1 {
+		return true
+	}
+	for _, arg := range args {
+		if arg != "-" && strings.ContainsAny(arg, "*?[") {
+			return true
+		}
+	}
+	return false
+}
+
+// usesStdin reports whether args read standard input, which then cannot be
+// used to prompt for a password.
+func usesStdin(args []string) bool {
+	for _, arg := range args {
+		if arg == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// eachInput calls parse with the name and contents of every file args name,
+// "-" being standard input. An input that cannot be read or parsed is
+// reported on stderr and skipped; eachInput reports whether all of them
+// succeeded.
+func eachInput(args []string, parse func(name string, data []byte) error) bool {
+	ok := true
+	for _, arg := range expandArgs(args) {
+		name := arg
+		var data []byte
+		var err error
+		if arg == "-" {
+			name = stdinName
+			data, err = readStdin()
+		} else {
+			data, err = os.ReadFile(arg)
+		}
+		if err == nil {
+			if err = parse(name, data); err != nil && !strings.Contains(err.Error(), name) {
+				err = fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			ok = false
+		}
+	}
+	return ok
+}
+
+// eachArg calls run for every argument after glob expansion, reporting the
+// errors on stderr like eachInput, for commands that open their arguments
+// themselves.
+func eachArg(args []string, run func(arg string) error) bool {
+	ok := true
+	for _, arg := range expandArgs(args) {
+		if err := run(arg); err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			ok = false
+		}
+	}
+	return ok
+}
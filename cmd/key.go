@@ -15,25 +15,33 @@ import (
 var keyPassword string
 
 var keyCmd = &cobra.Command{
-	Use:   "key [file]",
+	Use:   "key [file...]",
 	Short: "Show private key information",
-	Long:  "Show information about a private key file (RSA, EC, Ed25519, PQC)",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Show information about one or more private key files (RSA, EC, Ed25519, PQC; - reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		password := keyPassword
 
-		key, err := privatekey.ParsePrivateKey(args[0], password)
-		if err != nil {
-			if err == privatekey.ErrEncryptedKey && password == "" {
+		var keys []*privatekey.KeyInfo
+		ok := eachInput(args, func(name string, data []byte) error {
+			key, err := privatekey.ParsePrivateKeyFromBytes(data, name, password)
+			if err == privatekey.ErrEncryptedKey && password == "" && !usesStdin(args) {
 				password = promptPassword("Enter password for encrypted key: ")
-				key, err = privatekey.ParsePrivateKey(args[0], password)
+				key, err = privatekey.ParsePrivateKeyFromBytes(data, name, password)
 			}
 			if err != nil {
-				os.Stderr.WriteString("Error: " + err.Error() + "\n")
-				os.Exit(1)
+				return err
 			}
+			keys = append(keys, key)
+			return nil
+		})
+
+		if len(keys) > 0 {
+			utils.PrintKeyInfos(keys, utils.OutputFormat(format))
+		}
+		if !ok {
+			os.Exit(1)
 		}
-		utils.PrintKeyInfo(key, utils.OutputFormat(format))
 	},
 }
 
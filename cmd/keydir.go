@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/marco-introini/certinfo/pkg/diag"
 	"github.com/marco-introini/certinfo/pkg/privatekey"
 	"github.com/marco-introini/certinfo/pkg/utils"
 
@@ -10,33 +11,42 @@ import (
 )
 
 var keydirPassword string
+var keydirMinStrength int
 
 var keydirCmd = &cobra.Command{
-	Use:   "keydir [directory]",
+	Use:   "keydir [directory...]",
 	Short: "Summarize private keys in a directory",
-	Long:  "Summarize all private keys in a directory (type and bits)",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Summarize all private keys in one or more directories or files (type and bits)",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var summaries []privatekey.KeySummary
-		var err error
-
-		if recursive {
-			summaries, err = privatekey.SummarizeDirectoryRecursive(args[0], keydirPassword)
-		} else {
-			summaries, err = privatekey.SummarizeDirectory(args[0], keydirPassword)
+		var diags []diag.Diagnostic
+
+		opts := walkOptions()
+		opts.Cache = openCache()
+		opts.FullPaths = len(expandArgs(args)) > 1
+		ok := eachArg(args, func(root string) error {
+			s, d, err := privatekey.SummarizeDirectoryContext(cmd.Context(), root, opts, keydirPassword)
+			summaries, diags = append(summaries, s...), append(diags, d...)
+			return err
+		})
+
+		if keydirMinStrength > 0 {
+			summaries = privatekey.WeakerThan(summaries, keydirMinStrength)
 		}
 
-		if err != nil {
-			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		utils.PrintKeySummaries(summaries, utils.OutputFormat(format))
+		utils.PrintDiagnostics(diags, utils.OutputFormat(format), showErrors)
+		saveCache(opts)
+		if !ok {
 			os.Exit(1)
 		}
-
-		utils.PrintKeySummaries(summaries, utils.OutputFormat(format))
 	},
 }
 
 func init() {
 	keydirCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Search recursively")
 	keydirCmd.Flags().StringVarP(&keydirPassword, "password", "p", "", "Password for encrypted private keys")
+	keydirCmd.Flags().IntVar(&keydirMinStrength, "min-strength", 0, "Only list keys weaker than this many bits of security")
 	rootCmd.AddCommand(keydirCmd)
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/match"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var matchPassword string
+
+var matchCmd = &cobra.Command{
+	Use:   "match [certificate] [private key] | match [directory]",
+	Short: "Check that a private key belongs to a certificate",
+	Long:  "Compare the public key of a certificate with the public key derived from a private key, or pair every certificate and private key in a directory tree",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			runDirectoryMatch(args[0])
+			return
+		}
+
+		certs, err := certificate.ParseCertificates(args[0])
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		password := matchPassword
+		key, err := privatekey.ParsePrivateKey(args[1], password)
+		if err != nil {
+			if err == privatekey.ErrEncryptedKey && password == "" {
+				password = promptPassword("Enter password for encrypted key: ")
+				key, err = privatekey.ParsePrivateKey(args[1], password)
+			}
+			if err != nil {
+				os.Stderr.WriteString("Error: " + err.Error() + "\n")
+				os.Exit(1)
+			}
+		}
+
+		result, err := match.Check(certs, key)
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		utils.PrintMatchResult(result, utils.OutputFormat(format))
+		if !result.Match {
+			os.Exit(1)
+		}
+	},
+}
+
+func runDirectoryMatch(dirPath string) {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+	if !info.IsDir() {
+		os.Stderr.WriteString("Error: " + dirPath + " is not a directory; pass a certificate and a private key to compare them\n")
+		os.Exit(1)
+	}
+
+	result, err := match.Directory(dirPath, matchPassword)
+	if err != nil {
+		os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	utils.PrintDirectoryMatch(result, utils.OutputFormat(format))
+	utils.PrintDiagnostics(result.Diagnostics, utils.OutputFormat(format), showErrors)
+	if result.Mismatches() > 0 || len(result.OrphanKeys) > 0 {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	matchCmd.Flags().StringVarP(&matchPassword, "password", "p", "", "Password for encrypted private keys")
+	rootCmd.AddCommand(matchCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/ocsp"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var ocspIssuer string
+var ocspURL string
+
+var ocspCmd = &cobra.Command{
+	Use:   "ocsp [certificate | response...]",
+	Short: "Check a certificate against its OCSP responder",
+	Long:  "Send an OCSP request for each certificate and decode the response, or decode saved or stapled OCSP response files (.ocsp, .der)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var resps []*ocsp.ResponseInfo
+		ok := eachArg(args, func(path string) error {
+			var resp *ocsp.ResponseInfo
+			var err error
+			if _, certErr := certificate.LoadX509Certificates(path); certErr == nil {
+				resp, err = ocsp.Query(path, ocsp.Options{IssuerPath: ocspIssuer, URL: ocspURL})
+			} else {
+				resp, err = ocsp.ParseResponseFile(path, ocspIssuer)
+			}
+			if err != nil {
+				return err
+			}
+			resps = append(resps, resp)
+			return nil
+		})
+
+		if len(resps) > 0 {
+			utils.PrintOCSPResponses(resps, utils.OutputFormat(format))
+		}
+		for _, resp := range resps {
+			if resp.Status == certificate.RevocationRevoked || (resp.SignatureChecked && !resp.SignatureValid) {
+				ok = false
+			}
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ocspCmd.Flags().StringVar(&ocspIssuer, "issuer", "", "Issuer certificate, used to build the request and verify the response signature")
+	ocspCmd.Flags().StringVar(&ocspURL, "url", "", "OCSP responder URL (default: the certificate's AIA OCSP URL)")
+	rootCmd.AddCommand(ocspCmd)
+}
@@ -11,17 +11,27 @@ import (
 var password string
 
 var p12Cmd = &cobra.Command{
-	Use:   "p12 [file]",
+	Use:   "p12 [file...]",
 	Short: "Show detailed information about a PKCS#12 file",
-	Long:  "Show detailed information about a single PKCS#12 file containing certificates and private keys",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Show detailed information about one or more PKCS#12 files containing certificates and private keys (- reads standard input)",
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		p12, err := pkcs12.ParseP12(args[0], password)
-		if err != nil {
-			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+		var p12s []*pkcs12.P12Info
+		ok := eachInput(args, func(name string, data []byte) error {
+			p12, err := pkcs12.ParseP12FromBytes(data, name, password)
+			if err != nil {
+				return err
+			}
+			p12s = append(p12s, p12)
+			return nil
+		})
+
+		if len(p12s) > 0 {
+			utils.PrintP12Infos(p12s, utils.OutputFormat(format))
+		}
+		if !ok {
 			os.Exit(1)
 		}
-		utils.PrintP12Info(p12, utils.OutputFormat(format))
 	},
 }
 
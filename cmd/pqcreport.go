@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/report"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var pqcReportPassword string
+var pqcReportTop int
+
+var pqcReportCmd = &cobra.Command{
+	Use:   "pqc-report [directory...]",
+	Short: "Report post-quantum migration readiness of a directory",
+	Long:  "Walk the certificates, private keys and PKCS#12 files of a directory tree, classify them as classical, hybrid or PQC, estimate their harvest-now-decrypt-later exposure, map them to the NIST IR 8547 and CNSA 2.0 transition dates and list what to migrate first",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dirPaths := expandArgs(args)
+		for _, dirPath := range dirPaths {
+			if _, err := os.Stat(dirPath); err != nil {
+				os.Stderr.WriteString("Error: " + err.Error() + "\n")
+				os.Exit(1)
+			}
+		}
+
+		opts := walkOptions()
+		opts.Cache = openCache()
+		r, err := report.GenerateContext(cmd.Context(), dirPaths, pqcReportPassword, opts)
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		if pqcReportTop > 0 && len(r.MigrateFirst) > pqcReportTop {
+			r.MigrateFirst = r.MigrateFirst[:pqcReportTop]
+		}
+
+		utils.PrintPQCReport(r, utils.OutputFormat(format))
+		utils.PrintDiagnostics(r.Diagnostics, utils.OutputFormat(format), showErrors)
+		saveCache(opts)
+	},
+}
+
+func init() {
+	pqcReportCmd.Flags().StringVarP(&pqcReportPassword, "password", "p", "", "Password for encrypted private keys and PKCS#12 files")
+	pqcReportCmd.Flags().IntVar(&pqcReportTop, "top", 10, "Number of items in the migrate-first list (0 for all)")
+	rootCmd.AddCommand(pqcReportCmd)
+}
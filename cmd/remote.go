@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/remote"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var remoteSNI string
+var remoteALPN []string
+var remoteRoots string
+var remoteTimeout time.Duration
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote [host:port...]",
+	Short: "Inspect the certificates served by a TLS endpoint",
+	Long:  "Run a TLS handshake with one or more servers and show the negotiated TLS version, cipher suite, ALPN, key exchange group, SNI behavior, stapled OCSP response and served chain",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		inspectAll(args, remote.Options{
+			ServerName: remoteSNI,
+			ALPN:       remoteALPN,
+			RootsPath:  remoteRoots,
+			Timeout:    remoteTimeout,
+		})
+	},
+}
+
+// inspectAll inspects every address and prints the results together.
+func inspectAll(addresses []string, opts remote.Options) {
+	var results []*remote.Result
+	ok := true
+	for _, address := range addresses {
+		result, err := remote.Inspect(address, opts)
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			ok = false
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if len(results) > 0 {
+		utils.PrintRemoteResults(results, utils.OutputFormat(format))
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	remoteCmd.Flags().StringVar(&remoteSNI, "sni", "", "Server name to send (default: the host name)")
+	remoteCmd.Flags().StringSliceVar(&remoteALPN, "alpn", []string{"h2", "http/1.1"}, "ALPN protocols to offer")
+	remoteCmd.Flags().StringVar(&remoteRoots, "roots", "", "Trusted root certificates used to verify the chain (default: system roots)")
+	remoteCmd.Flags().DurationVar(&remoteTimeout, "timeout", 10*time.Second, "Connection timeout")
+	rootCmd.AddCommand(remoteCmd)
+}
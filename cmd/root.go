@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 
+	"github.com/marco-introini/certinfo/pkg/archive"
+	"github.com/marco-introini/certinfo/pkg/cache"
 	"github.com/marco-introini/certinfo/pkg/utils"
+	"github.com/marco-introini/certinfo/pkg/walk"
 	"github.com/spf13/cobra"
 )
 
@@ -17,9 +24,23 @@ var rootCmd = &cobra.Command{
 var format string
 var recursive bool
 var noColor bool
+var showErrors bool
+var jobs int
+var cacheFile string
+var noCache bool
+var maxDepth int
+var includes []string
+var excludes []string
+var maxFileSize string
+var followSymlinks bool
+var archives bool
+var archiveDepth int
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -30,9 +51,95 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&format, "format", "f", "table", "Output format (table, json)")
 	rootCmd.PersistentFlags().BoolVarP(&recursive, "recursive", "r", false, "Search recursively")
 	rootCmd.PersistentFlags().BoolVarP(&noColor, "no-color", "c", false, "Disable color output")
+	rootCmd.PersistentFlags().BoolVar(&showErrors, "show-errors", false, "List every file a directory command skipped, and why")
+	rootCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "Number of directory levels to walk, implies --recursive (0 for no limit)")
+	rootCmd.PersistentFlags().StringArrayVar(&includes, "include", nil, "Only walk files matching this glob (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&excludes, "exclude", nil, "Skip files and directories matching this glob (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&maxFileSize, "max-file-size", "", "Skip files larger than this size, such as 512K or 10M")
+	rootCmd.PersistentFlags().BoolVar(&followSymlinks, "follow-symlinks", false, "Walk into symbolic links to directories, skipping loops")
+	rootCmd.PersistentFlags().BoolVar(&archives, "archives", false, "Scan the entries of zip, jar, tar and tar.gz files")
+	rootCmd.PersistentFlags().IntVar(&archiveDepth, "archive-depth", archive.DefaultMaxDepth, "Number of nested archive levels opened by --archives")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 0, "Number of files parsed in parallel by directory commands (0 for one per CPU)")
+	rootCmd.PersistentFlags().StringVar(&cacheFile, "cache", os.Getenv("CERTINFO_CACHE"), "Cache file that keeps parsed certificates and keys between directory scans; files whose size and modification time are unchanged are not read again (default $CERTINFO_CACHE)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Parse every file, ignoring --cache and $CERTINFO_CACHE")
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
 		if noColor {
 			utils.DisableColors()
 		}
+		if severalInputs(args) {
+			utils.ForceJSONArrays()
+		}
+	}
+}
+
+// walkOptions returns the directory walk options set by the global flags.
+func walkOptions() walk.Options {
+	maxSize, err := parseSize(maxFileSize)
+	if err != nil {
+		os.Stderr.WriteString("Error: --max-file-size: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	return walk.Options{
+		Recursive:      recursive,
+		MaxDepth:       maxDepth,
+		Include:        includes,
+		Exclude:        excludes,
+		MaxFileSize:    maxSize,
+		FollowSymlinks: followSymlinks,
+		Archives:       archives,
+		ArchiveDepth:   archiveDepth,
+		Jobs:           jobs,
+	}
+}
+
+// parseSize parses a size in bytes with an optional K, M or G suffix.
+func parseSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		multiplier = 1 << 10
+	case "M":
+		multiplier = 1 << 20
+	case "G":
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// openCache opens the scan cache selected by --cache, or returns nil when
+// there is none or --no-cache is set.
+func openCache() *cache.Cache {
+	if cacheFile == "" || noCache {
+		return nil
+	}
+
+	c, err := cache.Open(cacheFile)
+	if err != nil {
+		os.Stderr.WriteString("Warning: not using the cache: " + err.Error() + "\n")
+		return nil
+	}
+	return c
+}
+
+// saveCache writes the scan cache back and reports how many files it saved
+// from parsing.
+func saveCache(opts walk.Options) {
+	if opts.Cache == nil {
+		return
+	}
+	if err := opts.Cache.Save(); err != nil {
+		os.Stderr.WriteString("Warning: could not save the cache: " + err.Error() + "\n")
 	}
+	utils.PrintCacheStats(opts.Cache.Stats())
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/marco-introini/certinfo/pkg/scan"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var scanPassword string
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [path...]",
+	Short: "Classify every crypto object in a file or directory",
+	Long:  "Detect the type of every file in one or more files or directories (certificate, bundle, private key, public key, CSR, CRL, PKCS#7, PKCS#12, JKS, SSH key or unknown) and list the objects found in one inventory",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		paths := expandArgs(args)
+		opts := scan.Options{Options: walkOptions(), Password: scanPassword}
+		opts.FullPaths = len(paths) > 1
+		opts.Cache = openCache()
+
+		inv := &scan.Inventory{Path: strings.Join(paths, ", ")}
+		ok := eachArg(args, func(path string) error {
+			pathInv, err := scan.ScanContext(cmd.Context(), path, opts)
+			if err != nil {
+				return err
+			}
+			inv.Files = append(inv.Files, pathInv.Files...)
+			inv.Diagnostics = append(inv.Diagnostics, pathInv.Diagnostics...)
+			return nil
+		})
+
+		utils.PrintInventory(inv, utils.OutputFormat(format))
+		utils.PrintDiagnostics(inv.Diagnostics, utils.OutputFormat(format), showErrors)
+		saveCache(opts.Options)
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	scanCmd.Flags().StringVarP(&scanPassword, "password", "p", "", "Password for encrypted private keys and PKCS#12 files")
+	rootCmd.AddCommand(scanCmd)
+}
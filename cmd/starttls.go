@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var starttlsProtocol string
+var starttlsSNI string
+var starttlsRoots string
+var starttlsTimeout time.Duration
+
+var starttlsCmd = &cobra.Command{
+	Use:   "starttls [host:port...]",
+	Short: "Inspect the certificates of a server that upgrades to TLS with STARTTLS",
+	Long:  "Speak the plaintext upgrade dialog of a protocol (" + strings.Join(remote.Protocols(), ", ") + "), run the TLS handshake and show the negotiated parameters and the served chain",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		inspectAll(args, remote.Options{
+			Protocol:   starttlsProtocol,
+			ServerName: starttlsSNI,
+			RootsPath:  starttlsRoots,
+			Timeout:    starttlsTimeout,
+		})
+	},
+}
+
+func init() {
+	starttlsCmd.Flags().StringVar(&starttlsProtocol, "protocol", "", "Upgrade protocol: "+strings.Join(remote.Protocols(), "|"))
+	starttlsCmd.Flags().StringVar(&starttlsSNI, "sni", "", "Server name to send (default: the host name)")
+	starttlsCmd.Flags().StringVar(&starttlsRoots, "roots", "", "Trusted root certificates used to verify the chain (default: system roots)")
+	starttlsCmd.Flags().DurationVar(&starttlsTimeout, "timeout", 10*time.Second, "Connection timeout")
+	starttlsCmd.MarkFlagRequired("protocol")
+	rootCmd.AddCommand(starttlsCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/chain"
+	"github.com/marco-introini/certinfo/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyIntermediates []string
+var verifyRoots []string
+var verifySystemRoots bool
+var verifyHost string
+var verifyEKU []string
+var verifyAt string
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [file...]",
+	Short: "Build and validate certificate chains",
+	Long:  "Build every candidate chain from each leaf certificate to a trusted root and explain why validation fails",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		at, err := parseTime(verifyAt)
+		if err != nil {
+			os.Stderr.WriteString("Error: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		var results []*chain.VerifyResult
+		ok := eachArg(args, func(leafPath string) error {
+			result, err := chain.Verify(leafPath, chain.Options{
+				Intermediates: verifyIntermediates,
+				Roots:         verifyRoots,
+				SystemRoots:   verifySystemRoots,
+				DNSName:       verifyHost,
+				KeyUsages:     verifyEKU,
+				At:            at,
+			})
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+			return nil
+		})
+
+		if len(results) > 0 {
+			utils.PrintVerifyResults(results, utils.OutputFormat(format))
+		}
+		for _, result := range results {
+			if !result.Valid {
+				ok = false
+			}
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// parseTime accepts an absolute time (RFC 3339, "2006-01-02 15:04:05" or
+// "2006-01-02") or an offset from now such as "+30d" or "+720h".
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		if days, ok := strings.CutSuffix(value, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid time offset: %s", value)
+			}
+			return time.Now().AddDate(0, 0, n), nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time offset: %s", value)
+		}
+		return time.Now().Add(d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", value)
+}
+
+func init() {
+	verifyCmd.Flags().StringSliceVar(&verifyIntermediates, "intermediates", nil, "Intermediate certificate files")
+	verifyCmd.Flags().StringSliceVar(&verifyRoots, "roots", nil, "Trusted root certificate files")
+	verifyCmd.Flags().BoolVar(&verifySystemRoots, "system-roots", false, "Trust the system root store")
+	verifyCmd.Flags().StringVar(&verifyHost, "host", "", "Host name the leaf certificate must be valid for")
+	verifyCmd.Flags().StringSliceVar(&verifyEKU, "eku", nil, "Required extended key usages (serverAuth, clientAuth, codeSigning, emailProtection, timeStamping, ocspSigning, any)")
+	verifyCmd.Flags().StringVar(&verifyAt, "at", "", "Validate at this time (RFC 3339, YYYY-MM-DD, or offset like +30d)")
+	rootCmd.AddCommand(verifyCmd)
+}
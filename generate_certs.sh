@@ -133,16 +133,34 @@ openssl req -new -x509 -days 730 -key root-ca.key -out root-ca.crt \
 openssl genrsa -out intermediate-ca.key 4096
 openssl req -new -key intermediate-ca.key -out intermediate-ca.csr \
     -subj "/CN=Test Intermediate CA/O=TestChain/C=IT"
+cat > chain.cnf << 'CHAINEOF'
+[ca_ext]
+basicConstraints = critical, CA:TRUE
+keyUsage = critical, keyCertSign, cRLSign
+subjectKeyIdentifier = hash
+authorityKeyIdentifier = keyid
+
+[server_ext]
+basicConstraints = CA:FALSE
+keyUsage = digitalSignature, keyEncipherment
+extendedKeyUsage = serverAuth
+subjectAltName = DNS:localhost
+CHAINEOF
+
 openssl x509 -req -days 365 -in intermediate-ca.csr \
-    -CA root-ca.crt -CAkey root-ca.key -CAcreateserial -out intermediate-ca.crt
+    -CA root-ca.crt -CAkey root-ca.key -CAcreateserial -out intermediate-ca.crt \
+    -extfile chain.cnf -extensions ca_ext
 rm -f *.csr *.srl
 
 openssl genrsa -out server.key 2048
 openssl req -new -key server.key -out server.csr \
     -subj "/CN=localhost/O=TestServer/C=IT"
 openssl x509 -req -days 365 -in server.csr \
-    -CA intermediate-ca.crt -CAkey intermediate-ca.key -CAcreateserial -out server.crt
-rm -f *.csr *.srl
+    -CA intermediate-ca.crt -CAkey intermediate-ca.key -CAcreateserial -out server.crt \
+    -extfile chain.cnf -extensions server_ext
+rm -f *.csr *.srl chain.cnf
+
+cat server.crt intermediate-ca.crt > fullchain.crt
 
 echo "[4/6] Generating self-signed and expired certificates..."
 cd "${CERT_DIR}/selfsigned"
@@ -208,7 +226,40 @@ openssl x509 -req -days 365 -in san-ecdsa.csr \
     -CA "${CERT_DIR}/traditional/ecdsa/ca-ecdsa-p256.crt" \
     -CAkey "${CERT_DIR}/traditional/ecdsa/ca-ecdsa-p256.key" \
     -CAcreateserial -out san-ecdsa.crt -extfile san.cnf -extensions ext
-rm -f *.csr *.srl san.cnf
+
+cat > san-mixed.cnf << 'SANMIXEDEOF'
+[req]
+default_bits = 2048
+prompt = no
+default_md = sha256
+distinguished_name = dn
+
+[dn]
+C = IT
+O = Test
+CN = web.test.local
+
+[ext]
+basicConstraints=CA:FALSE
+keyUsage = digitalSignature, keyEncipherment
+subjectAltName = @alt_names
+
+[alt_names]
+DNS.1 = web.test.local
+DNS.2 = *.apps.test.local
+IP.1 = 10.1.2.3
+email.1 = admin@test.local
+URI.1 = spiffe://test.local/ns/default/sa/web
+otherName.1 = 1.3.6.1.4.1.311.20.2.3;UTF8:user@test.local
+SANMIXEDEOF
+
+openssl req -new -newkey rsa:2048 -nodes -keyout san-mixed.key -out san-mixed.csr \
+    -config san-mixed.cnf
+openssl x509 -req -days 365 -in san-mixed.csr \
+    -CA "${CERT_DIR}/traditional/rsa/ca-rsa2048.crt" \
+    -CAkey "${CERT_DIR}/traditional/rsa/ca-rsa2048.key" \
+    -CAcreateserial -out san-mixed.crt -extfile san-mixed.cnf -extensions ext
+rm -f *.csr *.srl san.cnf san-mixed.cnf
 
 cd "${CERT_DIR}/client"
 
@@ -271,6 +322,124 @@ openssl x509 -req -days 365 -in wildcard.csr \
     -CAcreateserial -out wildcard.crt -extfile wildcard.cnf -extensions ext
 rm -f *.csr *.srl wildcard.cnf
 
+mkdir -p "${CERT_DIR}/extensions"
+cd "${CERT_DIR}/extensions"
+
+cat > extensions.cnf << 'EXTEOF'
+[req]
+default_bits = 2048
+prompt = no
+default_md = sha256
+distinguished_name = dn
+
+[dn]
+C = IT
+O = Test
+CN = Test Extensions CA
+
+[ext]
+basicConstraints = critical, CA:TRUE, pathlen:1
+keyUsage = critical, digitalSignature, keyCertSign, cRLSign
+subjectKeyIdentifier = hash
+authorityKeyIdentifier = keyid
+crlDistributionPoints = URI:http://crl.test.local/ca.crl
+authorityInfoAccess = OCSP;URI:http://ocsp.test.local, caIssuers;URI:http://ca.test.local/ca.crt
+certificatePolicies = 2.23.140.1.2.1, 1.3.6.1.4.1.99999.1
+nameConstraints = critical, permitted;DNS:.test.local, permitted;IP:10.0.0.0/255.0.0.0, excluded;email:test.local
+tlsfeature = status_request
+1.3.6.1.4.1.99999.2 = ASN1:UTF8String:certinfo test extension
+EXTEOF
+
+openssl req -new -x509 -days 365 -newkey rsa:2048 -nodes -keyout extensions.key \
+    -out extensions.crt -config extensions.cnf -extensions ext
+rm -f extensions.cnf
+
+mkdir -p "${CERT_DIR}/csr"
+cd "${CERT_DIR}/csr"
+
+cat > csr.cnf << 'CSREOF'
+[req]
+default_bits = 2048
+prompt = no
+default_md = sha256
+distinguished_name = dn
+req_extensions = ext
+
+[dn]
+C = IT
+O = TestServer
+CN = csr.test.local
+
+[ext]
+basicConstraints = critical, CA:FALSE
+keyUsage = critical, digitalSignature, keyEncipherment
+extendedKeyUsage = serverAuth, clientAuth
+subjectAltName = DNS:csr.test.local, DNS:www.csr.test.local, IP:10.1.2.3, email:admin@csr.test.local
+1.3.6.1.4.1.99999.3 = ASN1:UTF8String:certinfo test request extension
+CSREOF
+
+openssl req -new -newkey rsa:2048 -nodes -keyout server-rsa.key -out server-rsa.csr -config csr.cnf
+openssl ecparam -name prime256v1 -genkey -noout -out server-ecdsa.key
+openssl req -new -key server-ecdsa.key -out server-ecdsa.der -outform DER \
+    -subj "/CN=ecdsa.csr.test.local/O=TestServer/C=IT"
+rm -f csr.cnf
+
+mkdir -p "${CERT_DIR}/crl"
+cd "${CERT_DIR}/crl"
+
+cat > ca.cnf << 'CRLEOF'
+[ca]
+default_ca = CA_default
+
+[CA_default]
+dir = .
+database = index.txt
+new_certs_dir = .
+serial = serial
+crlnumber = crlnumber
+default_md = sha256
+default_days = 365
+default_crl_days = 30
+policy = policy_any
+copy_extensions = none
+crl_extensions = crl_ext
+
+[policy_any]
+commonName = supplied
+
+[crl_ext]
+authorityKeyIdentifier = keyid
+
+[delta_ext]
+authorityKeyIdentifier = keyid
+2.5.29.27 = critical, ASN1:INTEGER:1
+CRLEOF
+
+touch index.txt
+echo 1000 > serial
+echo 01 > crlnumber
+
+openssl req -new -x509 -days 365 -newkey rsa:2048 -nodes -keyout ca.key -out ca.crt \
+    -subj "/CN=Test CRL CA/O=TestCRL/C=IT"
+for name in good revoked; do
+    openssl req -new -newkey rsa:2048 -nodes -keyout ${name}.key -out ${name}.csr \
+        -subj "/CN=${name}.test.local/O=TestCRL/C=IT"
+    openssl ca -batch -config ca.cnf -cert ca.crt -keyfile ca.key -notext \
+        -in ${name}.csr -out ${name}.crt
+done
+openssl ca -config ca.cnf -cert ca.crt -keyfile ca.key -revoke revoked.crt -crl_reason keyCompromise
+openssl ca -config ca.cnf -cert ca.crt -keyfile ca.key -gencrl -out ca.crl
+openssl crl -in ca.crl -outform DER -out ca-der.crl
+openssl ca -config ca.cnf -cert ca.crt -keyfile ca.key -gencrl -out stale.crl \
+    -crl_lastupdate 20200101000000Z -crl_nextupdate 20200201000000Z
+openssl ca -config ca.cnf -cert ca.crt -keyfile ca.key -gencrl -crlexts delta_ext -out delta.crl
+for name in good revoked; do
+    openssl ocsp -issuer ca.crt -cert ${name}.crt -no_nonce -reqout ${name}.req
+    openssl ocsp -index index.txt -rsigner ca.crt -rkey ca.key -CA ca.crt -ndays 30 \
+        -reqin ${name}.req -respout ${name}.ocsp
+done
+rm -f *.csr *.req *.pem ca.cnf index.txt* serial* crlnumber*
+
 echo "[6/7] Generating PKCS#12 files..."
 cd "${CERT_DIR}/p12-format"
 
@@ -451,7 +620,7 @@ Traditional certificates using RSA and ECDSA algorithms.
 - **ecdsa/**: ECDSA certificates (P-256, P-384, P-521, Ed25519, Ed448)
 
 ### chain/
-Certificate chain (root -> intermediate -> server)
+Certificate chain (root -> intermediate -> server) and a fullchain bundle (server + intermediate)
 
 ### selfsigned/
 Self-signed certificates (no CA)
@@ -474,6 +643,15 @@ PKCS#12 format certificates (password: testpass)
 ### with-text/
 Certificates and keys with descriptive text prefix before PEM blocks (for testing PEM parsing)
 
+### extensions/
+CA certificate carrying key usage, pathLen, key identifiers, CRL/AIA URLs, policies, name constraints, Must-Staple and a private extension
+
+### crl/
+CRL test CA with a good and a revoked (keyCompromise) certificate; current CRL in PEM and DER, a stale CRL, a delta CRL and saved OCSP responses (good.ocsp, revoked.ocsp) signed by the CA
+
+### csr/
+Certificate signing requests: RSA in PEM with requested SANs, key usage, EKU and a private extension; ECDSA P-256 in DER
+
 ## Usage
 
 All keys without password protection can be read directly.
@@ -1,9 +1,11 @@
 module github.com/marco-introini/certinfo
 
-go 1.25
+go 1.25.0
 
 require (
+	github.com/cloudflare/circl v1.6.4
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/crypto v0.48.0
 	golang.org/x/term v0.40.0
 	software.sslmate.com/src/go-pkcs12 v0.7.0
 )
@@ -11,7 +13,6 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/crypto v0.48.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,260 @@
+// Package archive reads the entries of zip, jar, tar and tar.gz files, and of
+// the archives nested in them, within limits that guard against archive
+// bombs.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	DefaultMaxDepth     = 3
+	DefaultMaxEntrySize = 64 << 20
+	DefaultMaxTotalSize = 1 << 30
+	DefaultMaxEntries   = 100000
+)
+
+// Separator separates the path of an archive from the path of an entry in
+// it, as in bundle.tar.gz!/etc/ssl/server.pem.
+const Separator = "!/"
+
+// Options bounds what is read from one archive on disk. Zero fields use the
+// defaults.
+type Options struct {
+	// MaxDepth is the number of archive levels opened, the archive on disk
+	// being level 1.
+	MaxDepth int
+	// MaxEntrySize is the size of the largest entry read, uncompressed.
+	MaxEntrySize int64
+	// MaxTotalSize and MaxEntries bound the bytes uncompressed and the
+	// entries read from the archive, the nested ones included.
+	MaxTotalSize int64
+	MaxEntries   int
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultMaxDepth
+	}
+	if o.MaxEntrySize <= 0 {
+		o.MaxEntrySize = DefaultMaxEntrySize
+	}
+	if o.MaxTotalSize <= 0 {
+		o.MaxTotalSize = DefaultMaxTotalSize
+	}
+	if o.MaxEntries <= 0 {
+		o.MaxEntries = DefaultMaxEntries
+	}
+	return o
+}
+
+var (
+	// ErrEntryTooLarge is the error of entries larger than
+	// Options.MaxEntrySize.
+	ErrEntryTooLarge = errors.New("archive entry exceeds the size limit")
+	// ErrTooDeep is the error of archives nested deeper than
+	// Options.MaxDepth.
+	ErrTooDeep = errors.New("archive nested too deep")
+	// ErrLimit is the error of archives that uncompress to more than
+	// Options.MaxTotalSize or hold more than Options.MaxEntries entries.
+	// The rest of the archive is not read.
+	ErrLimit = errors.New("archive exceeds the extraction limits")
+)
+
+type Entry struct {
+	// Name is the slash-separated path of the entry, with Separator after
+	// each nested archive; empty for the archive itself.
+	Name string
+	Data []byte
+	// Err is why the entry, or the archive when Name is empty, could not be
+	// read.
+	Err error
+}
+
+// Format returns the archive format of a file name: "zip" (also for jar, war
+// and ear), "tar" or "tar.gz", or "" for any other file.
+func Format(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	switch path.Ext(lower) {
+	case ".zip", ".jar", ".war", ".ear":
+		return "zip"
+	}
+	return ""
+}
+
+type walker struct {
+	ctx     context.Context
+	opts    Options
+	visit   func(Entry) error
+	entries int
+	total   int64
+	// stopped is the error of visit or ctx that ends the walk, as opposed
+	// to the errors of unreadable archives.
+	stopped error
+}
+
+// Walk calls visit for every file in the archive at filePath, in archive
+// order, and for every entry or nested archive that could not be read. It
+// returns only the errors of visit and ctx.
+func Walk(ctx context.Context, filePath string, opts Options, visit func(Entry) error) error {
+	w := &walker{ctx: ctx, opts: opts.withDefaults(), visit: visit}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return w.emit(Entry{Err: err})
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return w.emit(Entry{Err: err})
+	}
+
+	if err := w.walk(Format(filePath), f, info.Size(), "", 1); err != nil {
+		if w.stopped != nil {
+			return w.stopped
+		}
+		return w.emit(Entry{Err: err})
+	}
+	return nil
+}
+
+func (w *walker) emit(e Entry) error {
+	if err := w.visit(e); err != nil {
+		w.stopped = err
+	}
+	return w.stopped
+}
+
+func (w *walker) walk(format string, r io.ReaderAt, size int64, prefix string, depth int) error {
+	switch format {
+	case "zip":
+		return w.walkZip(r, size, prefix, depth)
+	case "tar":
+		return w.walkTar(io.NewSectionReader(r, 0, size), prefix, depth)
+	case "tar.gz":
+		gz, err := gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return w.walkTar(gz, prefix, depth)
+	}
+	return fmt.Errorf("unknown archive format %q", format)
+}
+
+func (w *walker) walkZip(r io.ReaderAt, size int64, prefix string, depth int) error {
+	zr, err := zip.NewReader(r, size)
+	// Entry names are never used as paths on disk, so unsafe ones are
+	// read like the others.
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := w.entry(prefix, f.Name, int64(f.UncompressedSize64), f.Open, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkTar(r io.Reader, prefix string, depth int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		if err := w.entry(prefix, hdr.Name, hdr.Size, open, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// entry reads one file of an archive, and walks it when it is an archive
+// itself. size is the size the archive declares for it.
+func (w *walker) entry(prefix, name string, size int64, open func() (io.ReadCloser, error), depth int) error {
+	if err := w.ctx.Err(); err != nil {
+		w.stopped = err
+		return err
+	}
+	w.entries++
+	if w.entries > w.opts.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrLimit, w.opts.MaxEntries)
+	}
+
+	name = prefix + strings.TrimPrefix(path.Clean("/"+name), "/")
+	if size > w.opts.MaxEntrySize {
+		return w.emit(Entry{Name: name, Err: fmt.Errorf("%w: %d bytes", ErrEntryTooLarge, size)})
+	}
+	data, err := w.read(open)
+	if errors.Is(err, ErrLimit) {
+		return err
+	}
+	if err != nil {
+		return w.emit(Entry{Name: name, Err: err})
+	}
+
+	format := Format(name)
+	if format == "" {
+		return w.emit(Entry{Name: name, Data: data})
+	}
+	if depth >= w.opts.MaxDepth {
+		return w.emit(Entry{Name: name, Err: ErrTooDeep})
+	}
+	if err := w.walk(format, bytes.NewReader(data), int64(len(data)), name+Separator, depth+1); err != nil {
+		if w.stopped != nil || errors.Is(err, ErrLimit) {
+			return err
+		}
+		return w.emit(Entry{Name: name, Err: err})
+	}
+	return nil
+}
+
+// read reads an entry, counting the bytes it really uncompresses to rather
+// than trusting the size the archive declares.
+func (w *walker) read(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, w.opts.MaxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > w.opts.MaxEntrySize {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrEntryTooLarge, w.opts.MaxEntrySize)
+	}
+	w.total += int64(len(data))
+	if w.total > w.opts.MaxTotalSize {
+		return nil, fmt.Errorf("%w: more than %d bytes uncompressed", ErrLimit, w.opts.MaxTotalSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,178 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// file is an archive entry to build.
+type file struct {
+	name string
+	data []byte
+}
+
+func zipData(t testing.TB, files ...file) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		require.NoError(t, err)
+		_, err = w.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func tarData(t testing.TB, files ...file) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, f := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.data))}))
+		_, err := tw.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipData(t testing.TB, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func writeFile(t testing.TB, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func walkAll(t *testing.T, path string, opts Options) []Entry {
+	var entries []Entry
+	err := Walk(context.Background(), path, opts, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	require.NoError(t, err)
+	return entries
+}
+
+func TestFormat(t *testing.T) {
+	for name, want := range map[string]string{
+		"bundle.zip":     "zip",
+		"app.JAR":        "zip",
+		"app.war":        "zip",
+		"image.tar":      "tar",
+		"bundle.tar.gz":  "tar.gz",
+		"bundle.tgz":     "tar.gz",
+		"server.pem":     "",
+		"keystore.p12":   "",
+		"notes.gz":       "",
+		"archive.tar.xz": "",
+	} {
+		assert.Equal(t, want, Format(name), name)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	inner := zipData(t, file{"certs/ca.pem", []byte("ca")})
+	tgz := gzipData(t, tarData(t,
+		file{"./etc/ssl/server.pem", []byte("server")},
+		file{"lib/app.jar", inner},
+		file{"/etc/ssl/server.key", []byte("key")},
+	))
+	path := writeFile(t, "bundle.tar.gz", tgz)
+
+	entries := walkAll(t, path, Options{})
+	require.Len(t, entries, 3)
+	assert.Equal(t, Entry{Name: "etc/ssl/server.pem", Data: []byte("server")}, entries[0])
+	assert.Equal(t, Entry{Name: "lib/app.jar!/certs/ca.pem", Data: []byte("ca")}, entries[1])
+	assert.Equal(t, Entry{Name: "etc/ssl/server.key", Data: []byte("key")}, entries[2])
+
+	entries = walkAll(t, writeFile(t, "plain.tar", tarData(t, file{"empty.pem", nil})), Options{})
+	require.Len(t, entries, 1)
+	assert.NotNil(t, entries[0].Data, "an empty entry has empty, not nil, data")
+}
+
+func TestWalkDepth(t *testing.T) {
+	nested := zipData(t, file{"deep.zip", zipData(t, file{"ca.pem", []byte("ca")})})
+	path := writeFile(t, "outer.zip", zipData(t, file{"inner.zip", nested}))
+
+	entries := walkAll(t, path, Options{MaxDepth: 2})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "inner.zip!/deep.zip", entries[0].Name)
+	assert.ErrorIs(t, entries[0].Err, ErrTooDeep)
+
+	entries = walkAll(t, path, Options{MaxDepth: 3})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "inner.zip!/deep.zip!/ca.pem", entries[0].Name)
+}
+
+func TestWalkLimits(t *testing.T) {
+	// Zeros compress well, as in an archive bomb.
+	bomb := make([]byte, 1<<20)
+	path := writeFile(t, "bomb.zip", zipData(t, file{"a.pem", []byte("a")}, file{"bomb.bin", bomb}, file{"b.pem", []byte("b")}))
+
+	entries := walkAll(t, path, Options{MaxEntrySize: 1024})
+	require.Len(t, entries, 3)
+	assert.ErrorIs(t, entries[1].Err, ErrEntryTooLarge)
+	assert.Equal(t, "b.pem", entries[2].Name, "the entries after a large one are read")
+
+	entries = walkAll(t, path, Options{MaxTotalSize: 1024})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a.pem", entries[0].Name)
+	assert.Empty(t, entries[1].Name)
+	assert.ErrorIs(t, entries[1].Err, ErrLimit, "the archive is abandoned")
+
+	entries = walkAll(t, path, Options{MaxEntries: 2})
+	require.Len(t, entries, 3)
+	assert.ErrorIs(t, entries[2].Err, ErrLimit)
+}
+
+func TestWalkErrors(t *testing.T) {
+	corrupt := writeFile(t, "corrupt.zip", []byte("not a zip file"))
+	entries := walkAll(t, corrupt, Options{})
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].Name)
+	assert.Error(t, entries[0].Err)
+
+	path := writeFile(t, "outer.tar", tarData(t, file{"broken.jar", []byte("garbage")}, file{"ok.pem", []byte("ok")}))
+	entries = walkAll(t, path, Options{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "broken.jar", entries[0].Name)
+	assert.Error(t, entries[0].Err)
+	assert.Equal(t, "ok.pem", entries[1].Name, "a corrupt nested archive does not stop the walk")
+
+	entries = walkAll(t, filepath.Join(t.TempDir(), "missing.zip"), Options{})
+	require.Len(t, entries, 1)
+	assert.ErrorIs(t, entries[0].Err, os.ErrNotExist)
+}
+
+func TestWalkCancel(t *testing.T) {
+	path := writeFile(t, "bundle.zip", zipData(t, file{"a.pem", nil}, file{"b.pem", nil}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	visited := 0
+	err := Walk(ctx, path, Options{}, func(Entry) error {
+		visited++
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, visited)
+}
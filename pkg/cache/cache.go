@@ -0,0 +1,275 @@
+// Package cache keeps the parsed contents of files between runs, so that
+// repeated scans of a large tree only parse the files that changed.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+)
+
+// formatVersion is bumped when the layout of the cache file or of the
+// cached values changes.
+const formatVersion = 1
+
+// Version is the certinfo version, set at link time with
+// -ldflags "-X github.com/marco-introini/certinfo/pkg/cache.Version=v1.2.3".
+// Caches written by another version are discarded.
+var Version string
+
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	if version != "" && version != "(devel)" {
+		return version
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision":
+			version += "+" + s.Value
+		case s.Key == "vcs.modified" && s.Value == "true":
+			version += "-dirty"
+		}
+	}
+	return version
+}
+
+// Entry is the cached value of one file, valid while the file keeps its
+// size and modification time or its contents hash the same. A file that
+// keeps its size and modification time is trusted without being read, so
+// a same-size rewrite that also restores the modification time (touch -r,
+// cp -p, archive extraction) is served from the cache.
+type Entry struct {
+	Size    int64
+	ModTime int64
+	SHA256  string
+	Value   json.RawMessage
+}
+
+type Stats struct {
+	Hits   int
+	Misses int
+}
+
+type cacheFile struct {
+	Format  int
+	Version string
+	// Entries holds the entries of each namespace by absolute path.
+	Entries map[string]map[string]Entry
+}
+
+// Cache is an on-disk cache of parsed files. It is safe for concurrent use.
+// A nil *Cache caches nothing.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]map[string]Entry
+	// touched holds the keys looked up in each namespace during this run.
+	touched map[string]map[string]bool
+	dirty   bool
+	stats   Stats
+}
+
+// Open loads the cache file at path. A missing file, or one written by
+// another version of certinfo or that cannot be decoded, gives an empty
+// cache that replaces it on Save.
+func Open(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string]map[string]Entry), touched: make(map[string]map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f cacheFile
+	if json.Unmarshal(data, &f) != nil || f.Format != formatVersion || f.Version != buildVersion() || f.Entries == nil {
+		c.dirty = true
+		return c, nil
+	}
+	c.entries = f.Entries
+	return c, nil
+}
+
+// Save writes the cache back to its file if it changed. The entries of
+// files that no longer exist are dropped first, so the cache of a tree
+// whose files are renamed and deleted does not keep growing.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune()
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Format: formatVersion, Version: buildVersion(), Entries: c.entries})
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file and rename it, so that an interrupted run
+	// never leaves a truncated cache behind.
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// prune drops the entries of the files that no longer exist. The files
+// looked up during this run are known to exist; the others are checked,
+// rather than dropped, because a run may cover only part of the trees the
+// cache holds.
+func (c *Cache) prune() {
+	for namespace, entries := range c.entries {
+		for key := range entries {
+			if c.touched[namespace][key] {
+				continue
+			}
+			if _, err := os.Stat(key); errors.Is(err, fs.ErrNotExist) {
+				delete(entries, key)
+				c.dirty = true
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.entries, namespace)
+		}
+	}
+}
+
+// Stats returns the number of files found in and missing from the cache so
+// far.
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// lookup returns the entry of key, and marks it as used by this run.
+func (c *Cache) lookup(namespace, key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.touched[namespace] == nil {
+		c.touched[namespace] = make(map[string]bool)
+	}
+	c.touched[namespace][key] = true
+	e, ok := c.entries[namespace][key]
+	return e, ok
+}
+
+func (c *Cache) store(namespace, key string, e Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[namespace] == nil {
+		c.entries[namespace] = make(map[string]Entry)
+	}
+	c.entries[namespace][key] = e
+	c.dirty = true
+}
+
+func (c *Cache) count(hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+}
+
+// Load returns the value parse computes from the contents of the file at
+// path. When c holds a value in namespace for the file with the same size
+// and modification time, the file is not read at all, so its contents are
+// not hashed either; when only its contents hash the same, it is read but
+// not parsed. parse reports whether
+// its value may be cached, and the value must survive a JSON round trip.
+func Load[T any](c *Cache, namespace, path string, parse func(data []byte) (T, bool)) (T, error) {
+	var value T
+	if c == nil {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return value, err
+		}
+		value, _ = parse(data)
+		return value, nil
+	}
+
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return value, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return value, err
+	}
+
+	e, cached := c.lookup(namespace, key)
+	if cached && e.Size == info.Size() && e.ModTime == info.ModTime().UnixNano() {
+		if json.Unmarshal(e.Value, &value) == nil {
+			c.count(true)
+			return value, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return value, err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if cached && e.SHA256 == hash {
+		var v T
+		if json.Unmarshal(e.Value, &v) == nil {
+			e.Size, e.ModTime = info.Size(), info.ModTime().UnixNano()
+			c.store(namespace, key, e)
+			c.count(true)
+			return v, nil
+		}
+	}
+
+	c.count(false)
+	value, cacheable := parse(data)
+	if !cacheable {
+		return value, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value, nil
+	}
+	c.store(namespace, key, Entry{Size: int64(len(data)), ModTime: info.ModTime().UnixNano(), SHA256: hash, Value: raw})
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type summary struct {
+	Lines int
+	Text  string
+}
+
+// counter returns a parse function that counts its calls.
+func counter(calls *int, cacheable bool) func([]byte) (summary, bool) {
+	return func(data []byte) (summary, bool) {
+		*calls++
+		return summary{Lines: 1, Text: string(data)}, cacheable
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.crt")
+	require.NoError(t, os.WriteFile(path, []byte("first"), 0644))
+
+	c, err := Open(filepath.Join(dir, "cache.json"))
+	require.NoError(t, err)
+
+	calls := 0
+	v, err := Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, summary{1, "first"}, v)
+	assert.Equal(t, 1, calls)
+
+	v, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, summary{1, "first"}, v)
+	assert.Equal(t, 1, calls, "an unchanged file is not parsed again")
+
+	_, err = Load(c, "privatekey", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "namespaces are cached separately")
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, later, later))
+	_, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "a touched file with the same contents is not parsed again")
+
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0644))
+	v, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, summary{1, "second"}, v)
+	assert.Equal(t, 3, calls)
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 3}, c.Stats())
+
+	_, err = Load(c, "certificate", filepath.Join(dir, "missing.crt"), counter(&calls, true))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadNotCacheable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "encrypted.key")
+	require.NoError(t, os.WriteFile(path, []byte("secret"), 0644))
+
+	c, err := Open(filepath.Join(dir, "cache.json"))
+	require.NoError(t, err)
+
+	calls := 0
+	for range 2 {
+		_, err := Load(c, "privatekey", path, counter(&calls, false))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, Stats{Misses: 2}, c.Stats())
+}
+
+func TestLoadNilCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.crt")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+
+	calls := 0
+	for range 2 {
+		v, err := Load(nil, "certificate", path, counter(&calls, true))
+		require.NoError(t, err)
+		assert.Equal(t, "data", v.Text)
+	}
+	assert.Equal(t, 2, calls)
+
+	var c *Cache
+	assert.NoError(t, c.Save())
+	assert.Equal(t, Stats{}, c.Stats())
+}
+
+func TestOpenSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.crt")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+	cachePath := filepath.Join(dir, "cache.json")
+
+	c, err := Open(cachePath)
+	require.NoError(t, err)
+	calls := 0
+	_, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	require.NoError(t, c.Save())
+
+	c, err = Open(cachePath)
+	require.NoError(t, err)
+	_, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "the saved cache is used by the next run")
+
+	saved := Version
+	Version = "v0.0.0-other"
+	c, err = Open(cachePath)
+	Version = saved
+	require.NoError(t, err)
+	_, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "a cache written by another version is discarded")
+
+	require.NoError(t, os.WriteFile(cachePath, []byte("{truncated"), 0644))
+	c, err = Open(cachePath)
+	require.NoError(t, err)
+	_, err = Load(c, "certificate", path, counter(&calls, true))
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "a corrupt cache is discarded")
+	require.NoError(t, c.Save())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary file is left behind")
+}
+
+func TestSavePrunesDeletedFiles(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "cache.json")
+	var paths []string
+	for _, name := range []string{"kept.crt", "other.crt", "deleted.crt"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		paths = append(paths, path)
+	}
+
+	c, err := Open(cachePath)
+	require.NoError(t, err)
+	calls := 0
+	for _, path := range paths {
+		_, err := Load(c, "certificate", path, counter(&calls, true))
+		require.NoError(t, err)
+	}
+	require.NoError(t, c.Save())
+
+	// The next run only looks at one file; the file it did not look at is
+	// kept while it exists.
+	require.NoError(t, os.Remove(paths[2]))
+	c, err = Open(cachePath)
+	require.NoError(t, err)
+	_, err = Load(c, "certificate", paths[0], counter(&calls, true))
+	require.NoError(t, err)
+	require.NoError(t, c.Save())
+
+	c, err = Open(cachePath)
+	require.NoError(t, err)
+	assert.Len(t, c.entries["certificate"], 2)
+	assert.Contains(t, c.entries["certificate"], paths[0])
+	assert.Contains(t, c.entries["certificate"], paths[1])
+	assert.NotContains(t, c.entries["certificate"], paths[2], "the entry of a deleted file is dropped")
+}
@@ -1,9 +1,14 @@
 package certificate
 
 import (
-	"os"
-	"path/filepath"
+	"context"
 	"time"
+
+	"github.com/marco-introini/certinfo/pkg/cache"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/strength"
+	"github.com/marco-introini/certinfo/pkg/walk"
 )
 
 const daysUntilExpiring = 30
@@ -20,88 +25,165 @@ func getCertStatus(notAfter time.Time) string {
 }
 
 type CertificateSummary struct {
-	Filename      string
-	Encoding      string
-	CommonName    string
-	Issuer        string
-	Status        string
-	IsQuantumSafe bool
-	PQCTypes      []string
+	Filename     string
+	Index        int
+	Encoding     string
+	CommonName   string
+	Issuer       string
+	IssuerDN     string
+	SerialNumber string
+	// AuthorityKeyID tells the CRLs of CAs that share a DN apart.
+	AuthorityKeyID string `json:",omitempty"`
+	NotAfter       time.Time
+	Status         string
+	KeyType        string
+	Bits           int
+	IsCA           bool
+	KeyUsage       []string
+	ExtKeyUsage    []string
+	IsQuantumSafe  bool
+	QuantumSafety  string
+	PQCTypes       []string
+	SHA256         string
+	SPKISHA256     string
+	Strength       strength.Strength
 }
 
-func SummarizeDirectory(dirPath string) ([]CertificateSummary, error) {
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		return nil, err
-	}
+type Filter func(cert *CertificateInfo) bool
 
-	summaries := make([]CertificateSummary, 0, len(entries))
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		filePath := filepath.Join(dirPath, entry.Name())
+func MatchingSAN(name string) Filter {
+	return func(cert *CertificateInfo) bool {
+		return cert.CoversName(name)
+	}
+}
 
-		cert, err := ParseCertificate(filePath)
-		if err != nil {
-			continue
-		}
+// WeakerThan keeps certificates whose security strength, including the
+// strength of their signature hash, is known and below bits.
+func WeakerThan(bits int) Filter {
+	return func(cert *CertificateInfo) bool {
+		return cert.Strength.Below(bits)
+	}
+}
 
-		summary := CertificateSummary{
-			Filename:      entry.Name(),
-			Encoding:      cert.Encoding,
-			CommonName:    cert.CommonName,
-			Issuer:        cert.Issuer,
-			Status:        getCertStatus(cert.NotAfter),
-			IsQuantumSafe: cert.IsQuantumSafe,
-			PQCTypes:      cert.PQCTypes,
+func matchesFilters(cert *CertificateInfo, filters []Filter) bool {
+	for _, filter := range filters {
+		if !filter(cert) {
+			return false
 		}
+	}
+	return true
+}
 
-		summaries = append(summaries, summary)
+// NewCertificateSummary returns the directory listing row of cert.
+func NewCertificateSummary(filename string, cert *CertificateInfo) CertificateSummary {
+	return CertificateSummary{
+		Filename:       filename,
+		Index:          cert.Index,
+		Encoding:       cert.Encoding,
+		CommonName:     cert.CommonName,
+		Issuer:         cert.Issuer,
+		IssuerDN:       cert.IssuerDN,
+		SerialNumber:   cert.SerialNumber,
+		AuthorityKeyID: cert.AuthorityKeyID,
+		NotAfter:       cert.NotAfter,
+		Status:         getCertStatus(cert.NotAfter),
+		KeyType:        cert.KeyType,
+		Bits:           cert.Bits,
+		IsCA:           cert.IsCA,
+		KeyUsage:       cert.KeyUsage,
+		ExtKeyUsage:    cert.ExtKeyUsageStrings,
+		IsQuantumSafe:  cert.IsQuantumSafe,
+		QuantumSafety:  cert.QuantumSafety,
+		PQCTypes:       cert.PQCTypes,
+		SHA256:         cert.SHA256Fingerprint,
+		SPKISHA256:     cert.SPKISHA256,
+		Strength:       cert.Strength,
 	}
+}
 
-	return summaries, nil
+// fileSummary is what one file contributes to a directory summary.
+type fileSummary struct {
+	summaries []CertificateSummary
+	diag      *diag.Diagnostic
 }
 
-func SummarizeDirectoryRecursive(dirPath string) ([]CertificateSummary, error) {
-	summaries := make([]CertificateSummary, 0, 32)
+// cachedFile is what the cache keeps of a file: its certificates before
+// filtering, or the diagnostic of why it holds none.
+type cachedFile struct {
+	Certs []*CertificateInfo `json:",omitempty"`
+	Diag  *diag.Diagnostic   `json:",omitempty"`
+}
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
+// summarizeFile lists the certificates of f, or the diagnostic of why it
+// holds none. Archive entries are parsed in memory and not cached.
+func summarizeFile(f walk.File, c *cache.Cache, filters []Filter) fileSummary {
+	if f.Err != nil {
+		d := diag.ForPath(f.RelPath, f.Err)
+		return fileSummary{diag: &d}
+	}
 
-		if info.IsDir() {
-			return nil
+	parse := func(data []byte) (cachedFile, bool) {
+		certs, err := ParseCertificatesFromBytes(data, f.RelPath)
+		if err != nil {
+			d := diag.ForFile(f.RelPath, data, err, pem.KindCertificate)
+			return cachedFile{Diag: &d}, true
 		}
+		return cachedFile{Certs: certs}, true
+	}
 
-		relPath, _ := filepath.Rel(dirPath, path)
+	var parsed cachedFile
+	var err error
+	if f.Data != nil {
+		parsed, _ = parse(f.Data)
+	} else {
+		parsed, err = cache.Load(c, "certificate", f.Path, parse)
+	}
+	if err != nil {
+		d := diag.ForFile(f.RelPath, nil, err)
+		return fileSummary{diag: &d}
+	}
+	if parsed.Diag != nil {
+		parsed.Diag.Path = f.RelPath
+		return fileSummary{diag: parsed.Diag}
+	}
 
-		cert, err := ParseCertificate(path)
-		if err != nil {
-			return nil
+	var result fileSummary
+	for _, cert := range parsed.Certs {
+		if matchesFilters(cert, filters) {
+			result.summaries = append(result.summaries, NewCertificateSummary(f.RelPath, cert))
 		}
+	}
+	return result
+}
 
-		summary := CertificateSummary{
-			Filename:      relPath,
-			Encoding:      cert.Encoding,
-			CommonName:    cert.CommonName,
-			Issuer:        cert.Issuer,
-			Status:        getCertStatus(cert.NotAfter),
-			IsQuantumSafe: cert.IsQuantumSafe,
-			PQCTypes:      cert.PQCTypes,
-		}
+// SummarizeDirectory lists the certificates of the files in dirPath, and
+// the diagnostics of the files that hold none.
+func SummarizeDirectory(dirPath string, filters ...Filter) ([]CertificateSummary, []diag.Diagnostic, error) {
+	return SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{}, filters...)
+}
 
-		summaries = append(summaries, summary)
+func SummarizeDirectoryRecursive(dirPath string, filters ...Filter) ([]CertificateSummary, []diag.Diagnostic, error) {
+	return SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Recursive: true}, filters...)
+}
 
-		return nil
+// SummarizeDirectoryContext is SummarizeDirectory with the files parsed on
+// opts.Jobs workers. The summaries keep the walk order. Filters may be called
+// from several goroutines at once.
+func SummarizeDirectoryContext(ctx context.Context, dirPath string, opts walk.Options, filters ...Filter) ([]CertificateSummary, []diag.Diagnostic, error) {
+	files, err := walk.Map(ctx, dirPath, opts, func(f walk.File) fileSummary {
+		return summarizeFile(f, opts.Cache, filters)
 	})
-
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return summaries, nil
+	summaries := make([]CertificateSummary, 0, len(files))
+	var diags []diag.Diagnostic
+	for _, f := range files {
+		summaries = append(summaries, f.summaries...)
+		if f.diag != nil {
+			diags = append(diags, *f.diag)
+		}
+	}
+	return summaries, diags, nil
 }
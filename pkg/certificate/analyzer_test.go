@@ -1,17 +1,34 @@
 package certificate
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/marco-introini/certinfo/pkg/cache"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/walk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSummarizeDirectory(t *testing.T) {
 	dirPath := filepath.Join("..", "..", "test_certs", "traditional", "rsa")
-	summaries, err := SummarizeDirectory(dirPath)
+	summaries, _, err := SummarizeDirectory(dirPath)
 	require.NoError(t, err, "failed to summarize directory")
 	assert.NotEmpty(t, summaries, "expected at least one certificate summary")
 
@@ -31,7 +48,7 @@ func TestSummarizeDirectory(t *testing.T) {
 
 func TestSummarizeDirectoryExpired(t *testing.T) {
 	dirPath := filepath.Join("..", "..", "test_certs", "expired")
-	summaries, err := SummarizeDirectory(dirPath)
+	summaries, _, err := SummarizeDirectory(dirPath)
 	require.NoError(t, err, "failed to summarize expired directory")
 	assert.NotEmpty(t, summaries, "expected at least one certificate summary")
 
@@ -42,33 +59,33 @@ func TestSummarizeDirectoryExpired(t *testing.T) {
 
 func TestSummarizeDirectoryRecursive(t *testing.T) {
 	dirPath := filepath.Join("..", "..", "test_certs")
-	summaries, err := SummarizeDirectoryRecursive(dirPath)
+	summaries, _, err := SummarizeDirectoryRecursive(dirPath)
 	require.NoError(t, err, "failed to summarize directory recursively")
 	assert.GreaterOrEqual(t, len(summaries), 10, "expected many certificates")
 }
 
 func TestSummarizeDirectoryNotFound(t *testing.T) {
-	_, err := SummarizeDirectory("/nonexistent/path")
+	_, _, err := SummarizeDirectory("/nonexistent/path")
 	assert.Error(t, err, "expected error for non-existent directory")
 }
 
 func TestSummarizeECDSA(t *testing.T) {
 	dirPath := filepath.Join("..", "..", "test_certs", "traditional", "ecdsa")
-	summaries, err := SummarizeDirectory(dirPath)
+	summaries, _, err := SummarizeDirectory(dirPath)
 	require.NoError(t, err, "failed to summarize ECDSA directory")
 	assert.NotEmpty(t, summaries, "expected at least one ECDSA certificate")
 }
 
 func TestSummarizeCertificateChain(t *testing.T) {
 	dirPath := filepath.Join("..", "..", "test_certs", "chain")
-	summaries, err := SummarizeDirectory(dirPath)
+	summaries, _, err := SummarizeDirectory(dirPath)
 	require.NoError(t, err, "failed to summarize chain directory")
 	assert.GreaterOrEqual(t, len(summaries), 3, "expected at least 3 certificates in chain")
 }
 
 func TestSummarizeDirectoryEmpty(t *testing.T) {
 	emptyDir := t.TempDir()
-	summaries, err := SummarizeDirectory(emptyDir)
+	summaries, _, err := SummarizeDirectory(emptyDir)
 	require.NoError(t, err, "failed to summarize empty directory")
 	assert.Empty(t, summaries, "expected no summaries for empty directory")
 }
@@ -88,9 +105,202 @@ func TestSummarizeDirectoryWithInvalidFiles(t *testing.T) {
 	err = os.WriteFile(filepath.Join(dirPath, "empty.txt"), []byte{}, 0644)
 	require.NoError(t, err)
 
-	summaries, err := SummarizeDirectory(dirPath)
+	err = os.WriteFile(filepath.Join(dirPath, "broken.crt"), []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"), 0644)
+	require.NoError(t, err)
+
+	summaries, diags, err := SummarizeDirectory(dirPath)
 	require.NoError(t, err, "failed to summarize directory with invalid files")
 
 	assert.Equal(t, 1, len(summaries), "expected only 1 valid certificate summary")
 	assert.Equal(t, "valid.crt", summaries[0].Filename)
+
+	require.Len(t, diags, 3)
+	assert.Equal(t, "broken.crt", diags[0].Path)
+	assert.Equal(t, diag.Corrupt, diags[0].Category)
+	assert.Equal(t, "empty.txt", diags[1].Path)
+	assert.Equal(t, diag.Unrecognized, diags[1].Category)
+	assert.Equal(t, "invalid.crt", diags[2].Path)
+	assert.Equal(t, diag.Unrecognized, diags[2].Category)
+}
+
+func TestSummarizeDirectoryBundle(t *testing.T) {
+	dirPath := filepath.Join("..", "..", "test_certs", "chain")
+	summaries, _, err := SummarizeDirectory(dirPath)
+	require.NoError(t, err, "failed to summarize chain directory")
+
+	var bundle []CertificateSummary
+	for _, s := range summaries {
+		if s.Filename == "fullchain.crt" {
+			bundle = append(bundle, s)
+		}
+	}
+	require.Len(t, bundle, 2, "expected one row per certificate in the bundle")
+	assert.Equal(t, 0, bundle[0].Index)
+	assert.Equal(t, "localhost", bundle[0].CommonName)
+	assert.Equal(t, 1, bundle[1].Index)
+	assert.Equal(t, "Test Intermediate CA", bundle[1].CommonName)
+}
+
+func TestSummarizeDirectoryMatchingSAN(t *testing.T) {
+	dirPath := filepath.Join("..", "..", "test_certs", "san-types")
+	summaries, _, err := SummarizeDirectory(dirPath, MatchingSAN("spiffe://test.local/ns/default/sa/web"))
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "san-mixed.crt", summaries[0].Filename)
+
+	summaries, _, err = SummarizeDirectoryRecursive(filepath.Join("..", "..", "test_certs"), MatchingSAN("::1"))
+	require.NoError(t, err)
+	assert.Len(t, summaries, 2, "expected the RSA and ECDSA SAN certificates")
+
+	summaries, _, err = SummarizeDirectory(dirPath, MatchingSAN("nothing.example"))
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestSummarizeDirectoryWeakerThan(t *testing.T) {
+	dirPath := filepath.Join("..", "..", "test_certs", "traditional", "rsa")
+	summaries, _, err := SummarizeDirectory(dirPath, WeakerThan(128))
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	for _, s := range summaries {
+		assert.Contains(t, s.Filename, "rsa2048")
+		assert.Equal(t, 112, s.Strength.Bits)
+	}
+
+	summaries, _, err = SummarizeDirectory(dirPath, WeakerThan(112))
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestSummarizeDirectoryContext(t *testing.T) {
+	dirPath := filepath.Join("..", "..", "test_certs")
+	sequential, seqDiags, err := SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Recursive: true, Jobs: 1})
+	require.NoError(t, err)
+	parallel, parDiags, err := SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Recursive: true, Jobs: 8})
+	require.NoError(t, err)
+	assert.Equal(t, sequential, parallel, "summaries must not depend on the number of workers")
+	assert.Equal(t, seqDiags, parDiags)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = SummarizeDirectoryContext(ctx, dirPath, walk.Options{Recursive: true})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSummarizeDirectoryCache(t *testing.T) {
+	dirPath := filepath.Join("..", "..", "test_certs")
+	uncached, uncachedDiags, err := SummarizeDirectoryRecursive(dirPath, WeakerThan(128))
+	require.NoError(t, err)
+
+	c, err := cache.Open(filepath.Join(t.TempDir(), "cache.json"))
+	require.NoError(t, err)
+	opts := walk.Options{Recursive: true, Cache: c}
+
+	for range 2 {
+		summaries, diags, err := SummarizeDirectoryContext(context.Background(), dirPath, opts, WeakerThan(128))
+		require.NoError(t, err)
+		assert.Equal(t, uncached, summaries)
+		assert.Equal(t, uncachedDiags, diags)
+	}
+	stats := c.Stats()
+	assert.Positive(t, stats.Misses)
+	assert.Equal(t, stats.Misses, stats.Hits, "the second walk is served from the cache")
+}
+
+func TestSummarizeDirectoryArchives(t *testing.T) {
+	server, err := os.ReadFile(filepath.Join("..", "..", "test_certs", "chain", "server.crt"))
+	require.NoError(t, err)
+	client, err := os.ReadFile(filepath.Join("..", "..", "test_certs", "client", "client.crt"))
+	require.NoError(t, err)
+
+	var jar bytes.Buffer
+	zw := zip.NewWriter(&jar)
+	w, err := zw.Create("certs/client.crt")
+	require.NoError(t, err)
+	_, err = w.Write(client)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var tgz bytes.Buffer
+	gw := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gw)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"etc/ssl/server.pem", server}, {"lib/app.jar", jar.Bytes()}, {"README", []byte("readme")}} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.data))}))
+		_, err := tw.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	dirPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dirPath, "bundle.tar.gz"), tgz.Bytes(), 0644))
+
+	summaries, diags, err := SummarizeDirectory(dirPath)
+	require.NoError(t, err)
+	assert.Empty(t, summaries, "archives are not opened by default")
+	require.Len(t, diags, 1)
+
+	c, err := cache.Open(filepath.Join(t.TempDir(), "cache.json"))
+	require.NoError(t, err)
+	summaries, diags, err = SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Archives: true, Cache: c})
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "bundle.tar.gz!/etc/ssl/server.pem", summaries[0].Filename)
+	assert.Equal(t, "localhost", summaries[0].CommonName)
+	assert.Equal(t, "bundle.tar.gz!/lib/app.jar!/certs/client.crt", summaries[1].Filename)
+	assert.Equal(t, "testclient", summaries[1].CommonName)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "bundle.tar.gz!/README", diags[0].Path)
+	assert.Equal(t, diag.Unrecognized, diags[0].Category)
+	assert.Equal(t, cache.Stats{}, c.Stats(), "archive entries are not cached")
+
+	_, diags, err = SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Archives: true, ArchiveDepth: 1})
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, "bundle.tar.gz!/lib/app.jar", diags[0].Path)
+	assert.Equal(t, diag.Skipped, diags[0].Category)
+}
+
+// writeCertTree writes n certificates into 10 subdirectories of a new
+// directory.
+func writeCertTree(b *testing.B, n int) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(b, err)
+
+	root := b.TempDir()
+	for i := range n {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 1)),
+			Subject:      pkix.Name{CommonName: fmt.Sprintf("host%d.example.com", i)},
+			DNSNames:     []string{fmt.Sprintf("host%d.example.com", i)},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(b, err)
+
+		dir := filepath.Join(root, fmt.Sprintf("dir%d", i%10))
+		require.NoError(b, os.MkdirAll(dir, 0755))
+		data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		require.NoError(b, os.WriteFile(filepath.Join(dir, fmt.Sprintf("host%d.crt", i)), data, 0644))
+	}
+	return root
+}
+
+func BenchmarkSummarizeDirectory(b *testing.B) {
+	root := writeCertTree(b, 1000)
+
+	for _, jobs := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for b.Loop() {
+				summaries, _, err := SummarizeDirectoryContext(context.Background(), root, walk.Options{Recursive: true, Jobs: jobs})
+				if err != nil || len(summaries) != 1000 {
+					b.Fatalf("got %d summaries, err %v", len(summaries), err)
+				}
+			}
+		})
+	}
 }
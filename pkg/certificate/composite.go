@@ -0,0 +1,205 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+
+	"github.com/marco-introini/certinfo/pkg/pqc"
+)
+
+const (
+	QuantumClassical = "classical"
+	QuantumHybrid    = "hybrid"
+	QuantumPQC       = "pqc"
+)
+
+// Alternative public key and signature extensions (ITU-T X.509 (10/2019)),
+// used by dual-signature certificates.
+const (
+	oidExtSubjectAltPublicKeyInfo = "2.5.29.72"
+	oidExtAltSignatureAlgorithm   = "2.5.29.73"
+	oidExtAltSignatureValue       = "2.5.29.74"
+)
+
+var classicalSignatureNames = map[string]string{
+	"1.2.840.113549.1.1.11": "SHA256-RSA",
+	"1.2.840.113549.1.1.12": "SHA384-RSA",
+	"1.2.840.113549.1.1.13": "SHA512-RSA",
+	"1.2.840.113549.1.1.10": "RSASSA-PSS",
+	"1.2.840.10045.4.3.2":   "ECDSA-SHA256",
+	"1.2.840.10045.4.3.3":   "ECDSA-SHA384",
+	"1.2.840.10045.4.3.4":   "ECDSA-SHA512",
+	"1.3.101.112":           "Ed25519",
+	"1.3.101.113":           "Ed448",
+}
+
+// Component is one algorithm of a composite key or signature, or an
+// alternative key or signature. Size is the encoded length in bytes.
+type Component struct {
+	Algorithm string
+	KeyType   string
+	Bits      int
+	Category  int
+	PQC       bool
+	Size      int
+}
+
+func pqcComponent(alg pqc.Algorithm, size int) Component {
+	return Component{
+		Algorithm: alg.Name,
+		KeyType:   alg.KeyType(),
+		Bits:      alg.Bits,
+		Category:  alg.Category,
+		PQC:       true,
+		Size:      size,
+	}
+}
+
+// splitComposite breaks a composite public key or signature into its ML-DSA
+// and traditional parts. Early drafts encode them as a SEQUENCE of two BIT
+// STRINGs; current drafts concatenate the fixed-size ML-DSA value with the
+// traditional one.
+func splitComposite(alg pqc.Algorithm, data []byte, signature bool) []Component {
+	component, ok := pqc.ByName(alg.Component)
+	if !ok {
+		return nil
+	}
+
+	var pqPart, tradPart []byte
+	var parts []asn1.BitString
+	if rest, err := asn1.Unmarshal(data, &parts); err == nil && len(rest) == 0 && len(parts) == 2 {
+		pqPart, tradPart = parts[0].Bytes, parts[1].Bytes
+	} else {
+		size := component.PublicKeySize
+		if signature {
+			size = component.SignatureSize
+		}
+		if len(data) < size {
+			return nil
+		}
+		pqPart, tradPart = data[:size], data[size:]
+	}
+
+	classical := Component{
+		Algorithm: alg.Classical,
+		KeyType:   alg.ClassicalKeyType,
+		Bits:      alg.ClassicalBits,
+		Size:      len(tradPart),
+	}
+	if !signature && alg.ClassicalKeyType == "RSA" {
+		if key, err := x509.ParsePKCS1PublicKey(tradPart); err == nil {
+			classical.Bits = key.N.BitLen()
+		}
+	}
+
+	return []Component{pqcComponent(component, len(pqPart)), classical}
+}
+
+// spkiComponent describes the key in a DER-encoded SubjectPublicKeyInfo.
+func spkiComponent(spki []byte) *Component {
+	oid, key, err := pqc.PublicKey(spki)
+	if err != nil {
+		return nil
+	}
+	if alg, ok := pqc.ByOID(oid); ok {
+		c := pqcComponent(alg, len(key))
+		return &c
+	}
+
+	c := &Component{Algorithm: oid, KeyType: oid, Size: len(key)}
+	if pub, err := x509.ParsePKIXPublicKey(spki); err == nil {
+		c.KeyType, c.Bits = KeyTypeAndBits(pub)
+		c.Algorithm = c.KeyType
+	}
+	return c
+}
+
+func decodeAltExtensions(cert *x509.Certificate, info *CertificateInfo) {
+	var sigAlgorithm string
+	var sigValue []byte
+	for _, ext := range cert.Extensions {
+		switch ext.Id.String() {
+		case oidExtSubjectAltPublicKeyInfo:
+			info.AltPublicKey = spkiComponent(ext.Value)
+		case oidExtAltSignatureAlgorithm:
+			var ai struct {
+				Algorithm  asn1.ObjectIdentifier
+				Parameters asn1.RawValue `asn1:"optional"`
+			}
+			if _, err := asn1.Unmarshal(ext.Value, &ai); err == nil {
+				sigAlgorithm = ai.Algorithm.String()
+			}
+		case oidExtAltSignatureValue:
+			var value asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &value); err == nil {
+				sigValue = value.Bytes
+			}
+		}
+	}
+
+	if sigAlgorithm == "" {
+		return
+	}
+	if alg, ok := pqc.ByOID(sigAlgorithm); ok {
+		c := pqcComponent(alg, len(sigValue))
+		info.AltSignature = &c
+		return
+	}
+	c := &Component{Algorithm: sigAlgorithm, Size: len(sigValue)}
+	if name, ok := classicalSignatureNames[sigAlgorithm]; ok {
+		c.Algorithm = name
+	}
+	info.AltSignature = c
+}
+
+// classifyQuantumSafety combines the primary and alternative algorithms of a
+// certificate: pqc when all are post-quantum, hybrid when post-quantum and
+// classical algorithms are mixed (including composites), classical otherwise.
+func classifyQuantumSafety(info *CertificateInfo, sigAlg, keyAlg *pqc.Algorithm) string {
+	var hasPQC, hasClassical bool
+	mark := func(alg *pqc.Algorithm) {
+		switch {
+		case alg == nil:
+			hasClassical = true
+		case alg.Composite:
+			hasPQC, hasClassical = true, true
+		default:
+			hasPQC = true
+		}
+	}
+	mark(sigAlg)
+	mark(keyAlg)
+	for _, alt := range []*Component{info.AltPublicKey, info.AltSignature} {
+		if alt == nil {
+			continue
+		}
+		if alt.PQC {
+			hasPQC = true
+		} else {
+			hasClassical = true
+		}
+	}
+
+	switch {
+	case hasPQC && hasClassical:
+		return QuantumHybrid
+	case hasPQC:
+		return QuantumPQC
+	default:
+		return QuantumClassical
+	}
+}
+
+func decodeComposite(cert *x509.Certificate, info *CertificateInfo, sigAlg, keyAlg *pqc.Algorithm) {
+	if keyAlg != nil && keyAlg.Composite {
+		if _, key, err := pqc.PublicKey(cert.RawSubjectPublicKeyInfo); err == nil {
+			info.KeyComponents = splitComposite(*keyAlg, key, false)
+		}
+	}
+	if sigAlg != nil && sigAlg.Composite {
+		info.SignatureComponents = splitComposite(*sigAlg, cert.Signature, true)
+	}
+	decodeAltExtensions(cert, info)
+	info.QuantumSafety = classifyQuantumSafety(info, sigAlg, keyAlg)
+	info.IsQuantumSafe = info.QuantumSafety != QuantumClassical
+}
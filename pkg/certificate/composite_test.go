@@ -0,0 +1,203 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oidMLDSA44            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}
+	oidMLDSA65ECDSAP256   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 45}
+	oidMLDSA44RSA2048PSS  = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 37}
+	oidECDSAWithSHA256    = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidAltPublicKeyInfo   = asn1.ObjectIdentifier{2, 5, 29, 72}
+	oidAltSignatureAlgo   = asn1.ObjectIdentifier{2, 5, 29, 73}
+	oidAltSignatureValue  = asn1.ObjectIdentifier{2, 5, 29, 74}
+	oidECPublicKey        = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256     = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidEd25519            = asn1.ObjectIdentifier{1, 3, 101, 112}
+	testCompositeSigBytes = 70
+)
+
+func ecdsaPoint(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	spki, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	_, err = asn1.Unmarshal(spki, &info)
+	require.NoError(t, err)
+	return info.PublicKey.Bytes
+}
+
+func TestParseCompositeCertificate(t *testing.T) {
+	point := ecdsaPoint(t)
+	publicKey := append(make([]byte, 1952), point...)
+	signature := make([]byte, 3309+testCompositeSigBytes)
+
+	der := buildCertificateDER(t, "composite", oidMLDSA65ECDSAP256, oidMLDSA65ECDSAP256, publicKey, signature)
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+
+	assert.Equal(t, "MLDSA65-ECDSA-P256-SHA512", cert.Algorithm)
+	assert.Equal(t, "Composite ML-DSA", cert.KeyType)
+	assert.Equal(t, QuantumHybrid, cert.QuantumSafety)
+	assert.True(t, cert.IsQuantumSafe)
+
+	assert.Equal(t, []Component{
+		{Algorithm: "ML-DSA-65", KeyType: "ML-DSA", Bits: 65, Category: 3, PQC: true, Size: 1952},
+		{Algorithm: "ECDSA P-256", KeyType: "ECDSA", Bits: 256, Size: 65},
+	}, cert.KeyComponents)
+	assert.Equal(t, []Component{
+		{Algorithm: "ML-DSA-65", KeyType: "ML-DSA", Bits: 65, Category: 3, PQC: true, Size: 3309},
+		{Algorithm: "ECDSA P-256", KeyType: "ECDSA", Bits: 256, Size: testCompositeSigBytes},
+	}, cert.SignatureComponents)
+}
+
+func TestParseCompositeCertificateSequenceEncoding(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPublic := x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)
+
+	publicKey, err := asn1.Marshal([]asn1.BitString{
+		{Bytes: make([]byte, 1312), BitLength: 8 * 1312},
+		{Bytes: rsaPublic, BitLength: 8 * len(rsaPublic)},
+	})
+	require.NoError(t, err)
+	signature, err := asn1.Marshal([]asn1.BitString{
+		{Bytes: make([]byte, 2420), BitLength: 8 * 2420},
+		{Bytes: make([]byte, 256), BitLength: 8 * 256},
+	})
+	require.NoError(t, err)
+
+	der := buildCertificateDER(t, "composite-rsa", oidMLDSA44RSA2048PSS, oidMLDSA44RSA2048PSS, publicKey, signature)
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+
+	require.Len(t, cert.KeyComponents, 2)
+	assert.Equal(t, "ML-DSA-44", cert.KeyComponents[0].Algorithm)
+	assert.Equal(t, 1312, cert.KeyComponents[0].Size)
+	assert.Equal(t, "RSA-PSS 2048", cert.KeyComponents[1].Algorithm)
+	assert.Equal(t, "RSA", cert.KeyComponents[1].KeyType)
+	assert.Equal(t, 2048, cert.KeyComponents[1].Bits)
+
+	require.Len(t, cert.SignatureComponents, 2)
+	assert.Equal(t, 2420, cert.SignatureComponents[0].Size)
+	assert.Equal(t, 256, cert.SignatureComponents[1].Size)
+	assert.Equal(t, QuantumHybrid, cert.QuantumSafety)
+}
+
+func TestParseCompositeCertificateTruncatedKey(t *testing.T) {
+	der := buildCertificateDER(t, "short", oidMLDSA65ECDSAP256, oidMLDSA65ECDSAP256, make([]byte, 100), make([]byte, 64))
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+	assert.Empty(t, cert.KeyComponents)
+	assert.Empty(t, cert.SignatureComponents)
+	assert.Equal(t, QuantumHybrid, cert.QuantumSafety)
+}
+
+func TestParseDualSignatureCertificate(t *testing.T) {
+	altSPKI, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{pkix.AlgorithmIdentifier{Algorithm: oidMLDSA44}, asn1.BitString{Bytes: make([]byte, 1312), BitLength: 8 * 1312}})
+	require.NoError(t, err)
+	altAlgorithm, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: oidMLDSA44})
+	require.NoError(t, err)
+	altValue, err := asn1.Marshal(asn1.BitString{Bytes: make([]byte, 2420), BitLength: 8 * 2420})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(7),
+		Subject:      pkix.Name{CommonName: "dual"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidAltPublicKeyInfo, Value: altSPKI},
+			{Id: oidAltSignatureAlgo, Value: altAlgorithm},
+			{Id: oidAltSignatureValue, Value: altValue},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ECDSA", cert.KeyType)
+	assert.Equal(t, QuantumHybrid, cert.QuantumSafety)
+	require.NotNil(t, cert.AltPublicKey)
+	assert.Equal(t, "ML-DSA-44", cert.AltPublicKey.Algorithm)
+	assert.Equal(t, 2, cert.AltPublicKey.Category)
+	assert.Equal(t, 1312, cert.AltPublicKey.Size)
+	require.NotNil(t, cert.AltSignature)
+	assert.Equal(t, "ML-DSA-44", cert.AltSignature.Algorithm)
+	assert.Equal(t, 2420, cert.AltSignature.Size)
+	assert.Empty(t, cert.OtherExtensions)
+}
+
+func TestParseAltClassicalPublicKey(t *testing.T) {
+	point := ecdsaPoint(t)
+	params, err := asn1.Marshal(oidNamedCurveP256)
+	require.NoError(t, err)
+	altSPKI, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{pkix.AlgorithmIdentifier{Algorithm: oidECPublicKey, Parameters: asn1.RawValue{FullBytes: params}}, asn1.BitString{Bytes: point, BitLength: 8 * len(point)}})
+	require.NoError(t, err)
+	altAlgorithm, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256})
+	require.NoError(t, err)
+
+	// A pure ML-DSA certificate carrying a classical alternative key.
+	der := buildCertificateDER(t, "pqc-primary", oidMLDSA44, oidMLDSA44, make([]byte, 1312), make([]byte, 2420),
+		pkix.Extension{Id: oidAltPublicKeyInfo, Value: altSPKI},
+		pkix.Extension{Id: oidAltSignatureAlgo, Value: altAlgorithm},
+	)
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+
+	require.NotNil(t, cert.AltPublicKey)
+	assert.Equal(t, "ECDSA", cert.AltPublicKey.KeyType)
+	assert.Equal(t, 256, cert.AltPublicKey.Bits)
+	assert.False(t, cert.AltPublicKey.PQC)
+	require.NotNil(t, cert.AltSignature)
+	assert.Equal(t, "ECDSA-SHA256", cert.AltSignature.Algorithm)
+	assert.Equal(t, QuantumHybrid, cert.QuantumSafety)
+}
+
+func TestQuantumSafetyClassification(t *testing.T) {
+	classical, err := ParseCertificate(getTestCertPath("traditional/rsa/server-rsa2048.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, QuantumClassical, classical.QuantumSafety)
+	assert.False(t, classical.IsQuantumSafe)
+
+	der := buildCertificateDER(t, "pure", oidMLDSA44, oidMLDSA44, make([]byte, 1312), make([]byte, 2420))
+	pure, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+	assert.Equal(t, QuantumPQC, pure.QuantumSafety)
+	assert.True(t, pure.IsQuantumSafe)
+	assert.Empty(t, pure.KeyComponents)
+
+	// An ML-DSA CA signing a classical leaf key.
+	der = buildCertificateDER(t, "mixed", oidMLDSA44, oidEd25519, make([]byte, 32), make([]byte, 2420))
+	mixed, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+	assert.Equal(t, QuantumHybrid, mixed.QuantumSafety)
+}
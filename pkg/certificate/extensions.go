@@ -0,0 +1,195 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+)
+
+const (
+	oidExtKeyUsage              = "2.5.29.15"
+	oidExtBasicConstraints      = "2.5.29.19"
+	oidExtSubjectKeyID          = "2.5.29.14"
+	oidExtAuthorityKeyID        = "2.5.29.35"
+	oidExtSubjectAltName        = "2.5.29.17"
+	oidExtExtendedKeyUsage      = "2.5.29.37"
+	oidExtCRLDistributionPoints = "2.5.29.31"
+	oidExtAuthorityInfoAccess   = "1.3.6.1.5.5.7.1.1"
+	oidExtCertificatePolicies   = "2.5.29.32"
+	oidExtNameConstraints       = "2.5.29.30"
+	oidExtTLSFeature            = "1.3.6.1.5.5.7.1.24"
+)
+
+// tlsFeatureStatusRequest is the status_request TLS extension number
+// carried in the TLS feature extension by Must-Staple certificates (RFC 7633).
+const tlsFeatureStatusRequest = 5
+
+var decodedExtensions = map[string]bool{
+	oidExtKeyUsage:                true,
+	oidExtBasicConstraints:        true,
+	oidExtSubjectKeyID:            true,
+	oidExtAuthorityKeyID:          true,
+	oidExtSubjectAltName:          true,
+	oidExtExtendedKeyUsage:        true,
+	oidExtCRLDistributionPoints:   true,
+	oidExtAuthorityInfoAccess:     true,
+	oidExtCertificatePolicies:     true,
+	oidExtNameConstraints:         true,
+	oidExtTLSFeature:              true,
+	oidExtSubjectAltPublicKeyInfo: true,
+	oidExtAltSignatureAlgorithm:   true,
+	oidExtAltSignatureValue:       true,
+}
+
+var policyNames = map[string]string{
+	"2.5.29.32.0":    "Any Policy",
+	"2.23.140.1.1":   "Extended Validation",
+	"2.23.140.1.2.1": "Domain Validated",
+	"2.23.140.1.2.2": "Organization Validated",
+	"2.23.140.1.2.3": "Individual Validated",
+}
+
+type BasicConstraints struct {
+	IsCA    bool
+	PathLen *int
+}
+
+type NameConstraints struct {
+	Critical        bool
+	PermittedDNS    []string
+	ExcludedDNS     []string
+	PermittedIPs    []string
+	ExcludedIPs     []string
+	PermittedEmails []string
+	ExcludedEmails  []string
+	PermittedURIs   []string
+	ExcludedURIs    []string
+}
+
+type ExtensionInfo struct {
+	OID      string
+	Critical bool
+}
+
+func KeyUsageStrings(ku x509.KeyUsage) []string {
+	names := []struct {
+		bit  x509.KeyUsage
+		name string
+	}{
+		{x509.KeyUsageDigitalSignature, "Digital Signature"},
+		{x509.KeyUsageContentCommitment, "Content Commitment"},
+		{x509.KeyUsageKeyEncipherment, "Key Encipherment"},
+		{x509.KeyUsageDataEncipherment, "Data Encipherment"},
+		{x509.KeyUsageKeyAgreement, "Key Agreement"},
+		{x509.KeyUsageCertSign, "Certificate Sign"},
+		{x509.KeyUsageCRLSign, "CRL Sign"},
+		{x509.KeyUsageEncipherOnly, "Encipher Only"},
+		{x509.KeyUsageDecipherOnly, "Decipher Only"},
+	}
+
+	var usages []string
+	for _, n := range names {
+		if ku&n.bit != 0 {
+			usages = append(usages, n.name)
+		}
+	}
+	return usages
+}
+
+func formatKeyID(id []byte) string {
+	return fingerprint.ColonHex(id)
+}
+
+func policyToString(oid string) string {
+	if name, ok := policyNames[oid]; ok {
+		return fmt.Sprintf("%s (%s)", oid, name)
+	}
+	return oid
+}
+
+func hasMustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() != oidExtTLSFeature {
+			continue
+		}
+		var features []int
+		if _, err := asn1.Unmarshal(ext.Value, &features); err != nil {
+			return false
+		}
+		for _, f := range features {
+			if f == tlsFeatureStatusRequest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getNameConstraints(cert *x509.Certificate) *NameConstraints {
+	hasExtension := false
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() == oidExtNameConstraints {
+			hasExtension = true
+			break
+		}
+	}
+	if !hasExtension {
+		return nil
+	}
+
+	nc := &NameConstraints{
+		Critical:        cert.PermittedDNSDomainsCritical,
+		PermittedDNS:    cert.PermittedDNSDomains,
+		ExcludedDNS:     cert.ExcludedDNSDomains,
+		PermittedEmails: cert.PermittedEmailAddresses,
+		ExcludedEmails:  cert.ExcludedEmailAddresses,
+		PermittedURIs:   cert.PermittedURIDomains,
+		ExcludedURIs:    cert.ExcludedURIDomains,
+	}
+	for _, ipNet := range cert.PermittedIPRanges {
+		nc.PermittedIPs = append(nc.PermittedIPs, ipNet.String())
+	}
+	for _, ipNet := range cert.ExcludedIPRanges {
+		nc.ExcludedIPs = append(nc.ExcludedIPs, ipNet.String())
+	}
+	return nc
+}
+
+func decodeExtensions(cert *x509.Certificate, info *CertificateInfo) {
+	info.KeyUsage = KeyUsageStrings(cert.KeyUsage)
+
+	if cert.BasicConstraintsValid {
+		bc := &BasicConstraints{IsCA: cert.IsCA}
+		if cert.IsCA && (cert.MaxPathLen > 0 || cert.MaxPathLenZero) {
+			pathLen := cert.MaxPathLen
+			bc.PathLen = &pathLen
+		}
+		info.BasicConstraints = bc
+	}
+
+	info.SubjectKeyID = formatKeyID(cert.SubjectKeyId)
+	info.AuthorityKeyID = formatKeyID(cert.AuthorityKeyId)
+	info.CRLDistributionPoints = cert.CRLDistributionPoints
+	info.OCSPServers = cert.OCSPServer
+	info.IssuingCertificateURLs = cert.IssuingCertificateURL
+
+	for _, policy := range cert.Policies {
+		info.Policies = append(info.Policies, policyToString(policy.String()))
+	}
+
+	info.NameConstraints = getNameConstraints(cert)
+	info.MustStaple = hasMustStaple(cert)
+
+	for _, ext := range cert.Extensions {
+		oid := ext.Id.String()
+		if decodedExtensions[oid] {
+			continue
+		}
+		info.OtherExtensions = append(info.OtherExtensions, ExtensionInfo{
+			OID:      oid,
+			Critical: ext.Critical,
+		})
+	}
+}
@@ -2,22 +2,27 @@ package certificate
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
 	"os"
-	"strings"
 	"time"
 
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
 	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/marco-introini/certinfo/pkg/strength"
 )
 
 type CertificateInfo struct {
 	Filename           string
+	Index              int
 	Encoding           string
 	CommonName         string
 	Issuer             string
+	IssuerDN           string
 	Subject            string
 	NotBefore          time.Time
 	NotAfter           time.Time
@@ -26,17 +31,50 @@ type CertificateInfo struct {
 	Bits               int
 	SerialNumber       string
 	SANs               []string
+	IPSANs             []string
+	EmailSANs          []string
+	URISANs            []string
+	OtherNameSANs      []OtherName
 	IsCA               bool
 	ExtKeyUsage        []x509.ExtKeyUsage
 	ExtKeyUsageStrings []string
 	IsQuantumSafe      bool
+	QuantumSafety      string
 	PQCTypes           []string
+	SHA1Fingerprint    string
+	SHA256Fingerprint  string
+	SPKISHA256         string
+	SPKISHA256Hex      string
+
+	KeyUsage               []string
+	BasicConstraints       *BasicConstraints
+	SubjectKeyID           string
+	AuthorityKeyID         string
+	CRLDistributionPoints  []string
+	OCSPServers            []string
+	IssuingCertificateURLs []string
+	Policies               []string
+	NameConstraints        *NameConstraints
+	MustStaple             bool
+	OtherExtensions        []ExtensionInfo
+
+	KeyComponents       []Component
+	SignatureComponents []Component
+	AltPublicKey        *Component
+	AltSignature        *Component
+
+	Revocation *Revocation
+	Signature  *SignatureCheck
+
+	Strength strength.Strength
 }
 
-func getKeyBitsAndType(pub any) (string, int) {
+func KeyTypeAndBits(pub any) (string, int) {
 	switch key := pub.(type) {
 	case *rsa.PublicKey:
 		return "RSA", key.N.BitLen()
+	case ed25519.PublicKey:
+		return "Ed25519", 256
 	case *ecdsa.PublicKey:
 		switch key.Curve {
 		case elliptic.P256():
@@ -53,68 +91,25 @@ func getKeyBitsAndType(pub any) (string, int) {
 	}
 }
 
-func isPQCSignatureAlgorithmByName(algoName string) bool {
-	lowerAlgo := strings.ToLower(algoName)
-	return strings.Contains(lowerAlgo, "ml-dsa") ||
-		strings.Contains(lowerAlgo, "slh-dsa") ||
-		strings.Contains(lowerAlgo, "fn-dsa") ||
-		strings.Contains(lowerAlgo, "falcon") ||
-		strings.Contains(lowerAlgo, "rainbow") ||
-		strings.Contains(lowerAlgo, "sphincs") ||
-		strings.Contains(lowerAlgo, "dilithium") ||
-		strings.Contains(lowerAlgo, "kyber")
+// IsPQCAlgorithmName reports whether a free-form algorithm name refers to a
+// post-quantum algorithm family.
+func IsPQCAlgorithmName(algoName string) bool {
+	return pqc.FamilyFromName(algoName) != ""
 }
 
-func getPQCTypesFromAlgorithmName(algoName string) []string {
-	var pqcTypes []string
-	lowerAlgo := strings.ToLower(algoName)
-
-	if strings.Contains(lowerAlgo, "ml-dsa-44") || strings.Contains(lowerAlgo, "dilithium2") {
-		pqcTypes = append(pqcTypes, "ML-DSA-44")
-	}
-	if strings.Contains(lowerAlgo, "ml-dsa-45") || strings.Contains(lowerAlgo, "dilithium3") {
-		pqcTypes = append(pqcTypes, "ML-DSA-65")
-	}
-	if strings.Contains(lowerAlgo, "ml-dsa-87") || strings.Contains(lowerAlgo, "dilithium5") {
-		pqcTypes = append(pqcTypes, "ML-DSA-87")
-	}
-	if strings.Contains(lowerAlgo, "slh-dsa") || strings.Contains(lowerAlgo, "sphincs") {
-		if strings.Contains(lowerAlgo, "128") {
-			pqcTypes = append(pqcTypes, "SLH-DSA-128")
-		} else if strings.Contains(lowerAlgo, "192") {
-			pqcTypes = append(pqcTypes, "SLH-DSA-192")
-		} else if strings.Contains(lowerAlgo, "256") {
-			pqcTypes = append(pqcTypes, "SLH-DSA-256")
-		} else {
-			pqcTypes = append(pqcTypes, "SLH-DSA")
-		}
+// PQCTypesFromAlgorithmName returns the registry name for algoName, or just
+// its family when the parameter set is not recognised.
+func PQCTypesFromAlgorithmName(algoName string) []string {
+	if alg, ok := pqc.ByName(algoName); ok {
+		return []string{alg.Name}
 	}
-	if strings.Contains(lowerAlgo, "fn-dsa") || strings.Contains(lowerAlgo, "falcon") {
-		if strings.Contains(lowerAlgo, "128") || strings.Contains(lowerAlgo, "512") {
-			pqcTypes = append(pqcTypes, "FN-DSA-128")
-		} else if strings.Contains(lowerAlgo, "192") {
-			pqcTypes = append(pqcTypes, "FN-DSA-192")
-		} else if strings.Contains(lowerAlgo, "256") || strings.Contains(lowerAlgo, "1024") {
-			pqcTypes = append(pqcTypes, "FN-DSA-256")
-		} else {
-			pqcTypes = append(pqcTypes, "FN-DSA")
-		}
-	}
-	if strings.Contains(lowerAlgo, "ml-kem") || strings.Contains(lowerAlgo, "kyber") {
-		if strings.Contains(lowerAlgo, "512") {
-			pqcTypes = append(pqcTypes, "ML-KEM-512")
-		} else if strings.Contains(lowerAlgo, "768") {
-			pqcTypes = append(pqcTypes, "ML-KEM-768")
-		} else if strings.Contains(lowerAlgo, "1024") {
-			pqcTypes = append(pqcTypes, "ML-KEM-1024")
-		} else {
-			pqcTypes = append(pqcTypes, "ML-KEM")
-		}
+	if family := pqc.FamilyFromName(algoName); family != "" {
+		return []string{family}
 	}
-	return pqcTypes
+	return nil
 }
 
-func extKeyUsageToString(eku x509.ExtKeyUsage) string {
+func ExtKeyUsageString(eku x509.ExtKeyUsage) string {
 	switch eku {
 	case x509.ExtKeyUsageAny:
 		return "Any"
@@ -150,75 +145,70 @@ func extKeyUsageToString(eku x509.ExtKeyUsage) string {
 }
 
 func parseCertificateData(data []byte, filePath string) (*CertificateInfo, error) {
-	var cert *x509.Certificate
-	var encoding string
-	var certBytes []byte
-
 	if pem.IsPEM(data) {
-		certBytes, _ = pem.FindBlock(data, pem.TypeCertificate)
+		certBytes, _ := pem.FindBlock(data, pem.TypeCertificate)
 		if certBytes == nil {
 			return nil, fmt.Errorf("no certificate found in %s", filePath)
 		}
-		var err error
-		cert, err = x509.ParseCertificate(certBytes)
+		return ParseCertificateDER(certBytes, "PEM", filePath, 0)
+	}
+	return ParseCertificateDER(data, "DER", filePath, 0)
+}
+
+func parseCertificatesData(data []byte, filePath string) ([]*CertificateInfo, error) {
+	if !pem.IsPEM(data) {
+		info, err := ParseCertificateDER(data, "DER", filePath, 0)
 		if err != nil {
 			return nil, err
 		}
-		encoding = "PEM"
-	} else {
-		certBytes = data
-		var err error
-		cert, err = x509.ParseCertificate(data)
+		return []*CertificateInfo{info}, nil
+	}
+
+	blocks := pem.FindAllBlocks(data, pem.TypeCertificate)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", filePath)
+	}
+
+	certs := make([]*CertificateInfo, 0, len(blocks))
+	for i, block := range blocks {
+		info, err := ParseCertificateDER(block, "PEM", filePath, i)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
 		}
-		encoding = "DER"
+		certs = append(certs, info)
 	}
+	return certs, nil
+}
 
-	algoName := cert.SignatureAlgorithm.String()
-
-	isQuantumSafe := isPQCSignatureAlgorithmByName(algoName)
-	pqcTypes := getPQCTypesFromAlgorithmName(algoName)
+func ParseCertificateDER(certBytes []byte, encoding string, filePath string, index int) (*CertificateInfo, error) {
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, err
+	}
 
-	if cert.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
-		certStr := string(certBytes)
-		if strings.Contains(certStr, "ML-DSA-44") {
-			pqcTypes = append(pqcTypes, "ML-DSA-44")
-		}
-		if strings.Contains(certStr, "ML-DSA-65") {
-			pqcTypes = append(pqcTypes, "ML-DSA-65")
-		}
-		if strings.Contains(certStr, "ML-DSA-87") {
-			pqcTypes = append(pqcTypes, "ML-DSA-87")
-		}
-		if strings.Contains(certStr, "ML-KEM-512") || strings.Contains(certStr, "MLKEM512") {
-			pqcTypes = append(pqcTypes, "ML-KEM-512")
-		}
-		if strings.Contains(certStr, "ML-KEM-768") || strings.Contains(certStr, "MLKEM768") {
-			pqcTypes = append(pqcTypes, "ML-KEM-768")
-		}
-		if strings.Contains(certStr, "ML-KEM-1024") || strings.Contains(certStr, "MLKEM1024") {
-			pqcTypes = append(pqcTypes, "ML-KEM-1024")
-		}
-		if strings.Contains(certStr, "SLH-DSA") {
-			pqcTypes = append(pqcTypes, "SLH-DSA")
-		}
-		if strings.Contains(certStr, "FN-DSA") {
-			pqcTypes = append(pqcTypes, "FN-DSA")
+	algoName := cert.SignatureAlgorithm.String()
+	sigAlg, keyAlg := pqc.Detect(cert.Raw, cert.RawSubjectPublicKeyInfo)
+	if sigAlg != nil {
+		algoName = sigAlg.Name
+	} else if cert.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		if oid, err := pqc.SignatureAlgorithmOID(cert.Raw); err == nil {
+			algoName = oid
 		}
-		isQuantumSafe = len(pqcTypes) > 0
 	}
+	pqcTypes := pqc.Names(sigAlg, keyAlg)
 
 	var extKeyUsageStrings []string
 	for _, eku := range cert.ExtKeyUsage {
-		extKeyUsageStrings = append(extKeyUsageStrings, extKeyUsageToString(eku))
+		extKeyUsageStrings = append(extKeyUsageStrings, ExtKeyUsageString(eku))
 	}
 
 	info := &CertificateInfo{
 		Filename:           filePath,
+		Index:              index,
 		Encoding:           encoding,
 		CommonName:         cert.Subject.CommonName,
 		Issuer:             cert.Issuer.CommonName,
+		IssuerDN:           cert.Issuer.String(),
 		Subject:            cert.Subject.String(),
 		NotBefore:          cert.NotBefore,
 		NotAfter:           cert.NotAfter,
@@ -227,14 +217,26 @@ func parseCertificateData(data []byte, filePath string) (*CertificateInfo, error
 		IsCA:               cert.IsCA,
 		ExtKeyUsage:        cert.ExtKeyUsage,
 		ExtKeyUsageStrings: extKeyUsageStrings,
-		IsQuantumSafe:      isQuantumSafe,
 		PQCTypes:           pqcTypes,
 	}
-	info.KeyType, info.Bits = getKeyBitsAndType(cert.PublicKey)
-
-	if len(cert.DNSNames) > 0 {
-		info.SANs = cert.DNSNames
+	info.KeyType, info.Bits = KeyTypeAndBits(cert.PublicKey)
+	info.Strength = strength.ForPublicKey(cert.PublicKey)
+	if keyAlg != nil {
+		info.KeyType, info.Bits = keyAlg.KeyType(), keyAlg.Bits
+		info.Strength = strength.ForAlgorithm(*keyAlg)
+	}
+	if sigAlg != nil {
+		info.Strength = strength.LimitAlgorithm(info.Strength, *sigAlg)
+	} else {
+		info.Strength = strength.Limit(info.Strength, cert.SignatureAlgorithm)
 	}
+	info.SHA1Fingerprint = fingerprint.SHA1(cert.Raw)
+	info.SHA256Fingerprint = fingerprint.SHA256(cert.Raw)
+	info.SPKISHA256, info.SPKISHA256Hex = fingerprint.SPKI(cert.RawSubjectPublicKeyInfo)
+	decodeExtensions(cert, info)
+	decodeComposite(cert, info, sigAlg, keyAlg)
+
+	decodeSubjectAltNames(cert, info)
 
 	return info, nil
 }
@@ -248,6 +250,67 @@ func ParseCertificate(filePath string) (*CertificateInfo, error) {
 	return parseCertificateData(data, filePath)
 }
 
-func ParseCertificateFromBytes(data []byte) (*CertificateInfo, error) {
-	return parseCertificateData(data, "")
+func ParseCertificateFromBytes(data []byte, filename ...string) (*CertificateInfo, error) {
+	name := ""
+	if len(filename) > 0 {
+		name = filename[0]
+	}
+
+	return parseCertificateData(data, name)
+}
+
+func ParseCertificates(filePath string) ([]*CertificateInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCertificatesData(data, filePath)
+}
+
+func ParseCertificatesFromBytes(data []byte, filename ...string) ([]*CertificateInfo, error) {
+	name := ""
+	if len(filename) > 0 {
+		name = filename[0]
+	}
+
+	return parseCertificatesData(data, name)
+}
+
+// LoadX509Certificates returns the parsed crypto/x509 certificates of a PEM
+// bundle or DER file, for callers that need more than CertificateInfo.
+func LoadX509Certificates(filePath string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadX509CertificatesFromBytes(data, filePath)
+}
+
+// LoadX509CertificatesFromBytes is LoadX509Certificates for data already read;
+// filename is used in the errors.
+func LoadX509CertificatesFromBytes(data []byte, filename string) ([]*x509.Certificate, error) {
+	if !pem.IsPEM(data) {
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.Certificate{cert}, nil
+	}
+
+	blocks := pem.FindAllBlocks(data, pem.TypeCertificate)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", filename)
+	}
+
+	certs := make([]*x509.Certificate, 0, len(blocks))
+	for _, block := range blocks {
+		cert, err := x509.ParseCertificate(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
 }
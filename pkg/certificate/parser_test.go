@@ -1,9 +1,17 @@
 package certificate
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -403,7 +411,7 @@ func TestIsPQCSignatureAlgorithmByName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isPQCSignatureAlgorithmByName(tt.algo)
+			result := IsPQCAlgorithmName(tt.algo)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -416,34 +424,368 @@ func TestGetPQCTypesFromAlgorithmName(t *testing.T) {
 		expected []string
 	}{
 		{"ML-DSA-44", "ml-dsa-44", []string{"ML-DSA-44"}},
-		{"ML-DSA-65", "ml-dsa-45", []string{"ML-DSA-65"}},
+		{"ML-DSA-65", "ml-dsa-65", []string{"ML-DSA-65"}},
 		{"ML-DSA-87", "ml-dsa-87", []string{"ML-DSA-87"}},
-		{"ML-DSA-44 dilithium", "dilithium2", []string{"ML-DSA-44"}},
-		{"ML-DSA-65 dilithium", "dilithium3", []string{"ML-DSA-65"}},
-		{"ML-DSA-87 dilithium", "dilithium5", []string{"ML-DSA-87"}},
-		{"SLH-DSA-128", "slh-dsa-sha2-128s", []string{"SLH-DSA-128"}},
-		{"SLH-DSA-192", "slh-dsa-sha2-192s", []string{"SLH-DSA-192"}},
-		{"SLH-DSA-256", "slh-dsa-sha2-256s", []string{"SLH-DSA-256"}},
+		{"Unknown ML-DSA parameter set", "ml-dsa-45", []string{"ML-DSA"}},
+		{"Dilithium2", "dilithium2", []string{"Dilithium2"}},
+		{"Dilithium3", "dilithium3", []string{"Dilithium3"}},
+		{"Dilithium5", "dilithium5", []string{"Dilithium5"}},
+		{"SLH-DSA-SHA2-128S", "slh-dsa-sha2-128s", []string{"SLH-DSA-SHA2-128S"}},
+		{"SLH-DSA-SHA2-192S", "slh-dsa-sha2-192s", []string{"SLH-DSA-SHA2-192S"}},
+		{"SLH-DSA-SHAKE-256F", "slh-dsa-shake-256f", []string{"SLH-DSA-SHAKE-256F"}},
 		{"SLH-DSA generic", "slh-dsa", []string{"SLH-DSA"}},
-		{"FN-DSA-128", "fn-dsa-128", []string{"FN-DSA-128"}},
-		{"FN-DSA-192", "fn-dsa-192", []string{"FN-DSA-192"}},
-		{"FN-DSA-256", "fn-dsa-256", []string{"FN-DSA-256"}},
+		{"FN-DSA-512", "fn-dsa-512", []string{"FN-DSA-512"}},
+		{"FN-DSA-1024", "fn-dsa-1024", []string{"FN-DSA-1024"}},
 		{"FN-DSA generic", "fn-dsa", []string{"FN-DSA"}},
-		{"FALCON-512", "falcon-512", []string{"FN-DSA-128"}},
-		{"FALCON-1024", "falcon-1024", []string{"FN-DSA-256"}},
+		{"FALCON-512", "falcon-512", []string{"FN-DSA-512"}},
+		{"FALCON-1024", "falcon-1024", []string{"FN-DSA-1024"}},
 		{"ML-KEM-512", "ml-kem-512", []string{"ML-KEM-512"}},
 		{"ML-KEM-768", "ml-kem-768", []string{"ML-KEM-768"}},
 		{"ML-KEM-1024", "ml-kem-1024", []string{"ML-KEM-1024"}},
 		{"ML-KEM generic", "ml-kem", []string{"ML-KEM"}},
-		{"Kyber", "kyber512", []string{"ML-KEM-512"}},
+		{"Kyber", "kyber512", []string{"Kyber512"}},
+		{"Composite", "MLDSA65-ECDSA-P384-SHA512", []string{"MLDSA65-ECDSA-P384-SHA512"}},
 		{"RSA", "rsaEncryption", nil},
 		{"ECDSA", "ecdsa-with-sha256", nil},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := getPQCTypesFromAlgorithmName(tt.algoName)
+			result := PQCTypesFromAlgorithmName(tt.algoName)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
+
+type testValidity struct {
+	NotBefore, NotAfter time.Time
+}
+
+type testSPKI struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type testTBSCertificate struct {
+	Version      int `asn1:"explicit,tag:0"`
+	SerialNumber *big.Int
+	Signature    pkix.AlgorithmIdentifier
+	Issuer       asn1.RawValue
+	Validity     testValidity
+	Subject      asn1.RawValue
+	PublicKey    testSPKI
+	Extensions   []pkix.Extension `asn1:"optional,explicit,tag:3"`
+}
+
+// buildCertificateDER assembles a certificate with arbitrary signature and
+// public key algorithm OIDs, which crypto/x509 cannot create. The signature
+// is not valid.
+func buildCertificateDER(t *testing.T, cn string, sigOID, keyOID asn1.ObjectIdentifier, publicKey, signature []byte, extensions ...pkix.Extension) []byte {
+	t.Helper()
+	return buildSignedCertificateDER(t, cn, sigOID, keyOID, publicKey, func([]byte) []byte { return signature }, extensions...)
+}
+
+// buildSignedCertificateDER is buildCertificateDER with the signature
+// computed over the encoded TBSCertificate.
+func buildSignedCertificateDER(t *testing.T, cn string, sigOID, keyOID asn1.ObjectIdentifier, publicKey []byte, sign func(tbs []byte) []byte, extensions ...pkix.Extension) []byte {
+	t.Helper()
+	name, err := asn1.Marshal(pkix.Name{CommonName: cn}.ToRDNSequence())
+	require.NoError(t, err)
+
+	sigAlg := pkix.AlgorithmIdentifier{Algorithm: sigOID}
+	tbs := testTBSCertificate{
+		Version:      2,
+		SerialNumber: big.NewInt(42),
+		Signature:    sigAlg,
+		Issuer:       asn1.RawValue{FullBytes: name},
+		Validity:     testValidity{time.Now().Add(-time.Hour).UTC().Truncate(time.Second), time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)},
+		Subject:      asn1.RawValue{FullBytes: name},
+		PublicKey: testSPKI{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: keyOID},
+			PublicKey: asn1.BitString{Bytes: publicKey, BitLength: 8 * len(publicKey)},
+		},
+		Extensions: extensions,
+	}
+	tbsDER, err := asn1.Marshal(tbs)
+	require.NoError(t, err)
+	signature := sign(tbsDER)
+
+	der, err := asn1.Marshal(struct {
+		TBS       asn1.RawValue
+		Algorithm pkix.AlgorithmIdentifier
+		Signature asn1.BitString
+	}{asn1.RawValue{FullBytes: tbsDER}, sigAlg, asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)}})
+	require.NoError(t, err)
+	return der
+}
+
+func TestParseCertificatePQCOIDs(t *testing.T) {
+	mldsa44 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}
+	mldsa65 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	slhdsa := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 21}
+	mlkem768 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 2}
+	composite := asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 6, 45}
+
+	tests := []struct {
+		name      string
+		sigOID    asn1.ObjectIdentifier
+		keyOID    asn1.ObjectIdentifier
+		keySize   int
+		algorithm string
+		keyType   string
+		bits      int
+		pqcTypes  []string
+	}{
+		{"ML-DSA-44", mldsa44, mldsa44, 1312, "ML-DSA-44", "ML-DSA", 44, []string{"ML-DSA-44"}},
+		{"ML-DSA-65 signed ML-KEM", mldsa65, mlkem768, 1184, "ML-DSA-65", "ML-KEM", 768, []string{"ML-DSA-65", "ML-KEM-768"}},
+		{"SLH-DSA", slhdsa, slhdsa, 32, "SLH-DSA-SHA2-128F", "SLH-DSA", 128, []string{"SLH-DSA-SHA2-128F"}},
+		{"Composite", composite, composite, 2000, "MLDSA65-ECDSA-P256-SHA512", "Composite ML-DSA", 65, []string{"MLDSA65-ECDSA-P256-SHA512"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der := buildCertificateDER(t, tt.name, tt.sigOID, tt.keyOID, make([]byte, tt.keySize), make([]byte, 64))
+			cert, err := ParseCertificateFromBytes(der)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.name, cert.CommonName)
+			assert.Equal(t, tt.algorithm, cert.Algorithm)
+			assert.Equal(t, tt.keyType, cert.KeyType)
+			assert.Equal(t, tt.bits, cert.Bits)
+			assert.True(t, cert.IsQuantumSafe)
+			assert.Equal(t, tt.pqcTypes, cert.PQCTypes)
+		})
+	}
+}
+
+func TestParseCertificateUnknownOIDAlgorithm(t *testing.T) {
+	unknown := asn1.ObjectIdentifier{1, 2, 3, 4, 5}
+	der := buildCertificateDER(t, "unknown", unknown, unknown, make([]byte, 32), make([]byte, 64))
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4.5", cert.Algorithm)
+	assert.False(t, cert.IsQuantumSafe)
+	assert.Empty(t, cert.PQCTypes)
+}
+
+func TestParseCertificatePQCNameInSubject(t *testing.T) {
+	// A PQC name in the subject used to be enough for the substring scan.
+	unknown := asn1.ObjectIdentifier{1, 2, 3, 4, 5}
+	der := buildCertificateDER(t, "Test ML-DSA-44 CA", unknown, unknown, make([]byte, 32), make([]byte, 64))
+	cert, err := ParseCertificateFromBytes(der)
+	require.NoError(t, err)
+	assert.False(t, cert.IsQuantumSafe)
+	assert.Empty(t, cert.PQCTypes)
+}
+
+func TestParseCertificatesBundle(t *testing.T) {
+	certs, err := ParseCertificates(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err, "failed to parse certificate bundle")
+	require.Len(t, certs, 2)
+
+	assert.Equal(t, 0, certs[0].Index)
+	assert.Equal(t, "localhost", certs[0].CommonName)
+	assert.Equal(t, 1, certs[1].Index)
+	assert.Equal(t, "Test Intermediate CA", certs[1].CommonName)
+
+	cert, err := ParseCertificate(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cert.CommonName, "ParseCertificate should return the first certificate")
+}
+
+func TestParseCertificatesSingle(t *testing.T) {
+	certs, err := ParseCertificates(getTestCertPath("traditional/rsa/server-rsa2048.crt"))
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	assert.Equal(t, 0, certs[0].Index)
+
+	data, err := os.ReadFile(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err)
+	certs, err = ParseCertificatesFromBytes(data)
+	require.NoError(t, err)
+	assert.Len(t, certs, 2)
+}
+
+func TestParseCertificateExtensions(t *testing.T) {
+	cert, err := ParseCertificate(getTestCertPath("extensions/extensions.crt"))
+	require.NoError(t, err, "failed to parse certificate with extensions")
+
+	assert.Equal(t, []string{"Digital Signature", "Certificate Sign", "CRL Sign"}, cert.KeyUsage)
+	require.NotNil(t, cert.BasicConstraints)
+	assert.True(t, cert.BasicConstraints.IsCA)
+	require.NotNil(t, cert.BasicConstraints.PathLen)
+	assert.Equal(t, 1, *cert.BasicConstraints.PathLen)
+	assert.NotEmpty(t, cert.SubjectKeyID)
+	assert.Equal(t, []string{"http://crl.test.local/ca.crl"}, cert.CRLDistributionPoints)
+	assert.Equal(t, []string{"http://ocsp.test.local"}, cert.OCSPServers)
+	assert.Equal(t, []string{"http://ca.test.local/ca.crt"}, cert.IssuingCertificateURLs)
+	assert.Equal(t, []string{"2.23.140.1.2.1 (Domain Validated)", "1.3.6.1.4.1.99999.1"}, cert.Policies)
+
+	require.NotNil(t, cert.NameConstraints)
+	assert.True(t, cert.NameConstraints.Critical)
+	assert.Equal(t, []string{".test.local"}, cert.NameConstraints.PermittedDNS)
+	assert.Equal(t, []string{"10.0.0.0/8"}, cert.NameConstraints.PermittedIPs)
+	assert.Equal(t, []string{"test.local"}, cert.NameConstraints.ExcludedEmails)
+
+	assert.True(t, cert.MustStaple)
+	assert.Equal(t, []ExtensionInfo{{OID: "1.3.6.1.4.1.99999.2", Critical: false}}, cert.OtherExtensions)
+}
+
+func TestParseCertificateChainExtensions(t *testing.T) {
+	intermediate, err := ParseCertificate(getTestCertPath("chain/intermediate-ca.crt"))
+	require.NoError(t, err)
+	server, err := ParseCertificate(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+
+	require.NotNil(t, intermediate.BasicConstraints)
+	assert.True(t, intermediate.BasicConstraints.IsCA)
+	assert.Nil(t, intermediate.BasicConstraints.PathLen)
+	assert.Equal(t, intermediate.SubjectKeyID, server.AuthorityKeyID)
+
+	require.NotNil(t, server.BasicConstraints)
+	assert.False(t, server.BasicConstraints.IsCA)
+	assert.Equal(t, []string{"Digital Signature", "Key Encipherment"}, server.KeyUsage)
+	assert.Nil(t, server.NameConstraints)
+	assert.False(t, server.MustStaple)
+	assert.Empty(t, server.OtherExtensions)
+}
+
+func TestKeyUsageToStrings(t *testing.T) {
+	assert.Empty(t, KeyUsageStrings(0))
+	assert.Equal(t, []string{"Key Agreement", "Decipher Only"},
+		KeyUsageStrings(x509.KeyUsageKeyAgreement|x509.KeyUsageDecipherOnly))
+}
+
+func TestFormatKeyID(t *testing.T) {
+	assert.Equal(t, "", formatKeyID(nil))
+	assert.Equal(t, "0A:FF:10", formatKeyID([]byte{0x0a, 0xff, 0x10}))
+}
+
+func TestParseCertificateTypedSANs(t *testing.T) {
+	cert, err := ParseCertificate(getTestCertPath("san-types/san-mixed.crt"))
+	require.NoError(t, err, "failed to parse certificate with mixed SANs")
+
+	assert.Equal(t, []string{"web.test.local", "*.apps.test.local"}, cert.SANs)
+	assert.Equal(t, []string{"10.1.2.3"}, cert.IPSANs)
+	assert.Equal(t, []string{"admin@test.local"}, cert.EmailSANs)
+	assert.Equal(t, []string{"spiffe://test.local/ns/default/sa/web"}, cert.URISANs)
+	assert.Equal(t, []OtherName{{Type: "UPN", Value: "user@test.local"}}, cert.OtherNameSANs)
+
+	cert, err = ParseCertificate(getTestCertPath("san-types/san-rsa.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1", "::1"}, cert.IPSANs)
+}
+
+func TestCoversName(t *testing.T) {
+	cert, err := ParseCertificate(getTestCertPath("san-types/san-mixed.crt"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"web.test.local", true},
+		{"WEB.test.local", true},
+		{"api.apps.test.local", true},
+		{"apps.test.local", false},
+		{"a.b.apps.test.local", false},
+		{"10.1.2.3", true},
+		{"10.1.2.4", false},
+		{"admin@test.local", true},
+		{"spiffe://test.local/ns/default/sa/web", true},
+		{"user@test.local", true},
+		{"other.test.local", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cert.CoversName(tt.name))
+		})
+	}
+}
+
+func TestParseCertificateFingerprints(t *testing.T) {
+	cert, err := ParseCertificate(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+
+	assert.Len(t, cert.SHA1Fingerprint, 59)
+	assert.Len(t, cert.SHA256Fingerprint, 95)
+	assert.Len(t, cert.SPKISHA256, 44)
+	assert.Len(t, cert.SPKISHA256Hex, 64)
+
+	bundle, err := ParseCertificates(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err)
+	require.Len(t, bundle, 2)
+	assert.Equal(t, cert.SHA256Fingerprint, bundle[0].SHA256Fingerprint)
+	assert.NotEqual(t, bundle[0].SHA256Fingerprint, bundle[1].SHA256Fingerprint)
+}
+
+func TestParseCertificateStrength(t *testing.T) {
+	tests := []struct {
+		path      string
+		bits      int
+		category  int
+		limitedBy string
+	}{
+		{"traditional/rsa/server-rsa2048.crt", 112, 0, ""},
+		{"traditional/rsa/server-rsa4096.crt", 128, 0, ""},
+		{"traditional/ecdsa/server-ecdsa-p256.crt", 128, 0, ""},
+		{"traditional/ecdsa/ca-ecdsa-p384.crt", 128, 0, "SHA-256 signature"},
+		{"traditional/ecdsa/server-ed25519.crt", 128, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			info, err := ParseCertificate(getTestCertPath(tt.path))
+			require.NoError(t, err)
+			assert.Equal(t, tt.bits, info.Strength.Bits)
+			assert.Equal(t, tt.category, info.Strength.Category)
+			assert.Equal(t, tt.limitedBy, info.Strength.LimitedBy)
+		})
+	}
+}
+
+func TestParseCertificateStrengthSHA1(t *testing.T) {
+	data, err := os.ReadFile(getTestCertPath("chain/root-ca.key"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	rsaKey := key.(*rsa.PrivateKey)
+	require.Equal(t, 4096, rsaKey.N.BitLen())
+
+	template := &x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "sha1"},
+		NotBefore:          time.Now().Add(-time.Hour),
+		NotAfter:           time.Now().Add(time.Hour),
+		SignatureAlgorithm: x509.SHA1WithRSA,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &rsaKey.PublicKey, rsaKey)
+	require.NoError(t, err)
+
+	info, err := ParseCertificateDER(der, "DER", "sha1.der", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4096, info.Bits)
+	assert.Equal(t, 63, info.Strength.Bits)
+	assert.Equal(t, "SHA-1 signature", info.Strength.LimitedBy)
+	assert.True(t, info.Strength.IsWeak())
+}
+
+func TestParseCertificateStrengthPQC(t *testing.T) {
+	mldsa65 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	mldsa44 := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}
+
+	der := buildCertificateDER(t, "mldsa", mldsa65, mldsa65, make([]byte, 1952), make([]byte, 3309))
+	info, err := ParseCertificateDER(der, "DER", "mldsa.der", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 192, info.Strength.Bits)
+	assert.Equal(t, 3, info.Strength.Category)
+
+	der = buildCertificateDER(t, "mldsa", mldsa44, mldsa65, make([]byte, 1952), make([]byte, 2420))
+	info, err = ParseCertificateDER(der, "DER", "mldsa.der", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 128, info.Strength.Bits)
+	assert.Equal(t, "ML-DSA-44 signature", info.Strength.LimitedBy)
+}
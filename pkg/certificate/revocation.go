@@ -0,0 +1,23 @@
+package certificate
+
+import "time"
+
+const (
+	RevocationGood    = "good"
+	RevocationRevoked = "revoked"
+	RevocationUnknown = "unknown"
+)
+
+type Revocation struct {
+	Status         string
+	RevocationTime time.Time
+	Reason         string
+	Source         string
+	// Verified is set when the signatures of the CRLs that gave the status
+	// were verified with their issuer.
+	Verified bool
+}
+
+func (r *Revocation) IsRevoked() bool {
+	return r != nil && r.Status == RevocationRevoked
+}
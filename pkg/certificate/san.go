@@ -0,0 +1,146 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+var otherNameTypes = map[string]string{
+	"1.3.6.1.4.1.311.20.2.3": "UPN",
+	"1.3.6.1.5.5.7.8.5":      "XmppAddr",
+	"1.3.6.1.5.5.7.8.7":      "SRVName",
+	"1.3.6.1.5.5.7.8.9":      "SmtpUTF8Mailbox",
+}
+
+type OtherName struct {
+	Type  string
+	Value string
+}
+
+type otherNameValue struct {
+	TypeID asn1.ObjectIdentifier
+	Value  asn1.RawValue
+}
+
+// parseOtherNames extracts the otherName entries of the SAN extension, which
+// crypto/x509 does not expose.
+func parseOtherNames(cert *x509.Certificate) []OtherName {
+	var otherNames []OtherName
+
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() != oidExtSubjectAltName {
+			continue
+		}
+
+		var seq asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &seq); err != nil || !seq.IsCompound {
+			return nil
+		}
+
+		rest := seq.Bytes
+		for len(rest) > 0 {
+			var name asn1.RawValue
+			var err error
+			rest, err = asn1.Unmarshal(rest, &name)
+			if err != nil {
+				return otherNames
+			}
+			if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+				continue
+			}
+
+			var on otherNameValue
+			if _, err := asn1.UnmarshalWithParams(name.FullBytes, &on, "tag:0"); err != nil {
+				continue
+			}
+
+			typeName := on.TypeID.String()
+			if known, ok := otherNameTypes[typeName]; ok {
+				typeName = known
+			}
+			otherNames = append(otherNames, OtherName{Type: typeName, Value: otherNameToString(on.Value)})
+		}
+	}
+
+	return otherNames
+}
+
+func otherNameToString(wrapped asn1.RawValue) string {
+	var value asn1.RawValue
+	if _, err := asn1.Unmarshal(wrapped.Bytes, &value); err != nil {
+		return hex.EncodeToString(wrapped.Bytes)
+	}
+	switch value.Tag {
+	case asn1.TagUTF8String, asn1.TagIA5String, asn1.TagPrintableString:
+		return string(value.Bytes)
+	default:
+		return hex.EncodeToString(value.Bytes)
+	}
+}
+
+func decodeSubjectAltNames(cert *x509.Certificate, info *CertificateInfo) {
+	if len(cert.DNSNames) > 0 {
+		info.SANs = cert.DNSNames
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPSANs = append(info.IPSANs, ip.String())
+	}
+	info.EmailSANs = cert.EmailAddresses
+	for _, uri := range cert.URIs {
+		info.URISANs = append(info.URISANs, uri.String())
+	}
+	info.OtherNameSANs = parseOtherNames(cert)
+}
+
+func matchDNSName(pattern, name string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	if pattern == name {
+		return true
+	}
+
+	wildcardDomain, ok := strings.CutPrefix(pattern, "*.")
+	if !ok {
+		return false
+	}
+	_, domain, found := strings.Cut(name, ".")
+	return found && domain == wildcardDomain
+}
+
+// CoversName reports whether any SAN of the certificate matches name. DNS
+// SANs honour single-label wildcards; IP addresses are compared by value.
+func (c *CertificateInfo) CoversName(name string) bool {
+	if ip := net.ParseIP(name); ip != nil {
+		for _, san := range c.IPSANs {
+			if ip.Equal(net.ParseIP(san)) {
+				return true
+			}
+		}
+	}
+
+	for _, san := range c.SANs {
+		if matchDNSName(san, name) {
+			return true
+		}
+	}
+	for _, san := range c.EmailSANs {
+		if strings.EqualFold(san, name) {
+			return true
+		}
+	}
+	for _, san := range c.URISANs {
+		if san == name {
+			return true
+		}
+	}
+	for _, on := range c.OtherNameSANs {
+		if strings.EqualFold(on.Value, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+
+	"github.com/marco-introini/certinfo/pkg/pqc"
+)
+
+const (
+	SignatureValid       = "valid"
+	SignatureInvalid     = "invalid"
+	SignatureUnsupported = "unsupported"
+	// SignatureNoIssuer is the status of certificates whose issuer is not
+	// among the certificates supplied to verify them.
+	SignatureNoIssuer = "issuer not supplied"
+)
+
+// SignatureCheck is the result of verifying a certificate's signature with
+// an issuer's public key.
+type SignatureCheck struct {
+	Status string
+	Issuer string
+	Error  string
+}
+
+func (s *SignatureCheck) IsValid() bool {
+	return s != nil && s.Status == SignatureValid
+}
+
+// FindIssuers returns the candidates whose subject is cert's issuer, the
+// ones whose subject key identifier is cert's authority key identifier
+// first, so that the key that signed cert is tried first when a CA has
+// rolled over its key.
+func FindIssuers(cert *x509.Certificate, candidates []*x509.Certificate) []*x509.Certificate {
+	var matching, others []*x509.Certificate
+	for _, c := range candidates {
+		switch {
+		case !bytes.Equal(c.RawSubject, cert.RawIssuer):
+		case len(cert.AuthorityKeyId) > 0 && bytes.Equal(c.SubjectKeyId, cert.AuthorityKeyId):
+			matching = append(matching, c)
+		default:
+			others = append(others, c)
+		}
+	}
+	return append(matching, others...)
+}
+
+// FindIssuer returns the candidate most likely to have signed cert, or nil
+// when no candidate's subject is cert's issuer.
+func FindIssuer(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	if issuers := FindIssuers(cert, candidates); len(issuers) > 0 {
+		return issuers[0]
+	}
+	return nil
+}
+
+// VerifySignatureWith checks cert against each candidate that may have
+// issued it, and returns the first valid result, or the result of the
+// likeliest issuer when none is valid.
+func VerifySignatureWith(cert *x509.Certificate, candidates []*x509.Certificate) *SignatureCheck {
+	var first *SignatureCheck
+	for _, issuer := range FindIssuers(cert, candidates) {
+		check := VerifySignature(cert, issuer)
+		if check.IsValid() {
+			return check
+		}
+		if first == nil {
+			first = check
+		}
+	}
+	if first == nil {
+		return VerifySignature(cert, nil)
+	}
+	return first
+}
+
+// VerifySignature checks that issuer's key signed cert. ML-DSA, SLH-DSA and
+// composite signatures are verified by the pqc package, everything else by
+// crypto/x509. A nil issuer gives the SignatureNoIssuer status.
+func VerifySignature(cert, issuer *x509.Certificate) *SignatureCheck {
+	if issuer == nil {
+		return &SignatureCheck{
+			Status: SignatureNoIssuer,
+			Issuer: cert.Issuer.String(),
+			Error:  "issuer " + cert.Issuer.String() + " not supplied",
+		}
+	}
+	check := &SignatureCheck{Issuer: issuer.Subject.String()}
+
+	var err error
+	oid, oidErr := pqc.SignatureAlgorithmOID(cert.Raw)
+	if _, ok := pqc.ByOID(oid); oidErr == nil && ok {
+		err = pqc.Verify(oid, issuer.RawSubjectPublicKeyInfo, cert.RawTBSCertificate, cert.Signature)
+	} else {
+		err = issuer.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+	}
+
+	switch {
+	case err == nil:
+		check.Status = SignatureValid
+	case errors.Is(err, pqc.ErrUnsupportedAlgorithm), errors.Is(err, x509.ErrUnsupportedAlgorithm):
+		check.Status = SignatureUnsupported
+		check.Error = err.Error()
+	default:
+		check.Status = SignatureInvalid
+		check.Error = err.Error()
+	}
+	return check
+}
@@ -0,0 +1,162 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/slhdsa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadX509(t *testing.T, relPath string) *x509.Certificate {
+	certs, err := LoadX509Certificates(getTestCertPath(relPath))
+	require.NoError(t, err)
+	return certs[0]
+}
+
+// pqcCertificate builds a certificate for a new key of scheme s, signed by
+// signer (or self-signed when signer is nil).
+func pqcCertificate(t *testing.T, cn string, oid asn1.ObjectIdentifier, s sign.Scheme, signer sign.PrivateKey) (*x509.Certificate, sign.PrivateKey) {
+	pub, priv, err := s.GenerateKey()
+	require.NoError(t, err)
+	key, err := pub.MarshalBinary()
+	require.NoError(t, err)
+	if signer == nil {
+		signer = priv
+	}
+
+	der := buildSignedCertificateDER(t, cn, oid, oid, key, func(tbs []byte) []byte {
+		return s.Sign(signer, tbs, nil)
+	})
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, priv
+}
+
+func TestVerifySignaturePQC(t *testing.T) {
+	tests := []struct {
+		name   string
+		oid    asn1.ObjectIdentifier
+		scheme sign.Scheme
+	}{
+		{"ML-DSA-65", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}, mldsa65.Scheme()},
+		{"SLH-DSA-SHA2-128F", asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 21}, slhdsa.SHA2_128f.Scheme()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, rootKey := pqcCertificate(t, "PQC Root", tt.oid, tt.scheme, nil)
+			leaf, _ := pqcCertificate(t, "PQC Leaf", tt.oid, tt.scheme, rootKey)
+			other, _ := pqcCertificate(t, "Other Root", tt.oid, tt.scheme, nil)
+
+			check := VerifySignature(root, root)
+			assert.Equal(t, SignatureValid, check.Status, check.Error)
+			assert.Equal(t, "CN=PQC Root", check.Issuer)
+			assert.True(t, check.IsValid())
+
+			check = VerifySignature(leaf, root)
+			assert.Equal(t, SignatureValid, check.Status, check.Error)
+
+			check = VerifySignature(leaf, other)
+			assert.Equal(t, SignatureInvalid, check.Status)
+			assert.Contains(t, check.Error, "invalid signature")
+			assert.False(t, check.IsValid())
+		})
+	}
+}
+
+func TestVerifySignatureKeyMismatch(t *testing.T) {
+	mldsaOID := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	slhdsaOID := asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 21}
+	leaf, _ := pqcCertificate(t, "leaf", mldsaOID, mldsa65.Scheme(), nil)
+	issuer, _ := pqcCertificate(t, "issuer", slhdsaOID, slhdsa.SHA2_128f.Scheme(), nil)
+
+	check := VerifySignature(leaf, issuer)
+	assert.Equal(t, SignatureInvalid, check.Status)
+	assert.Contains(t, check.Error, "SLH-DSA-SHA2-128F key cannot verify a ML-DSA-65 signature")
+}
+
+func TestVerifySignatureClassical(t *testing.T) {
+	server := loadX509(t, "chain/server.crt")
+	intermediate := loadX509(t, "chain/intermediate-ca.crt")
+	root := loadX509(t, "chain/root-ca.crt")
+
+	check := VerifySignature(server, intermediate)
+	assert.Equal(t, SignatureValid, check.Status, check.Error)
+
+	check = VerifySignature(server, root)
+	assert.Equal(t, SignatureInvalid, check.Status)
+	assert.NotEmpty(t, check.Error)
+}
+
+func TestVerifySignatureUnsupported(t *testing.T) {
+	falcon := asn1.ObjectIdentifier{1, 3, 9999, 3, 11}
+	der := buildCertificateDER(t, "falcon", falcon, falcon, make([]byte, 897), make([]byte, 666))
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	check := VerifySignature(cert, cert)
+	assert.Equal(t, SignatureUnsupported, check.Status)
+	assert.Contains(t, check.Error, "not supported")
+}
+
+func TestFindIssuer(t *testing.T) {
+	server := loadX509(t, "chain/server.crt")
+	intermediate := loadX509(t, "chain/intermediate-ca.crt")
+	root := loadX509(t, "chain/root-ca.crt")
+
+	assert.Equal(t, intermediate, FindIssuer(server, []*x509.Certificate{root, intermediate}))
+	assert.Nil(t, FindIssuer(server, []*x509.Certificate{root}), "a certificate of another subject is not the issuer")
+	assert.Nil(t, FindIssuer(server, nil))
+
+	check := VerifySignatureWith(server, []*x509.Certificate{root})
+	assert.Equal(t, SignatureNoIssuer, check.Status)
+	assert.Contains(t, check.Error, "Test Intermediate CA")
+}
+
+// rolledOver returns a certificate with the subject of ca but a new key, as
+// after a key rollover, with the given subject key identifier.
+func rolledOver(t *testing.T, ca *x509.Certificate, ski []byte) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(99),
+		RawSubject:            ca.RawSubject,
+		SubjectKeyId:          ski,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestVerifySignatureWithRollover(t *testing.T) {
+	server := loadX509(t, "chain/server.crt")
+	intermediate := loadX509(t, "chain/intermediate-ca.crt")
+	require.NotEmpty(t, server.AuthorityKeyId)
+
+	newKey := rolledOver(t, intermediate, []byte{1, 2, 3})
+	assert.Equal(t, []*x509.Certificate{intermediate, newKey}, FindIssuers(server, []*x509.Certificate{newKey, intermediate}),
+		"the issuer whose key identifier matches comes first")
+
+	sameID := rolledOver(t, intermediate, server.AuthorityKeyId)
+	check := VerifySignatureWith(server, []*x509.Certificate{sameID, intermediate})
+	assert.Equal(t, SignatureValid, check.Status, "every issuer of the right subject is tried")
+
+	check = VerifySignatureWith(server, []*x509.Certificate{sameID})
+	assert.Equal(t, SignatureInvalid, check.Status)
+}
@@ -0,0 +1,429 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+)
+
+const maxPathDepth = 10
+
+type ProblemKind string
+
+const (
+	ProblemExpired        ProblemKind = "expired"
+	ProblemNotYetValid    ProblemKind = "not yet valid"
+	ProblemNameMismatch   ProblemKind = "name mismatch"
+	ProblemWrongEKU       ProblemKind = "wrong EKU"
+	ProblemMissingIssuer  ProblemKind = "missing issuer"
+	ProblemUntrustedRoot  ProblemKind = "untrusted root"
+	ProblemBadSignature   ProblemKind = "bad signature"
+	ProblemNotCA          ProblemKind = "not a CA"
+	ProblemPathLenTooLong ProblemKind = "path length exceeded"
+)
+
+const (
+	SourceLeaf         = "leaf"
+	SourceIntermediate = "intermediate"
+	SourceRoot         = "root"
+	SourceSystemRoot   = "system root"
+)
+
+type Problem struct {
+	Kind   ProblemKind
+	Detail string
+}
+
+type Hop struct {
+	CommonName string
+	Subject    string
+	Issuer     string
+	NotBefore  time.Time
+	NotAfter   time.Time
+	Source     string
+	Problems   []Problem
+}
+
+type Path struct {
+	Valid    bool
+	Trusted  bool
+	Hops     []Hop
+	Problems []Problem
+}
+
+type VerifyResult struct {
+	Filename   string
+	CommonName string
+	VerifiedAt time.Time
+	DNSName    string
+	KeyUsages  []string
+	Valid      bool
+	Paths      []Path
+}
+
+type Options struct {
+	Intermediates []string
+	Roots         []string
+	SystemRoots   bool
+	DNSName       string
+	KeyUsages     []string
+	At            time.Time
+}
+
+var extKeyUsageNames = map[string]x509.ExtKeyUsage{
+	"any":             x509.ExtKeyUsageAny,
+	"serverauth":      x509.ExtKeyUsageServerAuth,
+	"clientauth":      x509.ExtKeyUsageClientAuth,
+	"codesigning":     x509.ExtKeyUsageCodeSigning,
+	"emailprotection": x509.ExtKeyUsageEmailProtection,
+	"timestamping":    x509.ExtKeyUsageTimeStamping,
+	"ocspsigning":     x509.ExtKeyUsageOCSPSigning,
+}
+
+func parseExtKeyUsage(name string) (x509.ExtKeyUsage, error) {
+	eku, ok := extKeyUsageNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown extended key usage: %s", name)
+	}
+	return eku, nil
+}
+
+type candidate struct {
+	cert   *x509.Certificate
+	source string
+}
+
+type builder struct {
+	intermediates []*x509.Certificate
+	roots         []*x509.Certificate
+	systemRoots   *x509.CertPool
+	at            time.Time
+	paths         [][]candidate
+	terminal      []*Problem
+}
+
+func loadCertificateFiles(paths []string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, p := range paths {
+		loaded, err := certificate.LoadX509Certificates(p)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, loaded...)
+	}
+	return certs, nil
+}
+
+func containsCert(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+func inPath(path []candidate, cert *x509.Certificate) bool {
+	for _, c := range path {
+		if c.cert.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil
+}
+
+func couldHaveIssued(parent, child *x509.Certificate) bool {
+	if !bytes.Equal(parent.RawSubject, child.RawIssuer) {
+		return false
+	}
+	if len(parent.SubjectKeyId) > 0 && len(child.AuthorityKeyId) > 0 {
+		return bytes.Equal(parent.SubjectKeyId, child.AuthorityKeyId)
+	}
+	return true
+}
+
+// clampTime returns t if it falls within the validity of cert, otherwise the
+// nearest bound, so that system pool lookups are not rejected on expiry alone.
+func clampTime(t time.Time, cert *x509.Certificate) time.Time {
+	if t.Before(cert.NotBefore) {
+		return cert.NotBefore
+	}
+	if t.After(cert.NotAfter) {
+		return cert.NotAfter
+	}
+	return t
+}
+
+// systemIssuer looks cert up in the system pool. It reports whether cert is
+// itself a system root and, if not, the system root that issued it.
+func (b *builder) systemIssuer(cert *x509.Certificate) (*x509.Certificate, bool) {
+	if b.systemRoots == nil {
+		return nil, false
+	}
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:       b.systemRoots,
+		CurrentTime: clampTime(b.at, cert),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil || len(chains) == 0 {
+		return nil, false
+	}
+	for _, chain := range chains {
+		if len(chain) == 1 {
+			return nil, true
+		}
+	}
+	return chains[0][len(chains[0])-1], false
+}
+
+func (b *builder) extend(path []candidate) {
+	current := path[len(path)-1]
+
+	if current.source == SourceRoot || current.source == SourceSystemRoot {
+		b.finish(path, nil)
+		return
+	}
+
+	if len(path) >= maxPathDepth {
+		b.finish(path, &Problem{Kind: ProblemMissingIssuer, Detail: "maximum path depth reached"})
+		return
+	}
+
+	found := false
+	for _, root := range b.roots {
+		if !inPath(path, root) && couldHaveIssued(root, current.cert) {
+			found = true
+			b.extend(append(clonePath(path), candidate{cert: root, source: SourceRoot}))
+		}
+	}
+	for _, inter := range b.intermediates {
+		if !inPath(path, inter) && couldHaveIssued(inter, current.cert) {
+			found = true
+			b.extend(append(clonePath(path), candidate{cert: inter, source: SourceIntermediate}))
+		}
+	}
+	if found {
+		return
+	}
+
+	issuer, isRoot := b.systemIssuer(current.cert)
+	if isRoot {
+		path[len(path)-1].source = SourceSystemRoot
+		b.finish(path, nil)
+		return
+	}
+	if issuer != nil {
+		b.finish(append(clonePath(path), candidate{cert: issuer, source: SourceSystemRoot}), nil)
+		return
+	}
+
+	if isSelfSigned(current.cert) {
+		b.finish(path, &Problem{
+			Kind:   ProblemUntrustedRoot,
+			Detail: fmt.Sprintf("self-signed certificate %q is not a trusted root", current.cert.Subject.CommonName),
+		})
+		return
+	}
+
+	b.finish(path, &Problem{
+		Kind:   ProblemMissingIssuer,
+		Detail: fmt.Sprintf("no issuer found for %q (issued by %q)", current.cert.Subject.CommonName, current.cert.Issuer.String()),
+	})
+}
+
+func (b *builder) finish(path []candidate, problem *Problem) {
+	b.paths = append(b.paths, path)
+	b.terminal = append(b.terminal, problem)
+}
+
+func clonePath(path []candidate) []candidate {
+	return append([]candidate(nil), path...)
+}
+
+func checkValidity(cert *x509.Certificate, at time.Time) *Problem {
+	if at.Before(cert.NotBefore) {
+		return &Problem{
+			Kind:   ProblemNotYetValid,
+			Detail: fmt.Sprintf("valid from %s", cert.NotBefore.UTC().Format(time.RFC3339)),
+		}
+	}
+	if at.After(cert.NotAfter) {
+		return &Problem{
+			Kind:   ProblemExpired,
+			Detail: fmt.Sprintf("expired at %s", cert.NotAfter.UTC().Format(time.RFC3339)),
+		}
+	}
+	return nil
+}
+
+func checkExtKeyUsage(cert *x509.Certificate, usages []x509.ExtKeyUsage) *Problem {
+	if len(usages) == 0 || len(cert.ExtKeyUsage) == 0 && len(cert.UnknownExtKeyUsage) == 0 {
+		return nil
+	}
+	for _, have := range cert.ExtKeyUsage {
+		if have == x509.ExtKeyUsageAny {
+			return nil
+		}
+		for _, want := range usages {
+			if want == x509.ExtKeyUsageAny || want == have {
+				return nil
+			}
+		}
+	}
+	return &Problem{
+		Kind:   ProblemWrongEKU,
+		Detail: "certificate does not permit the requested extended key usage",
+	}
+}
+
+func checkPath(path []candidate, terminal *Problem, opts Options, usages []x509.ExtKeyUsage) Path {
+	p := Path{Hops: make([]Hop, 0, len(path))}
+
+	for i, c := range path {
+		hop := Hop{
+			CommonName: c.cert.Subject.CommonName,
+			Subject:    c.cert.Subject.String(),
+			Issuer:     c.cert.Issuer.String(),
+			NotBefore:  c.cert.NotBefore,
+			NotAfter:   c.cert.NotAfter,
+			Source:     c.source,
+		}
+
+		if problem := checkValidity(c.cert, opts.At); problem != nil {
+			hop.Problems = append(hop.Problems, *problem)
+		}
+
+		if i == 0 && opts.DNSName != "" {
+			if err := c.cert.VerifyHostname(opts.DNSName); err != nil {
+				hop.Problems = append(hop.Problems, Problem{Kind: ProblemNameMismatch, Detail: err.Error()})
+			}
+		}
+
+		if problem := checkExtKeyUsage(c.cert, usages); problem != nil {
+			hop.Problems = append(hop.Problems, *problem)
+		}
+
+		if i > 0 {
+			if !c.cert.BasicConstraintsValid || !c.cert.IsCA {
+				hop.Problems = append(hop.Problems, Problem{
+					Kind:   ProblemNotCA,
+					Detail: "issuer does not have the CA basic constraint",
+				})
+			} else if c.cert.MaxPathLen >= 0 && (c.cert.MaxPathLen > 0 || c.cert.MaxPathLenZero) && i-1 > c.cert.MaxPathLen {
+				hop.Problems = append(hop.Problems, Problem{
+					Kind:   ProblemPathLenTooLong,
+					Detail: fmt.Sprintf("pathLen %d allows fewer intermediates than %d", c.cert.MaxPathLen, i-1),
+				})
+			}
+		}
+
+		if i+1 < len(path) {
+			err := path[i+1].cert.CheckSignature(c.cert.SignatureAlgorithm, c.cert.RawTBSCertificate, c.cert.Signature)
+			if err != nil {
+				hop.Problems = append(hop.Problems, Problem{Kind: ProblemBadSignature, Detail: err.Error()})
+			}
+		}
+
+		p.Hops = append(p.Hops, hop)
+	}
+
+	if terminal != nil {
+		p.Problems = append(p.Problems, *terminal)
+	}
+
+	p.Trusted = terminal == nil
+	p.Valid = p.Trusted
+	for _, hop := range p.Hops {
+		if len(hop.Problems) > 0 {
+			p.Valid = false
+		}
+	}
+	return p
+}
+
+// Verify builds every candidate path from the leaf certificate in leafPath to
+// a trusted root and reports the problems found on each hop. Additional
+// certificates in the leaf file are treated as intermediates.
+func Verify(leafPath string, opts Options) (*VerifyResult, error) {
+	leafCerts, err := certificate.LoadX509Certificates(leafPath)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates, err := loadCertificateFiles(opts.Intermediates)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range leafCerts[1:] {
+		if !containsCert(intermediates, cert) {
+			intermediates = append(intermediates, cert)
+		}
+	}
+
+	roots, err := loadCertificateFiles(opts.Roots)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roots) == 0 && !opts.SystemRoots {
+		return nil, errors.New("no trusted roots supplied")
+	}
+
+	usages := make([]x509.ExtKeyUsage, 0, len(opts.KeyUsages))
+	for _, name := range opts.KeyUsages {
+		eku, err := parseExtKeyUsage(name)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, eku)
+	}
+
+	if opts.At.IsZero() {
+		opts.At = time.Now()
+	}
+
+	b := &builder{
+		intermediates: intermediates,
+		roots:         roots,
+		at:            opts.At,
+	}
+	if opts.SystemRoots {
+		b.systemRoots, err = x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load system roots: %w", err)
+		}
+	}
+
+	leaf := leafCerts[0]
+	source := SourceLeaf
+	if containsCert(roots, leaf) {
+		source = SourceRoot
+	}
+	b.extend([]candidate{{cert: leaf, source: source}})
+
+	result := &VerifyResult{
+		Filename:   leafPath,
+		CommonName: leaf.Subject.CommonName,
+		VerifiedAt: opts.At,
+		DNSName:    opts.DNSName,
+		KeyUsages:  opts.KeyUsages,
+	}
+	for i, path := range b.paths {
+		p := checkPath(path, b.terminal[i], opts, usages)
+		if p.Valid {
+			result.Valid = true
+		}
+		result.Paths = append(result.Paths, p)
+	}
+
+	return result, nil
+}
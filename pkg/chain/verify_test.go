@@ -0,0 +1,133 @@
+package chain
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestCertPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func hasProblem(problems []Problem, kind ProblemKind) bool {
+	for _, p := range problems {
+		if p.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVerifyValidChain(t *testing.T) {
+	result, err := Verify(getTestCertPath("chain/server.crt"), Options{
+		Intermediates: []string{getTestCertPath("chain/intermediate-ca.crt")},
+		Roots:         []string{getTestCertPath("chain/root-ca.crt")},
+		DNSName:       "localhost",
+		KeyUsages:     []string{"serverAuth"},
+	})
+	require.NoError(t, err, "failed to verify chain")
+	assert.True(t, result.Valid)
+	require.Len(t, result.Paths, 1)
+
+	path := result.Paths[0]
+	assert.True(t, path.Trusted)
+	require.Len(t, path.Hops, 3)
+	assert.Equal(t, SourceLeaf, path.Hops[0].Source)
+	assert.Equal(t, "Test Intermediate CA", path.Hops[1].CommonName)
+	assert.Equal(t, SourceIntermediate, path.Hops[1].Source)
+	assert.Equal(t, "Test Root CA", path.Hops[2].CommonName)
+	assert.Equal(t, SourceRoot, path.Hops[2].Source)
+}
+
+func TestVerifyMissingIssuer(t *testing.T) {
+	result, err := Verify(getTestCertPath("chain/server.crt"), Options{
+		Roots: []string{getTestCertPath("chain/root-ca.crt")},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Paths, 1)
+	assert.False(t, result.Paths[0].Trusted)
+	assert.True(t, hasProblem(result.Paths[0].Problems, ProblemMissingIssuer))
+}
+
+func TestVerifyUntrustedRoot(t *testing.T) {
+	result, err := Verify(getTestCertPath("selfsigned/rsa-selfsigned.crt"), Options{
+		Roots: []string{getTestCertPath("chain/root-ca.crt")},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Paths, 1)
+	assert.True(t, hasProblem(result.Paths[0].Problems, ProblemUntrustedRoot))
+}
+
+func TestVerifyNameMismatch(t *testing.T) {
+	result, err := Verify(getTestCertPath("san-types/san-rsa.crt"), Options{
+		Roots:   []string{getTestCertPath("traditional/rsa/ca-rsa2048.crt")},
+		DNSName: "example.com",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Paths, 1)
+	assert.True(t, hasProblem(result.Paths[0].Hops[0].Problems, ProblemNameMismatch))
+}
+
+func TestVerifyWrongEKU(t *testing.T) {
+	result, err := Verify(getTestCertPath("client/client.crt"), Options{
+		Roots:     []string{getTestCertPath("traditional/rsa/ca-rsa2048.crt")},
+		KeyUsages: []string{"serverAuth"},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.True(t, hasProblem(result.Paths[0].Hops[0].Problems, ProblemWrongEKU))
+
+	result, err = Verify(getTestCertPath("client/client.crt"), Options{
+		Roots:     []string{getTestCertPath("traditional/rsa/ca-rsa2048.crt")},
+		KeyUsages: []string{"clientAuth"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
+
+func TestVerifyExpired(t *testing.T) {
+	result, err := Verify(getTestCertPath("chain/server.crt"), Options{
+		Intermediates: []string{getTestCertPath("chain/intermediate-ca.crt")},
+		Roots:         []string{getTestCertPath("chain/root-ca.crt")},
+		At:            time.Now().AddDate(5, 0, 0),
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	for _, hop := range result.Paths[0].Hops {
+		assert.True(t, hasProblem(hop.Problems, ProblemExpired), "expected %s to be expired", hop.CommonName)
+	}
+}
+
+func TestVerifyNotYetValid(t *testing.T) {
+	result, err := Verify(getTestCertPath("chain/server.crt"), Options{
+		Intermediates: []string{getTestCertPath("chain/intermediate-ca.crt")},
+		Roots:         []string{getTestCertPath("chain/root-ca.crt")},
+		At:            time.Now().AddDate(-1, 0, 0),
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.True(t, hasProblem(result.Paths[0].Hops[0].Problems, ProblemNotYetValid))
+}
+
+func TestVerifyErrors(t *testing.T) {
+	_, err := Verify(getTestCertPath("chain/server.crt"), Options{})
+	assert.Error(t, err, "expected error without trusted roots")
+
+	_, err = Verify(getTestCertPath("nonexistent.crt"), Options{
+		Roots: []string{getTestCertPath("chain/root-ca.crt")},
+	})
+	assert.Error(t, err, "expected error for non-existent file")
+
+	_, err = Verify(getTestCertPath("chain/server.crt"), Options{
+		Roots:     []string{getTestCertPath("chain/root-ca.crt")},
+		KeyUsages: []string{"bogus"},
+	})
+	assert.Error(t, err, "expected error for unknown EKU")
+}
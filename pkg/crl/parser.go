@@ -0,0 +1,139 @@
+package crl
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/marco-introini/certinfo/pkg/pem"
+)
+
+const oidExtDeltaCRLIndicator = "2.5.29.27"
+
+const reasonRemoveFromCRL = 8
+
+var reasonNames = map[int]string{
+	0:  "unspecified",
+	1:  "keyCompromise",
+	2:  "cACompromise",
+	3:  "affiliationChanged",
+	4:  "superseded",
+	5:  "cessationOfOperation",
+	6:  "certificateHold",
+	8:  "removeFromCRL",
+	9:  "privilegeWithdrawn",
+	10: "aACompromise",
+}
+
+type RevokedEntry struct {
+	SerialNumber   string
+	RevocationTime time.Time
+	Reason         string
+	reasonCode     int
+}
+
+type CRLInfo struct {
+	Filename       string
+	Encoding       string
+	Issuer         string
+	IssuerDN       string
+	Algorithm      string
+	ThisUpdate     time.Time
+	NextUpdate     time.Time
+	Number         string
+	IsDelta        bool
+	BaseCRLNumber  string
+	AuthorityKeyID string
+	Stale          bool
+	Revoked        []RevokedEntry
+
+	list *x509.RevocationList
+}
+
+func ReasonString(code int) string {
+	if name, ok := reasonNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", code)
+}
+
+func isStale(nextUpdate time.Time, now time.Time) bool {
+	return !nextUpdate.IsZero() && now.After(nextUpdate)
+}
+
+func parseCRLData(data []byte, filePath string) (*CRLInfo, error) {
+	der := data
+	encoding := "DER"
+	if pem.IsPEM(data) {
+		der, _ = pem.FindBlock(data, pem.TypeCRL)
+		if der == nil {
+			return nil, fmt.Errorf("no CRL found in %s", filePath)
+		}
+		encoding = "PEM"
+	}
+
+	list, err := x509.ParseRevocationList(der)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CRLInfo{
+		Filename:       filePath,
+		Encoding:       encoding,
+		Issuer:         list.Issuer.CommonName,
+		IssuerDN:       list.Issuer.String(),
+		Algorithm:      list.SignatureAlgorithm.String(),
+		ThisUpdate:     list.ThisUpdate,
+		NextUpdate:     list.NextUpdate,
+		AuthorityKeyID: fingerprint.ColonHex(list.AuthorityKeyId),
+		Stale:          isStale(list.NextUpdate, time.Now()),
+		list:           list,
+	}
+	if list.Number != nil {
+		info.Number = list.Number.String()
+	}
+
+	for _, ext := range list.Extensions {
+		if ext.Id.String() != oidExtDeltaCRLIndicator {
+			continue
+		}
+		info.IsDelta = true
+		var base *big.Int
+		if _, err := asn1.Unmarshal(ext.Value, &base); err == nil {
+			info.BaseCRLNumber = base.String()
+		}
+	}
+
+	for _, entry := range list.RevokedCertificateEntries {
+		info.Revoked = append(info.Revoked, RevokedEntry{
+			SerialNumber:   entry.SerialNumber.String(),
+			RevocationTime: entry.RevocationTime,
+			Reason:         ReasonString(entry.ReasonCode),
+			reasonCode:     entry.ReasonCode,
+		})
+	}
+
+	return info, nil
+}
+
+func ParseCRL(filePath string) (*CRLInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCRLData(data, filePath)
+}
+
+func ParseCRLFromBytes(data []byte, filename ...string) (*CRLInfo, error) {
+	name := ""
+	if len(filename) > 0 {
+		name = filename[0]
+	}
+
+	return parseCRLData(data, name)
+}
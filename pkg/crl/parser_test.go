@@ -0,0 +1,69 @@
+package crl
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestCertPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func TestParseCRL(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		encoding string
+	}{
+		{"crl/ca.crl", "PEM"},
+		{"crl/ca-der.crl", "DER"},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			list, err := ParseCRL(getTestCertPath(tt.file))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.encoding, list.Encoding)
+			assert.Equal(t, "Test CRL CA", list.Issuer)
+			assert.Equal(t, "CN=Test CRL CA,O=TestCRL,C=IT", list.IssuerDN)
+			assert.Equal(t, "1", list.Number)
+			assert.False(t, list.IsDelta)
+			assert.False(t, list.Stale)
+			assert.NotEmpty(t, list.AuthorityKeyID)
+			assert.True(t, list.NextUpdate.After(list.ThisUpdate))
+
+			require.Len(t, list.Revoked, 1)
+			assert.Equal(t, "4097", list.Revoked[0].SerialNumber)
+			assert.Equal(t, "keyCompromise", list.Revoked[0].Reason)
+		})
+	}
+}
+
+func TestParseCRLStale(t *testing.T) {
+	list, err := ParseCRL(getTestCertPath("crl/stale.crl"))
+	require.NoError(t, err)
+
+	assert.True(t, list.Stale)
+	assert.Equal(t, time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), list.NextUpdate)
+}
+
+func TestParseCRLDelta(t *testing.T) {
+	list, err := ParseCRL(getTestCertPath("crl/delta.crl"))
+	require.NoError(t, err)
+
+	assert.True(t, list.IsDelta)
+	assert.Equal(t, "1", list.BaseCRLNumber)
+}
+
+func TestParseCRLNotACRL(t *testing.T) {
+	_, err := ParseCRL(getTestCertPath("crl/ca.crt"))
+	assert.Error(t, err)
+}
+
+func TestReasonString(t *testing.T) {
+	assert.Equal(t, "unspecified", ReasonString(0))
+	assert.Equal(t, "certificateHold", ReasonString(6))
+	assert.Equal(t, "unknown (7)", ReasonString(7))
+}
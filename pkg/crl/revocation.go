@@ -0,0 +1,220 @@
+package crl
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/x509"
+	"math/big"
+	"slices"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+)
+
+const reasonCertificateHold = 6
+
+// Set is a collection of CRLs used to check certificates for revocation.
+type Set struct {
+	crls    []*CRLInfo
+	issuers []*x509.Certificate
+	// signatures holds, for each CRL, whether its signature verified with
+	// an issuer, failed with every issuer of its subject, or could not be
+	// checked because none was supplied.
+	signatures map[*CRLInfo]signatureStatus
+}
+
+type signatureStatus int
+
+const (
+	signatureUnchecked signatureStatus = iota
+	signatureVerified
+	signatureRejected
+)
+
+func LoadSet(paths ...string) (*Set, error) {
+	set := &Set{}
+	for _, path := range paths {
+		info, err := ParseCRL(path)
+		if err != nil {
+			return nil, err
+		}
+		set.crls = append(set.crls, info)
+	}
+	return set, nil
+}
+
+// AddIssuers supplies the CA certificates the signatures of the CRLs are
+// verified with. A CRL whose signature does not verify with any issuer of
+// its subject is ignored; without such an issuer, the CRL is used but the
+// statuses it gives are reported as unverified.
+func (s *Set) AddIssuers(issuers ...*x509.Certificate) {
+	s.issuers = append(s.issuers, issuers...)
+	s.signatures = nil
+}
+
+func (s *Set) Stale() []*CRLInfo {
+	var stale []*CRLInfo
+	for _, list := range s.crls {
+		if list.Stale {
+			stale = append(stale, list)
+		}
+	}
+	return stale
+}
+
+// Unverified returns the CRLs whose signature could not be checked because
+// no issuer of their subject was supplied.
+func (s *Set) Unverified() []*CRLInfo {
+	return s.withSignature(signatureUnchecked)
+}
+
+// Rejected returns the CRLs whose signature does not verify, and which are
+// therefore ignored.
+func (s *Set) Rejected() []*CRLInfo {
+	return s.withSignature(signatureRejected)
+}
+
+func (s *Set) withSignature(status signatureStatus) []*CRLInfo {
+	var lists []*CRLInfo
+	for _, list := range s.crls {
+		if s.signature(list) == status {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
+
+func (s *Set) signature(list *CRLInfo) signatureStatus {
+	if s.signatures == nil {
+		s.signatures = make(map[*CRLInfo]signatureStatus)
+		for _, l := range s.crls {
+			s.signatures[l] = s.verify(l)
+		}
+	}
+	return s.signatures[list]
+}
+
+func (s *Set) verify(list *CRLInfo) signatureStatus {
+	if list.list == nil {
+		return signatureUnchecked
+	}
+	status := signatureUnchecked
+	for _, issuer := range s.issuers {
+		if !bytes.Equal(issuer.RawSubject, list.list.RawIssuer) {
+			continue
+		}
+		if len(list.list.AuthorityKeyId) > 0 && len(issuer.SubjectKeyId) > 0 && !bytes.Equal(list.list.AuthorityKeyId, issuer.SubjectKeyId) {
+			continue
+		}
+		if list.list.CheckSignatureFrom(issuer) == nil {
+			return signatureVerified
+		}
+		status = signatureRejected
+	}
+	return status
+}
+
+// crlNumber returns the CRL number, or -1 for a CRL without one.
+func crlNumber(number string) *big.Int {
+	n, ok := new(big.Int).SetString(number, 10)
+	if !ok {
+		return big.NewInt(-1)
+	}
+	return n
+}
+
+// newer orders CRLs by CRL number, then by thisUpdate.
+func newer(a, b *CRLInfo) int {
+	return cmp.Or(crlNumber(a.Number).Cmp(crlNumber(b.Number)), a.ThisUpdate.Compare(b.ThisUpdate))
+}
+
+func (list *CRLInfo) entry(serial string) *RevokedEntry {
+	for i := range list.Revoked {
+		if list.Revoked[i].SerialNumber == serial {
+			return &list.Revoked[i]
+		}
+	}
+	return nil
+}
+
+func revokedBy(list *CRLInfo, entry *RevokedEntry) *certificate.Revocation {
+	return &certificate.Revocation{
+		Status:         certificate.RevocationRevoked,
+		RevocationTime: entry.RevocationTime,
+		Reason:         entry.Reason,
+		Source:         list.Filename,
+	}
+}
+
+// Lookup reports the revocation status of the certificate with the given
+// issuer DN, authority key identifier and decimal serial number. Only the
+// CRLs of that issuer count: same DN and, when both are known, same key
+// identifier. The newest complete CRL, by CRL number, gives the status,
+// and the newest delta CRL based on it updates it; a removeFromCRL entry
+// only cancels a certificateHold. The status is unknown when no CRL of the
+// set was issued by that issuer.
+func (s *Set) Lookup(issuerDN, authorityKeyID, serial string) *certificate.Revocation {
+	var base, delta *CRLInfo
+	var deltas []*CRLInfo
+	for _, list := range s.crls {
+		if list.IssuerDN != issuerDN || s.signature(list) == signatureRejected {
+			continue
+		}
+		if list.AuthorityKeyID != "" && authorityKeyID != "" && list.AuthorityKeyID != authorityKeyID {
+			continue
+		}
+		if list.IsDelta {
+			deltas = append(deltas, list)
+		} else if base == nil || newer(list, base) > 0 {
+			base = list
+		}
+	}
+	for _, d := range deltas {
+		// A delta CRL only applies to a complete CRL at least as new as its
+		// base, and older than itself.
+		if base != nil && (crlNumber(d.BaseCRLNumber).Cmp(crlNumber(base.Number)) > 0 || crlNumber(d.Number).Cmp(crlNumber(base.Number)) <= 0) {
+			continue
+		}
+		if delta == nil || newer(d, delta) > 0 {
+			delta = d
+		}
+	}
+
+	result := &certificate.Revocation{Status: certificate.RevocationUnknown}
+	if base != nil {
+		result = &certificate.Revocation{Status: certificate.RevocationGood, Source: base.Filename}
+		if e := base.entry(serial); e != nil && e.reasonCode != reasonRemoveFromCRL {
+			result = revokedBy(base, e)
+		}
+	}
+	if delta != nil {
+		switch e := delta.entry(serial); {
+		case e == nil:
+		case e.reasonCode == reasonRemoveFromCRL:
+			if result.IsRevoked() && result.Reason == ReasonString(reasonCertificateHold) {
+				result = &certificate.Revocation{Status: certificate.RevocationGood, Source: delta.Filename}
+			}
+		default:
+			result = revokedBy(delta, e)
+		}
+	}
+
+	if result.Status != certificate.RevocationUnknown {
+		result.Verified = slices.IndexFunc([]*CRLInfo{base, delta}, func(l *CRLInfo) bool {
+			return l != nil && s.signature(l) != signatureVerified
+		}) < 0
+	}
+	return result
+}
+
+func (s *Set) Check(cert *certificate.CertificateInfo) {
+	cert.Revocation = s.Lookup(cert.IssuerDN, cert.AuthorityKeyID, cert.SerialNumber)
+}
+
+// CheckSummaries sets the status of every revoked certificate to revoked.
+func (s *Set) CheckSummaries(summaries []certificate.CertificateSummary) {
+	for i := range summaries {
+		if s.Lookup(summaries[i].IssuerDN, summaries[i].AuthorityKeyID, summaries[i].SerialNumber).IsRevoked() {
+			summaries[i].Status = certificate.RevocationRevoked
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package crl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCheck(t *testing.T) {
+	set, err := LoadSet(getTestCertPath("crl/ca.crl"))
+	require.NoError(t, err)
+
+	revoked, err := certificate.ParseCertificate(getTestCertPath("crl/revoked.crt"))
+	require.NoError(t, err)
+	set.Check(revoked)
+	require.NotNil(t, revoked.Revocation)
+	assert.True(t, revoked.Revocation.IsRevoked())
+	assert.Equal(t, "keyCompromise", revoked.Revocation.Reason)
+
+	good, err := certificate.ParseCertificate(getTestCertPath("crl/good.crt"))
+	require.NoError(t, err)
+	set.Check(good)
+	assert.Equal(t, certificate.RevocationGood, good.Revocation.Status)
+
+	other, err := certificate.ParseCertificate(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+	set.Check(other)
+	assert.Equal(t, certificate.RevocationUnknown, other.Revocation.Status)
+}
+
+func TestSetCheckSummaries(t *testing.T) {
+	set, err := LoadSet(getTestCertPath("crl/ca-der.crl"))
+	require.NoError(t, err)
+
+	summaries, _, err := certificate.SummarizeDirectory(getTestCertPath("crl"))
+	require.NoError(t, err)
+	set.CheckSummaries(summaries)
+
+	statuses := make(map[string]string)
+	for _, s := range summaries {
+		statuses[s.Filename] = s.Status
+	}
+	assert.Equal(t, "revoked", statuses["revoked.crt"])
+	assert.Equal(t, "valid", statuses["good.crt"])
+}
+
+func TestSetLookupRemoveFromCRL(t *testing.T) {
+	issuer := "CN=Test CRL CA,O=TestCRL,C=IT"
+	held := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := &CRLInfo{Filename: "base.crl", IssuerDN: issuer, Number: "1", Revoked: []RevokedEntry{
+		{SerialNumber: "10", RevocationTime: held, Reason: "certificateHold", reasonCode: 6},
+	}}
+	delta := &CRLInfo{Filename: "delta.crl", IssuerDN: issuer, Number: "2", IsDelta: true, BaseCRLNumber: "1", Revoked: []RevokedEntry{
+		{SerialNumber: "10", RevocationTime: held, Reason: "removeFromCRL", reasonCode: reasonRemoveFromCRL},
+	}}
+	compromised := &CRLInfo{Filename: "newer.crl", IssuerDN: issuer, Number: "3", Revoked: []RevokedEntry{
+		{SerialNumber: "10", RevocationTime: held, Reason: "keyCompromise", reasonCode: 1},
+	}}
+
+	result := (&Set{crls: []*CRLInfo{delta, base}}).Lookup(issuer, "", "10")
+	assert.Equal(t, certificate.RevocationGood, result.Status)
+	assert.Equal(t, "delta.crl", result.Source)
+	assert.False(t, result.Verified)
+
+	// The newest complete CRL decides, whatever the order of the files: the
+	// delta based on an older CRL no longer applies.
+	for _, crls := range [][]*CRLInfo{{base, delta, compromised}, {compromised, delta, base}, {delta, compromised, base}} {
+		result = (&Set{crls: crls}).Lookup(issuer, "", "10")
+		assert.True(t, result.IsRevoked())
+		assert.Equal(t, "keyCompromise", result.Reason)
+		assert.Equal(t, "newer.crl", result.Source)
+	}
+
+	// removeFromCRL only lifts a hold.
+	revoked := &CRLInfo{Filename: "base.crl", IssuerDN: issuer, Number: "1", Revoked: compromised.Revoked}
+	result = (&Set{crls: []*CRLInfo{revoked, delta}}).Lookup(issuer, "", "10")
+	assert.True(t, result.IsRevoked())
+}
+
+// impostorCRL returns a CRL revoking serial, issued by a new CA with the
+// same DN as ca but another key, and the given subject key identifier.
+func impostorCRL(t *testing.T, ca *x509.Certificate, keyID []byte, serial *big.Int) *CRLInfo {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		RawSubject:            ca.RawSubject,
+		SubjectKeyId:          keyID,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	impostor, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	der, err = x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(100),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: serial, RevocationTime: time.Now().Add(-time.Hour), ReasonCode: 1},
+		},
+	}, impostor, key)
+	require.NoError(t, err)
+	list, err := ParseCRLFromBytes(der, "impostor.crl")
+	require.NoError(t, err)
+	return list
+}
+
+func TestSetVerifiesCRLs(t *testing.T) {
+	ca, err := certificate.LoadX509Certificates(getTestCertPath("crl/ca.crt"))
+	require.NoError(t, err)
+	base, err := ParseCRL(getTestCertPath("crl/ca.crl"))
+	require.NoError(t, err)
+	good, err := certificate.ParseCertificate(getTestCertPath("crl/good.crt"))
+	require.NoError(t, err)
+
+	set := &Set{crls: []*CRLInfo{base}}
+	set.Check(good)
+	assert.Equal(t, certificate.RevocationGood, good.Revocation.Status)
+	assert.False(t, good.Revocation.Verified)
+	assert.Equal(t, []*CRLInfo{base}, set.Unverified())
+
+	set.AddIssuers(ca...)
+	set.Check(good)
+	assert.True(t, good.Revocation.Verified)
+	assert.Empty(t, set.Unverified())
+}
+
+func TestSetIgnoresCRLsOfOtherCAs(t *testing.T) {
+	ca, err := certificate.LoadX509Certificates(getTestCertPath("crl/ca.crt"))
+	require.NoError(t, err)
+	base, err := ParseCRL(getTestCertPath("crl/ca.crl"))
+	require.NoError(t, err)
+	good, err := certificate.LoadX509Certificates(getTestCertPath("crl/good.crt"))
+	require.NoError(t, err)
+	issuer, serial := base.IssuerDN, good[0].SerialNumber.String()
+	keyID := fingerprint.ColonHex(ca[0].SubjectKeyId)
+
+	// A CA with the same DN but another key identifier.
+	other := impostorCRL(t, ca[0], []byte{9, 9, 9}, good[0].SerialNumber)
+	set := &Set{crls: []*CRLInfo{base, other}}
+	assert.Equal(t, certificate.RevocationGood, set.Lookup(issuer, keyID, serial).Status)
+	assert.True(t, set.Lookup(issuer, "", serial).IsRevoked(), "without a key identifier the DN alone matches")
+
+	// A CRL claiming the key identifier of the CA, but not signed by it.
+	forged := impostorCRL(t, ca[0], ca[0].SubjectKeyId, good[0].SerialNumber)
+	set = &Set{crls: []*CRLInfo{base, forged}}
+	assert.True(t, set.Lookup(issuer, keyID, serial).IsRevoked())
+	set.AddIssuers(ca...)
+	assert.Equal(t, []*CRLInfo{forged}, set.Rejected())
+	result := set.Lookup(issuer, keyID, serial)
+	assert.Equal(t, certificate.RevocationGood, result.Status)
+	assert.True(t, result.Verified)
+}
@@ -0,0 +1,224 @@
+package csr
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"os"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+)
+
+const (
+	oidExtKeyUsage         = "2.5.29.15"
+	oidExtBasicConstraints = "2.5.29.19"
+	oidExtSubjectAltName   = "2.5.29.17"
+	oidExtExtendedKeyUsage = "2.5.29.37"
+)
+
+var extensionNames = map[string]string{
+	oidExtKeyUsage:         "Key Usage",
+	oidExtBasicConstraints: "Basic Constraints",
+	oidExtSubjectAltName:   "Subject Alternative Name",
+	oidExtExtendedKeyUsage: "Extended Key Usage",
+	"2.5.29.14":            "Subject Key Identifier",
+	"2.5.29.32":            "Certificate Policies",
+	"1.3.6.1.5.5.7.1.24":   "TLS Feature",
+}
+
+var extKeyUsageOIDs = map[string]x509.ExtKeyUsage{
+	"2.5.29.37.0":       x509.ExtKeyUsageAny,
+	"1.3.6.1.5.5.7.3.1": x509.ExtKeyUsageServerAuth,
+	"1.3.6.1.5.5.7.3.2": x509.ExtKeyUsageClientAuth,
+	"1.3.6.1.5.5.7.3.3": x509.ExtKeyUsageCodeSigning,
+	"1.3.6.1.5.5.7.3.4": x509.ExtKeyUsageEmailProtection,
+	"1.3.6.1.5.5.7.3.5": x509.ExtKeyUsageIPSECEndSystem,
+	"1.3.6.1.5.5.7.3.6": x509.ExtKeyUsageIPSECTunnel,
+	"1.3.6.1.5.5.7.3.7": x509.ExtKeyUsageIPSECUser,
+	"1.3.6.1.5.5.7.3.8": x509.ExtKeyUsageTimeStamping,
+	"1.3.6.1.5.5.7.3.9": x509.ExtKeyUsageOCSPSigning,
+}
+
+type RequestedExtension struct {
+	OID      string
+	Name     string
+	Critical bool
+}
+
+type CSRInfo struct {
+	Filename           string
+	Encoding           string
+	CommonName         string
+	Subject            string
+	Algorithm          string
+	KeyType            string
+	Bits               int
+	SANs               []string
+	IPSANs             []string
+	EmailSANs          []string
+	URISANs            []string
+	KeyUsage           []string
+	ExtKeyUsageStrings []string
+	BasicConstraints   *certificate.BasicConstraints
+	Extensions         []RequestedExtension
+	IsQuantumSafe      bool
+	PQCTypes           []string
+	SignatureValid     bool
+	SignatureError     string
+	SPKISHA256         string
+	SPKISHA256Hex      string
+}
+
+type basicConstraints struct {
+	IsCA       bool `asn1:"optional"`
+	MaxPathLen int  `asn1:"optional,default:-1"`
+}
+
+func parseKeyUsage(value []byte) []string {
+	var bits asn1.BitString
+	if _, err := asn1.Unmarshal(value, &bits); err != nil {
+		return nil
+	}
+	var usage x509.KeyUsage
+	for i := 0; i < 9; i++ {
+		if bits.At(i) != 0 {
+			usage |= 1 << uint(i)
+		}
+	}
+	return certificate.KeyUsageStrings(usage)
+}
+
+func parseExtKeyUsage(value []byte) []string {
+	var oids []asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(value, &oids); err != nil {
+		return nil
+	}
+	var usages []string
+	for _, oid := range oids {
+		if eku, ok := extKeyUsageOIDs[oid.String()]; ok {
+			usages = append(usages, certificate.ExtKeyUsageString(eku))
+		} else {
+			usages = append(usages, oid.String())
+		}
+	}
+	return usages
+}
+
+func parseBasicConstraints(value []byte) *certificate.BasicConstraints {
+	var bc basicConstraints
+	if _, err := asn1.Unmarshal(value, &bc); err != nil {
+		return nil
+	}
+	result := &certificate.BasicConstraints{IsCA: bc.IsCA}
+	if bc.IsCA && bc.MaxPathLen >= 0 {
+		pathLen := bc.MaxPathLen
+		result.PathLen = &pathLen
+	}
+	return result
+}
+
+func decodeExtensions(req *x509.CertificateRequest, info *CSRInfo) {
+	for _, ext := range req.Extensions {
+		oid := ext.Id.String()
+		name, ok := extensionNames[oid]
+		if !ok {
+			name = oid
+		}
+		info.Extensions = append(info.Extensions, RequestedExtension{
+			OID:      oid,
+			Name:     name,
+			Critical: ext.Critical,
+		})
+
+		switch oid {
+		case oidExtKeyUsage:
+			info.KeyUsage = parseKeyUsage(ext.Value)
+		case oidExtExtendedKeyUsage:
+			info.ExtKeyUsageStrings = parseExtKeyUsage(ext.Value)
+		case oidExtBasicConstraints:
+			info.BasicConstraints = parseBasicConstraints(ext.Value)
+		}
+	}
+}
+
+func parseCSRData(data []byte, filePath string) (*CSRInfo, error) {
+	der := data
+	encoding := "DER"
+	if pem.IsPEM(data) {
+		der, _ = pem.FindBlock(data, pem.TypeCSR, pem.TypeNewCSR)
+		if der == nil {
+			return nil, fmt.Errorf("no certificate request found in %s", filePath)
+		}
+		encoding = "PEM"
+	}
+
+	req, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+
+	algoName := req.SignatureAlgorithm.String()
+	sigAlg, keyAlg := pqc.Detect(req.Raw, req.RawSubjectPublicKeyInfo)
+	if sigAlg != nil {
+		algoName = sigAlg.Name
+	} else if req.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		if oid, err := pqc.SignatureAlgorithmOID(req.Raw); err == nil {
+			algoName = oid
+		}
+	}
+	pqcTypes := pqc.Names(sigAlg, keyAlg)
+	isQuantumSafe := len(pqcTypes) > 0
+
+	info := &CSRInfo{
+		Filename:      filePath,
+		Encoding:      encoding,
+		CommonName:    req.Subject.CommonName,
+		Subject:       req.Subject.String(),
+		Algorithm:     algoName,
+		SANs:          req.DNSNames,
+		EmailSANs:     req.EmailAddresses,
+		IsQuantumSafe: isQuantumSafe,
+		PQCTypes:      pqcTypes,
+	}
+	info.KeyType, info.Bits = certificate.KeyTypeAndBits(req.PublicKey)
+	if keyAlg != nil {
+		info.KeyType, info.Bits = keyAlg.Family, keyAlg.Bits
+	}
+	info.SPKISHA256, info.SPKISHA256Hex = fingerprint.SPKI(req.RawSubjectPublicKeyInfo)
+	for _, ip := range req.IPAddresses {
+		info.IPSANs = append(info.IPSANs, ip.String())
+	}
+	for _, uri := range req.URIs {
+		info.URISANs = append(info.URISANs, uri.String())
+	}
+	decodeExtensions(req, info)
+
+	if err := req.CheckSignature(); err != nil {
+		info.SignatureError = err.Error()
+	} else {
+		info.SignatureValid = true
+	}
+
+	return info, nil
+}
+
+func ParseCSR(filePath string) (*CSRInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCSRData(data, filePath)
+}
+
+func ParseCSRFromBytes(data []byte, filename ...string) (*CSRInfo, error) {
+	name := ""
+	if len(filename) > 0 {
+		name = filename[0]
+	}
+
+	return parseCSRData(data, name)
+}
@@ -0,0 +1,71 @@
+package csr
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestCertPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func TestParseCSR(t *testing.T) {
+	req, err := ParseCSR(getTestCertPath("csr/server-rsa.csr"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "PEM", req.Encoding)
+	assert.Equal(t, "csr.test.local", req.CommonName)
+	assert.Equal(t, "RSA", req.KeyType)
+	assert.Equal(t, 2048, req.Bits)
+	assert.Equal(t, "SHA256-RSA", req.Algorithm)
+	assert.False(t, req.IsQuantumSafe)
+	assert.True(t, req.SignatureValid)
+	assert.Empty(t, req.SignatureError)
+
+	assert.Equal(t, []string{"csr.test.local", "www.csr.test.local"}, req.SANs)
+	assert.Equal(t, []string{"10.1.2.3"}, req.IPSANs)
+	assert.Equal(t, []string{"admin@csr.test.local"}, req.EmailSANs)
+	assert.Equal(t, []string{"Digital Signature", "Key Encipherment"}, req.KeyUsage)
+	assert.Equal(t, []string{"Server Authentication", "Client Authentication"}, req.ExtKeyUsageStrings)
+	require.NotNil(t, req.BasicConstraints)
+	assert.False(t, req.BasicConstraints.IsCA)
+
+	assert.Contains(t, req.Extensions, RequestedExtension{OID: "2.5.29.15", Name: "Key Usage", Critical: true})
+	assert.Contains(t, req.Extensions, RequestedExtension{OID: "1.3.6.1.4.1.99999.3", Name: "1.3.6.1.4.1.99999.3", Critical: false})
+}
+
+func TestParseCSRDER(t *testing.T) {
+	req, err := ParseCSR(getTestCertPath("csr/server-ecdsa.der"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "DER", req.Encoding)
+	assert.Equal(t, "ECDSA", req.KeyType)
+	assert.Equal(t, 256, req.Bits)
+	assert.True(t, req.SignatureValid)
+	assert.Empty(t, req.Extensions)
+}
+
+func TestParseCSRBadSignature(t *testing.T) {
+	data, err := os.ReadFile(getTestCertPath("csr/server-rsa.csr"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+
+	der := append([]byte(nil), block.Bytes...)
+	der[len(der)-1] ^= 0xff
+
+	req, err := ParseCSRFromBytes(der)
+	require.NoError(t, err)
+	assert.False(t, req.SignatureValid)
+	assert.NotEmpty(t, req.SignatureError)
+}
+
+func TestParseCSRNotARequest(t *testing.T) {
+	_, err := ParseCSR(getTestCertPath("chain/server.crt"))
+	assert.Error(t, err)
+}
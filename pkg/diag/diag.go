@@ -0,0 +1,176 @@
+// Package diag describes the files a directory summary could not use, so that
+// a skipped file is reported instead of silently dropped.
+package diag
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/marco-introini/certinfo/pkg/archive"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/walk"
+)
+
+type Category string
+
+const (
+	Corrupt          Category = "corrupt"
+	Encrypted        Category = "encrypted"
+	PermissionDenied Category = "permission denied"
+	Unsupported      Category = "unsupported algorithm"
+	Unreadable       Category = "unreadable"
+	// OtherType and Unrecognized files hold no object of the type being
+	// summarized: a key in a certificate directory, or a README.
+	OtherType    Category = "other type"
+	Unrecognized Category = "unrecognized"
+	// Skipped paths were left out by the walk options: files over the size
+	// limit, symbolic links to directories that were not followed, and
+	// archive entries beyond the extraction limits.
+	Skipped Category = "skipped"
+)
+
+// Categories lists the categories in the order they are reported.
+var Categories = []Category{Corrupt, Encrypted, PermissionDenied, Unsupported, Unreadable, OtherType, Unrecognized, Skipped}
+
+type Diagnostic struct {
+	Path     string
+	Type     pem.Kind
+	Category Category
+	Error    string `json:",omitempty"`
+}
+
+// IsWarning reports whether the file should have been summarized but could
+// not be, as opposed to a file of another type or one the walk skipped.
+func (d Diagnostic) IsWarning() bool {
+	return d.Category != OtherType && d.Category != Unrecognized && d.Category != Skipped
+}
+
+// errorCategories map error message fragments to categories, for errors of
+// parsers that do not export sentinel errors.
+var errorCategories = []struct {
+	fragment string
+	category Category
+}{
+	{"password", Encrypted},
+	{"encrypted", Encrypted},
+	{"decrypt", Encrypted},
+	{"unsupported", Unsupported},
+	{"unknown algorithm", Unsupported},
+	{"unknown public key algorithm", Unsupported},
+}
+
+// Categorize returns the category of a parse or I/O error.
+func Categorize(err error) Category {
+	switch {
+	case errors.Is(err, walk.ErrTooLarge), errors.Is(err, walk.ErrSymlinkDir), errors.Is(err, walk.ErrSymlinkLoop),
+		errors.Is(err, archive.ErrEntryTooLarge), errors.Is(err, archive.ErrTooDeep), errors.Is(err, archive.ErrLimit):
+		return Skipped
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied
+	case errors.Is(err, fs.ErrNotExist):
+		return Unreadable
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return Unreadable
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, c := range errorCategories {
+		if strings.Contains(msg, c.fragment) {
+			return c.category
+		}
+	}
+	return Corrupt
+}
+
+// ForFile returns the diagnostic of a file that could not be summarized as
+// one of the wanted kinds. data is nil when the file could not be read.
+func ForFile(path string, data []byte, err error, want ...pem.Kind) Diagnostic {
+	d := Diagnostic{Path: path, Type: pem.KindUnknown}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	if data == nil && err != nil {
+		d.Category = Categorize(err)
+		return d
+	}
+
+	objects := pem.Detect(data)
+	d.Type = pem.Classify(objects)
+	switch {
+	case len(objects) == 0:
+		d.Category = Unrecognized
+	case !holdsKind(objects, want):
+		d.Category = OtherType
+	case err == nil:
+		d.Category = Corrupt
+	default:
+		d.Category = Categorize(err)
+	}
+	return d
+}
+
+// ForPath returns the diagnostic of a path a directory walk could not enter
+// or stat.
+func ForPath(path string, err error) Diagnostic {
+	return Diagnostic{Path: path, Type: pem.KindUnknown, Category: Categorize(err), Error: err.Error()}
+}
+
+func holdsKind(objects []pem.Object, want []pem.Kind) bool {
+	for _, o := range objects {
+		for _, k := range want {
+			if o.Kind == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Warnings returns the diagnostics that are warnings.
+func Warnings(diags []Diagnostic) []Diagnostic {
+	var warnings []Diagnostic
+	for _, d := range diags {
+		if d.IsWarning() {
+			warnings = append(warnings, d)
+		}
+	}
+	return warnings
+}
+
+// Count returns the number of diagnostics of each category.
+func Count(diags []Diagnostic) map[Category]int {
+	counts := make(map[Category]int)
+	for _, d := range diags {
+		counts[d.Category]++
+	}
+	return counts
+}
+
+// Merge combines the diagnostics of several summaries of the same tree. A
+// file is only reported once: as a warning if any summary expected it, and
+// not at all if any summary could use it.
+func Merge(used map[string]bool, lists ...[]Diagnostic) []Diagnostic {
+	byPath := make(map[string]Diagnostic)
+	for _, list := range lists {
+		for _, d := range list {
+			if used[d.Path] {
+				continue
+			}
+			if prev, ok := byPath[d.Path]; ok && (prev.IsWarning() || !d.IsWarning()) {
+				continue
+			}
+			byPath[d.Path] = d
+		}
+	}
+
+	merged := make([]Diagnostic, 0, len(byPath))
+	for _, d := range byPath {
+		merged = append(merged, d)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Path < merged[j].Path })
+	return merged
+}
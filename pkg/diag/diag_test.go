@@ -0,0 +1,118 @@
+package diag
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-introini/certinfo/pkg/archive"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/walk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestFile(t *testing.T, relPath string) []byte {
+	data, err := os.ReadFile(filepath.Join("..", "..", "test_certs", relPath))
+	require.NoError(t, err)
+	return data
+}
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		err      error
+		category Category
+	}{
+		{&fs.PathError{Op: "open", Path: "key.pem", Err: fs.ErrPermission}, PermissionDenied},
+		{&fs.PathError{Op: "open", Path: "key.pem", Err: fs.ErrNotExist}, Unreadable},
+		{&fs.PathError{Op: "read", Path: "key.pem", Err: errors.New("is a directory")}, Unreadable},
+		{errors.New("private key is encrypted, password required"), Encrypted},
+		{errors.New("pkcs12: decryption password incorrect"), Encrypted},
+		{errors.New("unsupported private key type: ed25519.PrivateKey"), Unsupported},
+		{errors.New("x509: malformed certificate"), Corrupt},
+		{fmt.Errorf("certificate 1: %w", errors.New("x509: malformed tbs certificate")), Corrupt},
+		{fmt.Errorf("%w: 20971520 bytes", walk.ErrTooLarge), Skipped},
+		{walk.ErrSymlinkLoop, Skipped},
+		{archive.ErrTooDeep, Skipped},
+		{fmt.Errorf("%w: more than 100000 entries", archive.ErrLimit), Skipped},
+		{zip.ErrFormat, Corrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.category, Categorize(tt.err))
+		})
+	}
+}
+
+func TestForFile(t *testing.T) {
+	parseErr := errors.New("no certificate found")
+
+	d := ForFile("server.key", readTestFile(t, "traditional/rsa/server-rsa2048.key"), parseErr, pem.KindCertificate)
+	assert.Equal(t, Diagnostic{Path: "server.key", Type: pem.KindPrivateKey, Category: OtherType, Error: "no certificate found"}, d)
+	assert.False(t, d.IsWarning())
+
+	d = ForFile("README.md", readTestFile(t, "README.md"), parseErr, pem.KindCertificate)
+	assert.Equal(t, pem.KindUnknown, d.Type)
+	assert.Equal(t, Unrecognized, d.Category)
+
+	corrupt := []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")
+	d = ForFile("broken.crt", corrupt, errors.New("x509: malformed certificate"), pem.KindCertificate)
+	assert.Equal(t, pem.KindCertificate, d.Type)
+	assert.Equal(t, Corrupt, d.Category)
+	assert.True(t, d.IsWarning())
+
+	d = ForFile("ca.key", readTestFile(t, "traditional/rsa-encrypted/ca-rsa2048-encrypted.key"),
+		errors.New("private key is encrypted, password required"), pem.KindPrivateKey)
+	assert.Equal(t, Encrypted, d.Category)
+
+	d = ForFile("secret.pem", nil, &fs.PathError{Op: "open", Path: "secret.pem", Err: fs.ErrPermission})
+	assert.Equal(t, pem.KindUnknown, d.Type)
+	assert.Equal(t, PermissionDenied, d.Category)
+	assert.True(t, d.IsWarning())
+}
+
+func TestWarningsAndCount(t *testing.T) {
+	diags := []Diagnostic{
+		{Path: "a.crt", Category: Corrupt},
+		{Path: "b.key", Category: OtherType},
+		{Path: "c.key", Category: Encrypted},
+		{Path: "d.txt", Category: Unrecognized},
+		{Path: "e.crt", Category: Corrupt},
+		{Path: "large.pem", Category: Skipped},
+	}
+
+	warnings := Warnings(diags)
+	assert.Len(t, warnings, 3)
+	assert.Equal(t, map[Category]int{Corrupt: 2, Encrypted: 1}, Count(warnings))
+}
+
+func TestMerge(t *testing.T) {
+	certDiags := []Diagnostic{
+		{Path: "server.key", Category: OtherType},
+		{Path: "store.pfx", Category: OtherType},
+		{Path: "notes.txt", Category: Unrecognized},
+	}
+	keyDiags := []Diagnostic{
+		{Path: "store.pfx", Category: OtherType},
+		{Path: "notes.txt", Category: Unrecognized},
+		{Path: "ca.key", Category: Encrypted},
+	}
+	p12Diags := []Diagnostic{
+		{Path: "server.key", Category: OtherType},
+		{Path: "store.pfx", Category: Encrypted},
+		{Path: "notes.txt", Category: Unrecognized},
+		{Path: "ca.key", Category: OtherType},
+	}
+
+	merged := Merge(map[string]bool{"server.key": true}, certDiags, keyDiags, p12Diags)
+	assert.Equal(t, []Diagnostic{
+		{Path: "ca.key", Category: Encrypted},
+		{Path: "notes.txt", Category: Unrecognized},
+		{Path: "store.pfx", Category: Encrypted},
+	}, merged)
+}
@@ -0,0 +1,53 @@
+package fingerprint
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type Fingerprint struct {
+	Filename      string
+	Type          string
+	Subject       string
+	SHA1          string
+	SHA256        string
+	SPKISHA256    string
+	SPKISHA256Hex string
+}
+
+// ColonHex formats data as upper-case hex bytes separated by colons, the
+// form used by browsers and OpenSSL.
+func ColonHex(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	parts := make([]string, len(data))
+	for i, b := range data {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+func SHA1(der []byte) string {
+	sum := sha1.Sum(der)
+	return ColonHex(sum[:])
+}
+
+func SHA256(der []byte) string {
+	sum := sha256.Sum256(der)
+	return ColonHex(sum[:])
+}
+
+// SPKI returns the SHA-256 hash of a DER SubjectPublicKeyInfo in base64
+// (the HPKP pin-sha256 format) and in lower-case hex.
+func SPKI(spki []byte) (string, string) {
+	if len(spki) == 0 {
+		return "", ""
+	}
+	sum := sha256.Sum256(spki)
+	return base64.StdEncoding.EncodeToString(sum[:]), hex.EncodeToString(sum[:])
+}
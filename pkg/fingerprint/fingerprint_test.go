@@ -0,0 +1,28 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColonHex(t *testing.T) {
+	assert.Equal(t, "", ColonHex(nil))
+	assert.Equal(t, "00:AB:FF", ColonHex([]byte{0x00, 0xab, 0xff}))
+}
+
+func TestDigests(t *testing.T) {
+	data := []byte("abc")
+	assert.Equal(t, "A9:99:3E:36:47:06:81:6A:BA:3E:25:71:78:50:C2:6C:9C:D0:D8:9D", SHA1(data))
+	assert.Equal(t, "BA:78:16:BF:8F:01:CF:EA:41:41:40:DE:5D:AE:22:23:B0:03:61:A3:96:17:7A:9C:B4:10:FF:61:F2:00:15:AD", SHA256(data))
+}
+
+func TestSPKI(t *testing.T) {
+	b64, hexSum := SPKI([]byte("abc"))
+	assert.Equal(t, "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=", b64)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hexSum)
+
+	b64, hexSum = SPKI(nil)
+	assert.Empty(t, b64)
+	assert.Empty(t, hexSum)
+}
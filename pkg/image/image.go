@@ -0,0 +1,365 @@
+// Package image finds the certificates and private keys in the filesystem
+// of a container image, read offline from a docker save tarball or an OCI
+// image layout, and the layer that added each of them.
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/marco-introini/certinfo/pkg/archive"
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/walk"
+)
+
+const (
+	// whiteoutPrefix marks a file of a layer that deletes the file of the
+	// same name from the layers below.
+	whiteoutPrefix = ".wh."
+	// opaqueWhiteout marks a directory whose contents in the layers below
+	// are hidden.
+	opaqueWhiteout = ".wh..wh..opq"
+)
+
+type Options struct {
+	// Include, Exclude and MaxFileSize select the files of the image that
+	// are parsed. Files are read into memory, so MaxFileSize is at most
+	// archive.DefaultMaxEntrySize.
+	walk.Options
+	// Password is tried on encrypted private keys.
+	Password string
+}
+
+func (o Options) maxFileSize() int64 {
+	if o.MaxFileSize > 0 && o.MaxFileSize < archive.DefaultMaxEntrySize {
+		return o.MaxFileSize
+	}
+	return archive.DefaultMaxEntrySize
+}
+
+type Layer struct {
+	// Number counts the layers from 1, the base layer.
+	Number    int
+	Digest    string
+	CreatedBy string `json:",omitempty"`
+	// Files is the number of files of the final filesystem that come from
+	// the layer.
+	Files int
+}
+
+// Certificate is a certificate of the final filesystem and the layer that
+// added the file holding it.
+type Certificate struct {
+	Layer int
+	certificate.CertificateSummary
+}
+
+type Key struct {
+	Layer int
+	privatekey.KeySummary
+}
+
+type Result struct {
+	Image  string
+	Layers []Layer
+	// Files is the number of regular files in the final filesystem.
+	Files        int
+	Certificates []Certificate
+	Keys         []Key
+	// Diagnostics are the files that look like certificates or keys but
+	// could not be parsed, and those over the size limit.
+	Diagnostics []diag.Diagnostic `json:",omitempty"`
+}
+
+// Scan reads the images of the docker save tarball, gzipped or not, or OCI
+// image layout, directory or tarball, at filePath.
+func Scan(filePath string, opts Options) ([]*Result, error) {
+	return ScanContext(context.Background(), filePath, opts)
+}
+
+// ScanContext is Scan stopping when ctx is done.
+func ScanContext(ctx context.Context, filePath string, opts Options) ([]*Result, error) {
+	s, err := openStore(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	images, err := findImages(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	var results []*Result
+	for _, img := range images {
+		r, err := scanImage(ctx, s, img, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", filePath, img.name, err)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// origin is where a file of the final filesystem comes from: the index of
+// its layer and, for a hard link, the file it links to in that layer.
+type origin struct {
+	layer int
+	link  string
+}
+
+func scanImage(ctx context.Context, s store, img imageRef, opts Options) (*Result, error) {
+	var config imageConfig
+	if err := readJSON(s, img.config, &config); err != nil {
+		return nil, err
+	}
+
+	r := &Result{Image: img.name}
+	var history []historyEntry
+	for _, h := range config.History {
+		if !h.EmptyLayer {
+			history = append(history, h)
+		}
+	}
+	for i, l := range img.layers {
+		layer := Layer{Number: i + 1, Digest: l.digest}
+		if !strings.HasPrefix(layer.Digest, "sha256:") && i < len(config.RootFS.DiffIDs) {
+			layer.Digest = config.RootFS.DiffIDs[i]
+		}
+		if i < len(history) {
+			layer.CreatedBy = history[i].CreatedBy
+		}
+		r.Layers = append(r.Layers, layer)
+	}
+
+	files := make(map[string]origin)
+	for i, l := range img.layers {
+		if err := applyLayer(ctx, s, l, i, files); err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i+1, err)
+		}
+	}
+	r.Files = len(files)
+
+	// wanted maps, for each layer, the entries to read to the paths of the
+	// final filesystem they hold.
+	wanted := make([]map[string][]string, len(img.layers))
+	for name, o := range files {
+		r.Layers[o.layer].Files++
+		if !opts.Selects(name) {
+			continue
+		}
+		if wanted[o.layer] == nil {
+			wanted[o.layer] = make(map[string][]string)
+		}
+		entry := name
+		if o.link != "" {
+			entry = o.link
+		}
+		wanted[o.layer][entry] = append(wanted[o.layer][entry], name)
+	}
+	for i, l := range img.layers {
+		if wanted[i] == nil {
+			continue
+		}
+		if err := r.scanLayer(ctx, s, l, i, wanted[i], opts); err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i+1, err)
+		}
+	}
+
+	slices.SortStableFunc(r.Certificates, func(a, b Certificate) int {
+		return cmp.Or(strings.Compare(a.Filename, b.Filename), cmp.Compare(a.Index, b.Index))
+	})
+	slices.SortStableFunc(r.Keys, func(a, b Key) int { return strings.Compare(a.Filename, b.Filename) })
+	slices.SortStableFunc(r.Diagnostics, func(a, b diag.Diagnostic) int { return strings.Compare(a.Path, b.Path) })
+	return r, nil
+}
+
+// eachEntry calls visit for every entry of a layer.
+func eachEntry(ctx context.Context, s store, l layerRef, visit func(name string, hdr *tar.Header, r io.Reader) error) error {
+	rc, err := openLayer(s, l.blob)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := cleanName(hdr.Name)
+		if name == "" {
+			continue
+		}
+		if err := visit(name, hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// applyLayer updates files, the regular files of the filesystem so far,
+// with the layer at index. Only whiteouts and opaque markers hide the paths
+// below them; any other entry replaces a lower file at its own path only,
+// so the directory entries a layer carries for its parents hide nothing.
+// Deletions apply to the layers below only, so a layer can hide a
+// directory and then fill it again.
+func applyLayer(ctx context.Context, s store, l layerRef, index int, files map[string]origin) error {
+	added := make(map[string]origin)
+	// deleted are the whiteouts, which hide a path and everything below it;
+	// replaced are the paths taken by an entry of this layer.
+	deleted := make(map[string]bool)
+	replaced := make(map[string]bool)
+	opaque := make(map[string]bool)
+	err := eachEntry(ctx, s, l, func(name string, hdr *tar.Header, _ io.Reader) error {
+		dir, base := path.Dir(name), path.Base(name)
+		switch {
+		case base == opaqueWhiteout:
+			opaque[dir] = true
+		case strings.HasPrefix(base, whiteoutPrefix):
+			deleted[path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))] = true
+		case hdr.Typeflag == tar.TypeLink:
+			target := cleanName(hdr.Linkname)
+			if o, ok := added[target]; ok {
+				added[name] = origin{layer: index, link: cmp.Or(o.link, target)}
+			}
+			replaced[name] = true
+		case hdr.FileInfo().Mode().IsRegular():
+			added[name] = origin{layer: index}
+		default:
+			delete(added, name)
+			replaced[name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) > 0 || len(replaced) > 0 || len(opaque) > 0 {
+		for name := range files {
+			if deleted[name] || replaced[name] {
+				delete(files, name)
+				continue
+			}
+			for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+				if deleted[dir] || opaque[dir] {
+					delete(files, name)
+					break
+				}
+			}
+		}
+		// Files at the root are hidden by an opaque root.
+		if opaque["."] {
+			clear(files)
+		}
+	}
+	for name, o := range added {
+		files[name] = o
+	}
+	return nil
+}
+
+// scanLayer reads the wanted entries of the layer at index and parses the
+// files that may hold certificates or keys.
+func (r *Result) scanLayer(ctx context.Context, s store, l layerRef, index int, wanted map[string][]string, opts Options) error {
+	type file struct {
+		data []byte
+		err  error
+	}
+	// A path can appear more than once in a layer; the last entry wins.
+	found := make(map[string]file)
+	limit := opts.maxFileSize()
+	err := eachEntry(ctx, s, l, func(name string, hdr *tar.Header, tr io.Reader) error {
+		paths := wanted[name]
+		if len(paths) == 0 || !hdr.FileInfo().Mode().IsRegular() {
+			return nil
+		}
+		var f file
+		if hdr.Size > limit {
+			f.err = fmt.Errorf("%w: %d bytes", walk.ErrTooLarge, hdr.Size)
+		} else {
+			data, err := io.ReadAll(io.LimitReader(tr, limit+1))
+			if err != nil {
+				return err
+			}
+			if int64(len(data)) > limit {
+				f.err = fmt.Errorf("%w: over %d bytes", walk.ErrTooLarge, limit)
+			} else {
+				f.data = data
+			}
+		}
+		for _, p := range paths {
+			if f.err != nil || isCandidate(p, f.data) {
+				found[p] = f
+			} else {
+				delete(found, p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, f := range found {
+		if f.err != nil {
+			r.Diagnostics = append(r.Diagnostics, diag.ForPath("/"+name, f.err))
+			continue
+		}
+		r.parse(index+1, "/"+name, f.data, opts.Password)
+	}
+	return nil
+}
+
+// candidateExtensions are the file extensions of certificates and keys,
+// which are parsed even without a PEM header, as DER.
+var candidateExtensions = map[string]bool{
+	".pem": true, ".crt": true, ".cer": true, ".cert": true, ".der": true, ".key": true,
+}
+
+// isCandidate reports whether a file of the image may hold a certificate or
+// a key. Checking every binary of an image for DER would only add noise.
+func isCandidate(name string, data []byte) bool {
+	return candidateExtensions[strings.ToLower(path.Ext(name))] || bytes.Contains(data, []byte("-----BEGIN "))
+}
+
+// parse adds the certificates and key of a file, or the diagnostic of why
+// it holds neither.
+func (r *Result) parse(layer int, filename string, data []byte, password string) {
+	certs, certErr := certificate.ParseCertificatesFromBytes(data, filename)
+	for _, cert := range certs {
+		r.Certificates = append(r.Certificates, Certificate{Layer: layer, CertificateSummary: certificate.NewCertificateSummary(filename, cert)})
+	}
+
+	key, keyErr := privatekey.ParsePrivateKeyFromBytes(data, filename, password)
+	if keyErr == nil && key.Algorithm == "Unknown" {
+		keyErr = fmt.Errorf("unsupported private key algorithm in %s", filename)
+	}
+	if keyErr == nil {
+		r.Keys = append(r.Keys, Key{Layer: layer, KeySummary: privatekey.NewKeySummary(filename, key)})
+	}
+
+	if certErr != nil && keyErr != nil {
+		err := certErr
+		if pem.Classify(pem.Detect(data)) == pem.KindPrivateKey {
+			err = keyErr
+		}
+		r.Diagnostics = append(r.Diagnostics, diag.ForFile(filename, data, err, pem.KindCertificate, pem.KindPrivateKey))
+	}
+}
@@ -0,0 +1,301 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/walk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestFile(t testing.TB, relPath string) []byte {
+	data, err := os.ReadFile(filepath.Join("..", "..", "test_certs", relPath))
+	require.NoError(t, err)
+	return data
+}
+
+// entry is a file of a layer to build; link makes it a hard link and dir a
+// directory.
+type entry struct {
+	name string
+	data []byte
+	link string
+	dir  bool
+}
+
+func tarData(t testing.TB, entries ...entry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(e.data))}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Typeflag: tar.TypeLink, Linkname: e.link}
+		}
+		if e.dir {
+			hdr = &tar.Header{Name: e.name, Typeflag: tar.TypeDir, Mode: 0755}
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write(e.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipData(t testing.TB, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func jsonData(t testing.TB, v any) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// testLayers builds three layers: the second deletes a key of the first, and
+// the third hides the /opt/app of the layers below and adds a key to it.
+func testLayers(t testing.TB) [][]byte {
+	return [][]byte{
+		tarData(t,
+			entry{name: "./etc/ssl/certs/ca.pem", data: readTestFile(t, "traditional/rsa/ca-rsa2048.crt")},
+			entry{name: "etc/ssl/certs/linked.pem", link: "etc/ssl/certs/ca.pem"},
+			entry{name: "etc/ssl/private/old.key", data: readTestFile(t, "traditional/ecdsa/server-ecdsa-p256.key")},
+			entry{name: "opt/app/tls.key", data: readTestFile(t, "traditional/rsa/ca-rsa2048.key")},
+			entry{name: "usr/bin/tool", data: []byte("\x7fELF binary")},
+		),
+		gzipData(t, tarData(t,
+			entry{name: "etc/ssl/private/.wh.old.key"},
+			entry{name: "opt/app/server.crt", data: readTestFile(t, "traditional/ecdsa/server-ecdsa-p256.crt")},
+			entry{name: "etc/notes.txt", data: []byte("-----BEGIN NOTHING-----\n")},
+		)),
+		tarData(t,
+			entry{name: "opt/app/.wh..wh..opq"},
+			entry{name: "opt/app/new.key", data: readTestFile(t, "traditional/ecdsa/server-ed25519.key")},
+			entry{name: "etc/ssl/certs/large.pem", data: bytes.Repeat([]byte("x"), 4096)},
+		),
+	}
+}
+
+var testConfig = map[string]any{
+	"history": []map[string]any{
+		{"created_by": "ADD rootfs.tar /"},
+		{"created_by": "ENV PATH=/usr/bin", "empty_layer": true},
+		{"created_by": "COPY app /opt/app"},
+		{"created_by": "RUN install-app"},
+	},
+}
+
+func writeFiles(t testing.TB, dir string, files map[string][]byte) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, data, 0644))
+	}
+}
+
+func tarFiles(t testing.TB, files map[string][]byte) []byte {
+	var entries []entry
+	for name, data := range files {
+		entries = append(entries, entry{name: name, data: data})
+	}
+	return tarData(t, entries...)
+}
+
+func dockerSave(t testing.TB, layers [][]byte) map[string][]byte {
+	files := map[string][]byte{"config.json": jsonData(t, testConfig)}
+	manifest := dockerManifest{Config: "config.json", RepoTags: []string{"example/app:1.0"}}
+	for i, l := range layers {
+		name := string(rune('a'+i)) + "/layer.tar"
+		files[name] = l
+		manifest.Layers = append(manifest.Layers, name)
+	}
+	files["manifest.json"] = jsonData(t, []dockerManifest{manifest})
+	return files
+}
+
+// ociLayout builds an image index for linux/amd64, with an attestation
+// manifest that is skipped.
+func ociLayout(t testing.TB, layers [][]byte) map[string][]byte {
+	files := make(map[string][]byte)
+	blob := func(data []byte) map[string]any {
+		d := digest(data)
+		files["blobs/sha256/"+d[len("sha256:"):]] = data
+		return map[string]any{"digest": d, "size": len(data)}
+	}
+
+	manifest := map[string]any{"config": blob(jsonData(t, testConfig))}
+	var layerDescs []map[string]any
+	for _, l := range layers {
+		layerDescs = append(layerDescs, blob(l))
+	}
+	manifest["layers"] = layerDescs
+
+	amd64 := blob(jsonData(t, manifest))
+	amd64["platform"] = map[string]string{"os": "linux", "architecture": "amd64"}
+	attestation := blob(jsonData(t, map[string]any{"config": blob([]byte("{}"))}))
+	attestation["platform"] = map[string]string{"os": "unknown", "architecture": "unknown"}
+	index := blob(jsonData(t, map[string]any{"manifests": []any{amd64, attestation}}))
+	index["annotations"] = map[string]string{"io.containerd.image.name": "example/app:1.0"}
+
+	files["oci-layout"] = []byte(`{"imageLayoutVersion":"1.0.0"}`)
+	files["index.json"] = jsonData(t, map[string]any{"manifests": []any{index}})
+	return files
+}
+
+func assertTestImage(t *testing.T, r *Result) {
+	t.Helper()
+	require.Len(t, r.Layers, 3)
+	assert.Equal(t, "ADD rootfs.tar /", r.Layers[0].CreatedBy)
+	assert.Equal(t, "RUN install-app", r.Layers[2].CreatedBy, "empty history entries do not count")
+	assert.Equal(t, 6, r.Files)
+	assert.Equal(t, []int{3, 1, 2}, []int{r.Layers[0].Files, r.Layers[1].Files, r.Layers[2].Files})
+
+	require.Len(t, r.Certificates, 2)
+	assert.Equal(t, "/etc/ssl/certs/ca.pem", r.Certificates[0].Filename)
+	assert.Equal(t, 1, r.Certificates[0].Layer)
+	assert.Equal(t, "/etc/ssl/certs/linked.pem", r.Certificates[1].Filename, "hard links are read from their target")
+	assert.Equal(t, r.Certificates[0].SHA256, r.Certificates[1].SHA256)
+
+	require.Len(t, r.Keys, 1, "the whiteout and the opaque directory hide the other keys")
+	assert.Equal(t, "/opt/app/new.key", r.Keys[0].Filename)
+	assert.Equal(t, 3, r.Keys[0].Layer)
+
+	require.Len(t, r.Diagnostics, 2)
+	assert.Equal(t, "/etc/notes.txt", r.Diagnostics[0].Path)
+	assert.False(t, r.Diagnostics[0].IsWarning())
+	assert.Equal(t, "/etc/ssl/certs/large.pem", r.Diagnostics[1].Path)
+	assert.Equal(t, diag.Unrecognized, r.Diagnostics[1].Category, "a .pem file is parsed without a PEM header")
+}
+
+func TestScanDockerSave(t *testing.T) {
+	files := dockerSave(t, testLayers(t))
+	path := filepath.Join(t.TempDir(), "app.tar")
+	require.NoError(t, os.WriteFile(path, tarFiles(t, files), 0644))
+
+	results, err := Scan(path, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "example/app:1.0", results[0].Image)
+	assert.Equal(t, "a/layer.tar", results[0].Layers[0].Digest, "without diff IDs the layer is named by its path")
+	assertTestImage(t, results[0])
+
+	gzipped := filepath.Join(t.TempDir(), "app.tar.gz")
+	require.NoError(t, os.WriteFile(gzipped, gzipData(t, tarFiles(t, files)), 0644))
+	results, err = Scan(gzipped, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assertTestImage(t, results[0])
+}
+
+func TestScanOCILayout(t *testing.T) {
+	layers := testLayers(t)
+	files := ociLayout(t, layers)
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+
+	results, err := Scan(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "example/app:1.0 (linux/amd64)", results[0].Image)
+	assert.Equal(t, digest(layers[1]), results[0].Layers[1].Digest)
+	assertTestImage(t, results[0])
+
+	path := filepath.Join(t.TempDir(), "app-oci.tar")
+	require.NoError(t, os.WriteFile(path, tarFiles(t, files), 0644))
+	results, err = Scan(path, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assertTestImage(t, results[0])
+}
+
+func TestScanDirectoryEntries(t *testing.T) {
+	// Layers carry the directories of their files; they hide nothing below
+	// them, but a directory does replace a file at its own path.
+	layers := [][]byte{
+		tarData(t,
+			entry{name: "etc/", dir: true},
+			entry{name: "etc/ssl/", dir: true},
+			entry{name: "etc/ssl/a.pem", data: readTestFile(t, "traditional/rsa/ca-rsa2048.crt")},
+			entry{name: "etc/ssl/old.pem", data: readTestFile(t, "traditional/ecdsa/server-ecdsa-p256.crt")},
+		),
+		tarData(t,
+			entry{name: "etc/", dir: true},
+			entry{name: "etc/ssl/", dir: true},
+			entry{name: "etc/ssl/b.pem", data: readTestFile(t, "traditional/ecdsa/server-ecdsa-p256.crt")},
+			entry{name: "etc/ssl/old.pem/", dir: true},
+		),
+	}
+	path := filepath.Join(t.TempDir(), "dirs.tar")
+	require.NoError(t, os.WriteFile(path, tarFiles(t, dockerSave(t, layers)), 0644))
+
+	results, err := Scan(path, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	r := results[0]
+	assert.Equal(t, 2, r.Files)
+	require.Len(t, r.Certificates, 2)
+	assert.Equal(t, "/etc/ssl/a.pem", r.Certificates[0].Filename)
+	assert.Equal(t, 1, r.Certificates[0].Layer)
+	assert.Equal(t, "/etc/ssl/b.pem", r.Certificates[1].Filename)
+	assert.Equal(t, 2, r.Certificates[1].Layer)
+}
+
+func TestScanOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, ociLayout(t, testLayers(t)))
+
+	results, err := Scan(dir, Options{Options: walk.Options{Exclude: []string{"/etc/ssl/**"}, MaxFileSize: 2048}})
+	require.NoError(t, err)
+	r := results[0]
+	assert.Empty(t, r.Certificates)
+	require.Len(t, r.Keys, 1)
+	require.Len(t, r.Diagnostics, 1)
+	assert.Equal(t, "/etc/notes.txt", r.Diagnostics[0].Path)
+
+	results, err = Scan(dir, Options{Options: walk.Options{MaxFileSize: 2048}})
+	require.NoError(t, err)
+	require.Len(t, results[0].Diagnostics, 2)
+	assert.Equal(t, diag.Skipped, results[0].Diagnostics[1].Category)
+}
+
+func TestScanErrors(t *testing.T) {
+	_, err := Scan(filepath.Join(t.TempDir(), "missing.tar"), Options{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Scan(t.TempDir(), Options{})
+	assert.ErrorContains(t, err, "no manifest.json or index.json")
+
+	files := dockerSave(t, testLayers(t))
+	delete(files, "b/layer.tar")
+	path := filepath.Join(t.TempDir(), "broken.tar")
+	require.NoError(t, os.WriteFile(path, tarFiles(t, files), 0644))
+	_, err = Scan(path, Options{})
+	assert.ErrorContains(t, err, "layer 2")
+
+	dir := t.TempDir()
+	writeFiles(t, dir, ociLayout(t, testLayers(t)))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ScanContext(ctx, dir, Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
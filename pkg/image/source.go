@@ -0,0 +1,375 @@
+package image
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// cleanName returns the slash-separated path of a tar entry or manifest
+// reference relative to the root, without "./" or ".." elements, or "" for
+// the root itself.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// store reads the files of an image: the manifests, configs and layer blobs.
+type store interface {
+	open(name string) (io.ReadCloser, error)
+	Close() error
+}
+
+// dirStore is an OCI image layout directory.
+type dirStore string
+
+func (d dirStore) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(cleanName(name))))
+}
+
+func (d dirStore) Close() error { return nil }
+
+// tarStore is a docker save tarball, or an OCI image layout in a tarball.
+// The entries are read in place, so a layer can be read more than once
+// without extracting the tarball.
+type tarStore struct {
+	f       *os.File
+	entries map[string]section
+	// temp is set when f is the tarball uncompressed to a temporary file.
+	temp bool
+}
+
+type section struct {
+	offset, size int64
+}
+
+// openTarStore indexes the regular files of the tarball at filePath. A
+// gzipped tarball is uncompressed to a temporary file first.
+func openTarStore(filePath string) (*tarStore, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s := &tarStore{f: f, entries: make(map[string]section)}
+
+	magic := make([]byte, len(gzipMagic))
+	_, err = io.ReadFull(f, magic)
+	if err == nil && bytes.Equal(magic, gzipMagic) {
+		err = s.uncompress()
+	} else {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		err = s.index()
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *tarStore) uncompress() error {
+	src := s.f
+	defer src.Close()
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tmp, err := os.CreateTemp("", "certinfo-image-*.tar")
+	if err != nil {
+		return err
+	}
+	s.f, s.temp = tmp, true
+	if _, err := io.Copy(tmp, gz); err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	return err
+}
+
+// index records where the data of every regular file starts. tar.Reader
+// does not read ahead, so after Next the file is at the start of the data.
+func (s *tarStore) index() error {
+	tr := tar.NewReader(s.f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading image tarball: %w", err)
+		}
+		if !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		offset, err := s.f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		s.entries[cleanName(hdr.Name)] = section{offset, hdr.Size}
+	}
+}
+
+func (s *tarStore) open(name string) (io.ReadCloser, error) {
+	e, ok := s.entries[cleanName(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return io.NopCloser(io.NewSectionReader(s.f, e.offset, e.size)), nil
+}
+
+func (s *tarStore) Close() error {
+	err := s.f.Close()
+	if s.temp {
+		os.Remove(s.f.Name())
+	}
+	return err
+}
+
+func openStore(filePath string) (store, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return dirStore(filePath), nil
+	}
+	return openTarStore(filePath)
+}
+
+func readJSON(s store, name string, v any) error {
+	rc, err := s.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// openLayer returns a reader of the uncompressed layer tarball.
+func openLayer(s store, name string) (io.ReadCloser, error) {
+	rc, err := s.open(name)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(rc)
+	magic, _ := br.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gz, rc}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		rc.Close()
+		return nil, errors.New("zstd-compressed layers are not supported")
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, rc}, nil
+}
+
+// imageRef is an image found in a store: its config and its layers from
+// the base up.
+type imageRef struct {
+	name   string
+	config string
+	layers []layerRef
+}
+
+type layerRef struct {
+	blob   string
+	digest string
+}
+
+// dockerManifest is an entry of the manifest.json of a docker save
+// tarball.
+type dockerManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+// ociManifest decodes both image manifests and image indexes, which list
+// the manifests of several platforms.
+type ociManifest struct {
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+type imageConfig struct {
+	RootFS struct {
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []historyEntry `json:"history"`
+}
+
+// historyEntry is a build step of an image; the steps that do not add a
+// layer are marked empty.
+type historyEntry struct {
+	CreatedBy  string `json:"created_by"`
+	EmptyLayer bool   `json:"empty_layer"`
+}
+
+var digestPattern = regexp.MustCompile(`^([a-z0-9]+):([a-f0-9]+)$`)
+
+// blobPath returns the path of a blob in an OCI image layout.
+func blobPath(digest string) (string, error) {
+	m := digestPattern.FindStringSubmatch(digest)
+	if m == nil {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return "blobs/" + m[1] + "/" + m[2], nil
+}
+
+// findImages lists the images of a store, from the manifest.json of docker
+// save or else from the index.json of an OCI image layout.
+func findImages(s store) ([]imageRef, error) {
+	var manifests []dockerManifest
+	err := readJSON(s, "manifest.json", &manifests)
+	if err == nil {
+		return dockerImages(manifests), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var index ociManifest
+	if err := readJSON(s, "index.json", &index); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("not a docker save tarball or OCI image layout: no manifest.json or index.json")
+		}
+		return nil, err
+	}
+	var images []imageRef
+	if err := ociImages(s, index.Manifests, "", 0, &images); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func dockerImages(manifests []dockerManifest) []imageRef {
+	images := make([]imageRef, 0, len(manifests))
+	for _, m := range manifests {
+		img := imageRef{name: strings.Join(m.RepoTags, ", "), config: m.Config}
+		if img.name == "" {
+			img.name = strings.TrimSuffix(path.Base(m.Config), ".json")
+		}
+		for _, l := range m.Layers {
+			// Recent versions of docker save layers as OCI blobs, older
+			// ones as <id>/layer.tar.
+			digest := l
+			if dir, hex := path.Split(cleanName(l)); strings.HasPrefix(dir, "blobs/") {
+				digest = path.Base(dir) + ":" + hex
+			}
+			img.layers = append(img.layers, layerRef{blob: l, digest: digest})
+		}
+		images = append(images, img)
+	}
+	return images
+}
+
+// maxIndexDepth bounds the image indexes followed from index.json.
+const maxIndexDepth = 4
+
+func ociImages(s store, descs []descriptor, name string, depth int, images *[]imageRef) error {
+	if depth > maxIndexDepth {
+		return errors.New("image indexes nested too deep")
+	}
+	for _, d := range descs {
+		// Attestations and other artifacts are listed for an unknown
+		// platform.
+		if d.Platform != nil && d.Platform.OS == "unknown" {
+			continue
+		}
+		blob, err := blobPath(d.Digest)
+		if err != nil {
+			return err
+		}
+		var m ociManifest
+		if err := readJSON(s, blob, &m); err != nil {
+			return err
+		}
+
+		imgName := name
+		if imgName == "" {
+			imgName = d.Annotations["io.containerd.image.name"]
+		}
+		if imgName == "" {
+			imgName = d.Annotations["org.opencontainers.image.ref.name"]
+		}
+		if imgName == "" {
+			imgName = d.Digest
+		}
+
+		if len(m.Manifests) > 0 {
+			if err := ociImages(s, m.Manifests, imgName, depth+1, images); err != nil {
+				return err
+			}
+			continue
+		}
+		if p := d.Platform; p != nil && name != "" {
+			platform := p.OS + "/" + p.Architecture
+			if p.Variant != "" {
+				platform += "/" + p.Variant
+			}
+			imgName += " (" + platform + ")"
+		}
+
+		config, err := blobPath(m.Config.Digest)
+		if err != nil {
+			return err
+		}
+		img := imageRef{name: imgName, config: config}
+		for _, l := range m.Layers {
+			blob, err := blobPath(l.Digest)
+			if err != nil {
+				return err
+			}
+			img.layers = append(img.layers, layerRef{blob: blob, digest: l.Digest})
+		}
+		*images = append(*images, img)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package match
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+)
+
+type Result struct {
+	CertFile   string
+	KeyFile    string
+	CommonName string
+	KeyType    string
+	CertSPKI   string
+	KeySPKI    string
+	Match      bool
+}
+
+type Pair struct {
+	CertFile   string
+	KeyFile    string
+	CommonName string
+	KeyType    string
+	Match      bool
+}
+
+type DirectoryResult struct {
+	Directory       string
+	Pairs           []Pair
+	OrphanKeys      []string
+	CertsWithoutKey []string
+	UncheckedKeys   []string
+	// Diagnostics are the files that hold neither a certificate nor a key
+	// that could be paired.
+	Diagnostics []diag.Diagnostic `json:",omitempty"`
+}
+
+// Mismatches returns the number of certificate and key files that share a
+// name but hold different public keys.
+func (r *DirectoryResult) Mismatches() int {
+	count := 0
+	for _, p := range r.Pairs {
+		if !p.Match {
+			count++
+		}
+	}
+	return count
+}
+
+// Check compares the public key of the private key with the certificates of a
+// file. The first certificate carrying the same public key is reported; when
+// none does, the result describes the first certificate.
+func Check(certs []*certificate.CertificateInfo, key *privatekey.KeyInfo) (*Result, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate to compare")
+	}
+	if key.SPKISHA256 == "" {
+		return nil, fmt.Errorf("cannot derive the public key of %s key %s", key.KeyType, key.Filename)
+	}
+
+	cert := certs[0]
+	for _, c := range certs {
+		if c.SPKISHA256 == key.SPKISHA256 {
+			cert = c
+			break
+		}
+	}
+
+	return &Result{
+		CertFile:   cert.Filename,
+		KeyFile:    key.Filename,
+		CommonName: cert.CommonName,
+		KeyType:    key.KeyType,
+		CertSPKI:   cert.SPKISHA256,
+		KeySPKI:    key.SPKISHA256,
+		Match:      cert.SPKISHA256 == key.SPKISHA256,
+	}, nil
+}
+
+func stem(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func certDisplayName(s certificate.CertificateSummary, counts map[string]int) string {
+	if counts[s.Filename] > 1 {
+		return fmt.Sprintf("%s[%d]", s.Filename, s.Index+1)
+	}
+	return s.Filename
+}
+
+func newPair(c certificate.CertificateSummary, key privatekey.KeySummary, counts map[string]int, match bool) Pair {
+	return Pair{
+		CertFile:   certDisplayName(c, counts),
+		KeyFile:    key.Filename,
+		CommonName: c.CommonName,
+		KeyType:    key.KeyType,
+		Match:      match,
+	}
+}
+
+// Directory pairs every certificate and private key found below dirPath by
+// public key. Keys whose public key cannot be derived are listed as
+// unchecked. Files that share a name (server.crt and server.key) but hold
+// different public keys are reported as mismatched pairs; keys without a
+// certificate and certificates without a key are listed separately.
+func Directory(dirPath string, password ...string) (*DirectoryResult, error) {
+	certs, certDiags, err := certificate.SummarizeDirectoryRecursive(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	keys, keyDiags, err := privatekey.SummarizeDirectoryRecursive(dirPath, password...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DirectoryResult{Directory: dirPath}
+
+	used := make(map[string]bool)
+	counts := make(map[string]int)
+	for _, c := range certs {
+		counts[c.Filename]++
+		used[c.Filename] = true
+	}
+	for _, k := range keys {
+		used[k.Filename] = true
+	}
+	result.Diagnostics = diag.Merge(used, certDiags, keyDiags)
+
+	certPaired := make([]bool, len(certs))
+	keyPaired := make([]bool, len(keys))
+	for k, key := range keys {
+		if key.SPKISHA256 == "" {
+			continue
+		}
+		for i, c := range certs {
+			if c.SPKISHA256 != key.SPKISHA256 {
+				continue
+			}
+			result.Pairs = append(result.Pairs, newPair(c, key, counts, true))
+			certPaired[i] = true
+			keyPaired[k] = true
+		}
+	}
+
+	for k, key := range keys {
+		if key.SPKISHA256 == "" {
+			result.UncheckedKeys = append(result.UncheckedKeys, key.Filename)
+			continue
+		}
+		if keyPaired[k] {
+			continue
+		}
+		for i, c := range certs {
+			if certPaired[i] || stem(c.Filename) != stem(key.Filename) {
+				continue
+			}
+			result.Pairs = append(result.Pairs, newPair(c, key, counts, false))
+			certPaired[i] = true
+			keyPaired[k] = true
+		}
+		if !keyPaired[k] {
+			result.OrphanKeys = append(result.OrphanKeys, key.Filename)
+		}
+	}
+
+	for i, c := range certs {
+		if !certPaired[i] {
+			result.CertsWithoutKey = append(result.CertsWithoutKey, certDisplayName(c, counts))
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package match
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestCertPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0600))
+}
+
+func TestCheck(t *testing.T) {
+	certs, err := certificate.ParseCertificates(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+
+	key, err := privatekey.ParsePrivateKey(getTestCertPath("chain/server.key"))
+	require.NoError(t, err)
+	result, err := Check(certs, key)
+	require.NoError(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, "localhost", result.CommonName)
+	assert.Equal(t, result.CertSPKI, result.KeySPKI)
+
+	other, err := privatekey.ParsePrivateKey(getTestCertPath("chain/root-ca.key"))
+	require.NoError(t, err)
+	result, err = Check(certs, other)
+	require.NoError(t, err)
+	assert.False(t, result.Match)
+}
+
+func TestCheckBundle(t *testing.T) {
+	certs, err := certificate.ParseCertificates(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err)
+	key, err := privatekey.ParsePrivateKey(getTestCertPath("chain/intermediate-ca.key"))
+	require.NoError(t, err)
+
+	result, err := Check(certs, key)
+	require.NoError(t, err)
+	assert.True(t, result.Match)
+	assert.Equal(t, "Test Intermediate CA", result.CommonName)
+}
+
+func TestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	copyFile(t, getTestCertPath("chain/server.crt"), filepath.Join(dir, "server.crt"))
+	copyFile(t, getTestCertPath("chain/server.key"), filepath.Join(dir, "server.key"))
+	copyFile(t, getTestCertPath("traditional/rsa/server-rsa2048.crt"), filepath.Join(dir, "web.crt"))
+	copyFile(t, getTestCertPath("chain/root-ca.key"), filepath.Join(dir, "web.key"))
+	copyFile(t, getTestCertPath("chain/intermediate-ca.key"), filepath.Join(dir, "orphan.key"))
+	copyFile(t, getTestCertPath("traditional/rsa/ca-rsa2048.crt"), filepath.Join(dir, "ca.crt"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.crt"), []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n"), 0644))
+
+	result, err := Directory(dir)
+	require.NoError(t, err)
+
+	require.Len(t, result.Pairs, 2)
+	assert.Equal(t, Pair{CertFile: "server.crt", KeyFile: "server.key", CommonName: "localhost", KeyType: "RSA", Match: true}, result.Pairs[0])
+	assert.Equal(t, "web.crt", result.Pairs[1].CertFile)
+	assert.False(t, result.Pairs[1].Match)
+	assert.Equal(t, 1, result.Mismatches())
+	assert.Equal(t, []string{"orphan.key"}, result.OrphanKeys)
+	assert.Equal(t, []string{"ca.crt"}, result.CertsWithoutKey)
+
+	warnings := diag.Warnings(result.Diagnostics)
+	require.Len(t, warnings, 1, "the files that were paired are not reported")
+	assert.Equal(t, "broken.crt", warnings[0].Path)
+}
+
+func TestDirectoryBundle(t *testing.T) {
+	result, err := Directory(getTestCertPath("chain"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.Mismatches())
+	assert.Empty(t, result.OrphanKeys)
+	assert.Contains(t, result.Pairs, Pair{CertFile: "fullchain.crt[1]", KeyFile: "server.key", CommonName: "localhost", KeyType: "RSA", Match: true})
+	assert.Contains(t, result.Pairs, Pair{CertFile: "fullchain.crt[2]", KeyFile: "intermediate-ca.key", CommonName: "Test Intermediate CA", KeyType: "RSA", Match: true})
+	assert.Empty(t, result.CertsWithoutKey)
+}
@@ -0,0 +1,214 @@
+package ocsp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/crl"
+	"golang.org/x/crypto/ocsp"
+)
+
+const maxResponseSize = 1 << 20
+
+const defaultTimeout = 10 * time.Second
+
+type ResponseInfo struct {
+	Source             string
+	CommonName         string
+	SerialNumber       string
+	Status             string
+	RevokedAt          time.Time
+	RevocationReason   string
+	ProducedAt         time.Time
+	ThisUpdate         time.Time
+	NextUpdate         time.Time
+	ResponderID        string
+	SignatureAlgorithm string
+	SignatureChecked   bool
+	SignatureValid     bool
+	SignatureError     string
+}
+
+type Options struct {
+	IssuerPath string
+	URL        string
+	Timeout    time.Duration
+}
+
+func statusString(status int) string {
+	switch status {
+	case ocsp.Good:
+		return certificate.RevocationGood
+	case ocsp.Revoked:
+		return certificate.RevocationRevoked
+	default:
+		return certificate.RevocationUnknown
+	}
+}
+
+func responderID(resp *ocsp.Response) string {
+	if len(resp.RawResponderName) > 0 {
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(resp.RawResponderName, &rdn); err == nil {
+			var name pkix.Name
+			name.FillFromRDNSequence(&rdn)
+			return name.String()
+		}
+	}
+	if len(resp.ResponderKeyHash) > 0 {
+		return "KeyHash:" + hex.EncodeToString(resp.ResponderKeyHash)
+	}
+	return ""
+}
+
+// DecodeResponse decodes a DER OCSP response. The signature is checked
+// against issuer, or against the delegated responder certificate embedded in
+// the response when that certificate is signed by issuer.
+func DecodeResponse(der []byte, issuer *x509.Certificate, source string) (*ResponseInfo, error) {
+	resp, err := ocsp.ParseResponse(der, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ResponseInfo{
+		Source:             source,
+		SerialNumber:       resp.SerialNumber.String(),
+		Status:             statusString(resp.Status),
+		ProducedAt:         resp.ProducedAt,
+		ThisUpdate:         resp.ThisUpdate,
+		NextUpdate:         resp.NextUpdate,
+		ResponderID:        responderID(resp),
+		SignatureAlgorithm: resp.SignatureAlgorithm.String(),
+	}
+	if resp.Status == ocsp.Revoked {
+		info.RevokedAt = resp.RevokedAt
+		info.RevocationReason = crl.ReasonString(resp.RevocationReason)
+	}
+
+	if issuer == nil {
+		info.SignatureError = "issuer certificate not supplied"
+		return info, nil
+	}
+	info.SignatureChecked = true
+	if _, err := ocsp.ParseResponse(der, issuer); err != nil {
+		info.SignatureError = err.Error()
+	} else {
+		info.SignatureValid = true
+	}
+
+	return info, nil
+}
+
+func loadIssuer(path string) (*x509.Certificate, error) {
+	certs, err := certificate.LoadX509Certificates(path)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// ParseResponseFile decodes a saved or stapled DER OCSP response. The
+// signature is only verified when issuerPath is set.
+func ParseResponseFile(filePath string, issuerPath string) (*ResponseInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuer *x509.Certificate
+	if issuerPath != "" {
+		issuer, err = loadIssuer(issuerPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return DecodeResponse(data, issuer, filePath)
+}
+
+// Query sends an OCSP request for the first certificate of certPath and
+// decodes the answer. The issuer defaults to the second certificate of the
+// file and the responder URL to the certificate's AIA OCSP URL.
+func Query(certPath string, opts Options) (*ResponseInfo, error) {
+	certs, err := certificate.LoadX509Certificates(certPath)
+	if err != nil {
+		return nil, err
+	}
+	cert := certs[0]
+
+	var issuer *x509.Certificate
+	switch {
+	case opts.IssuerPath != "":
+		issuer, err = loadIssuer(opts.IssuerPath)
+		if err != nil {
+			return nil, err
+		}
+	case len(certs) > 1:
+		issuer = certs[1]
+	default:
+		return nil, fmt.Errorf("issuer certificate required to build the OCSP request; use --issuer")
+	}
+
+	url := opts.URL
+	if url == "" {
+		if len(cert.OCSPServer) == 0 {
+			return nil, fmt.Errorf("%s has no OCSP responder URL; use --url", certPath)
+		}
+		url = cert.OCSPServer[0]
+	}
+
+	request, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+	httpReq.Header.Set("Accept", "application/ocsp-response")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder %s returned %s", url, httpResp.Status)
+	}
+
+	der, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := DecodeResponse(der, issuer, url)
+	if err != nil {
+		return nil, err
+	}
+	info.CommonName = cert.Subject.CommonName
+
+	if info.SerialNumber != cert.SerialNumber.String() {
+		return nil, fmt.Errorf("OCSP response is for serial %s, expected %s", info.SerialNumber, cert.SerialNumber.String())
+	}
+
+	return info, nil
+}
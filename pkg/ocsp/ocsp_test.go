@@ -0,0 +1,155 @@
+package ocsp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func getTestCertPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func loadCA(t *testing.T) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	certs, err := certificate.LoadX509Certificates(getTestCertPath("crl/ca.crt"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(getTestCertPath("crl/ca.key"))
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	return certs[0], key.(crypto.Signer)
+}
+
+// newResponder starts an OCSP responder that reports every serial in revoked
+// as revoked (keyCompromise) and everything else as good.
+func newResponder(t *testing.T, issuer *x509.Certificate, key crypto.Signer, revoked map[string]bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Hour),
+			NextUpdate:   time.Now().Add(24 * time.Hour),
+		}
+		if revoked[req.SerialNumber.String()] {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = time.Now().Add(-2 * time.Hour)
+			template.RevocationReason = ocsp.KeyCompromise
+		}
+
+		resp, err := ocsp.CreateResponse(issuer, issuer, template, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQuery(t *testing.T) {
+	issuer, key := loadCA(t)
+	server := newResponder(t, issuer, key, map[string]bool{"4097": true})
+
+	resp, err := Query(getTestCertPath("crl/good.crt"), Options{IssuerPath: getTestCertPath("crl/ca.crt"), URL: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, certificate.RevocationGood, resp.Status)
+	assert.Equal(t, "good.test.local", resp.CommonName)
+	assert.True(t, resp.SignatureValid)
+	assert.Equal(t, "CN=Test CRL CA,O=TestCRL,C=IT", resp.ResponderID)
+	assert.False(t, resp.NextUpdate.IsZero())
+
+	resp, err = Query(getTestCertPath("crl/revoked.crt"), Options{IssuerPath: getTestCertPath("crl/ca.crt"), URL: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, certificate.RevocationRevoked, resp.Status)
+	assert.Equal(t, "keyCompromise", resp.RevocationReason)
+	assert.False(t, resp.RevokedAt.IsZero())
+}
+
+func TestQueryDefaultsToAIAURL(t *testing.T) {
+	issuer, key := loadCA(t)
+	server := newResponder(t, issuer, key, nil)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "aia.test.local"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		OCSPServer:   []string{server.URL},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "aia.crt")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issuer.Raw})...)
+	require.NoError(t, os.WriteFile(path, bundle, 0600))
+
+	resp, err := Query(path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, resp.Source)
+	assert.Equal(t, "42", resp.SerialNumber)
+	assert.True(t, resp.SignatureValid)
+}
+
+func TestQueryRequiresIssuerAndURL(t *testing.T) {
+	_, err := Query(getTestCertPath("crl/good.crt"), Options{})
+	assert.ErrorContains(t, err, "issuer")
+
+	_, err = Query(getTestCertPath("crl/good.crt"), Options{IssuerPath: getTestCertPath("crl/ca.crt")})
+	assert.ErrorContains(t, err, "no OCSP responder URL")
+}
+
+func TestParseResponseFile(t *testing.T) {
+	resp, err := ParseResponseFile(getTestCertPath("crl/revoked.ocsp"), getTestCertPath("crl/ca.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, certificate.RevocationRevoked, resp.Status)
+	assert.Equal(t, "4097", resp.SerialNumber)
+	assert.Equal(t, "keyCompromise", resp.RevocationReason)
+	assert.True(t, resp.SignatureChecked)
+	assert.True(t, resp.SignatureValid)
+
+	resp, err = ParseResponseFile(getTestCertPath("crl/good.ocsp"), "")
+	require.NoError(t, err)
+	assert.Equal(t, certificate.RevocationGood, resp.Status)
+	assert.False(t, resp.SignatureChecked)
+
+	resp, err = ParseResponseFile(getTestCertPath("crl/good.ocsp"), getTestCertPath("chain/root-ca.crt"))
+	require.NoError(t, err)
+	assert.True(t, resp.SignatureChecked)
+	assert.False(t, resp.SignatureValid)
+	assert.NotEmpty(t, resp.SignatureError)
+}
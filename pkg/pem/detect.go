@@ -0,0 +1,336 @@
+package pem
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Kind is the type of crypto object found in a file.
+type Kind string
+
+const (
+	KindCertificate Kind = "certificate"
+	KindBundle      Kind = "bundle"
+	KindPrivateKey  Kind = "private key"
+	KindPublicKey   Kind = "public key"
+	KindCSR         Kind = "csr"
+	KindCRL         Kind = "crl"
+	KindPKCS7       Kind = "pkcs7"
+	KindPKCS12      Kind = "pkcs12"
+	KindJKS         Kind = "jks"
+	KindSSHKey      Kind = "ssh key"
+	KindUnknown     Kind = "unknown"
+)
+
+const (
+	TypeTrustedCertificate BlockType = "TRUSTED CERTIFICATE"
+	TypePKCS7              BlockType = "PKCS7"
+	TypeCMS                BlockType = "CMS"
+	TypeOpenSSHPrivateKey  BlockType = "OPENSSH PRIVATE KEY"
+	TypeDSAPrivateKey      BlockType = "DSA PRIVATE KEY"
+)
+
+// Object is one crypto object found by a detector. Bytes holds the DER of
+// PEM blocks and DER files, and the raw data of other formats. Headers are
+// the PEM headers, such as those of legacy encrypted keys.
+type Object struct {
+	Kind     Kind
+	Encoding string
+	Label    string
+	Headers  map[string]string
+	Bytes    []byte
+}
+
+// Encrypted reports whether o is a private key that needs a password: an
+// encrypted PKCS#8 key, or a legacy PEM key with a Proc-Type header.
+func (o Object) Encrypted() bool {
+	return o.Kind == KindPrivateKey && (o.Label == string(TypeEncryptedKey) || o.Headers["Proc-Type"] != "")
+}
+
+// isOneOf reports whether o is a block of one of types, or an object of the
+// kind one of them holds.
+func (o Object) isOneOf(types []BlockType) bool {
+	for _, t := range types {
+		if o.Label == string(t) || (o.Kind != KindUnknown && o.Kind == KindOf(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Detector recognises one file format and returns the objects in data, or
+// nil when data is not in that format.
+type Detector func(data []byte) []Object
+
+type namedDetector struct {
+	name   string
+	detect Detector
+}
+
+var detectors []namedDetector
+
+// Register adds a detector after the built-in ones. Detectors run in
+// registration order and the first one that finds objects wins.
+func Register(name string, detect Detector) {
+	detectors = append(detectors, namedDetector{name, detect})
+}
+
+var blockKinds = map[BlockType]Kind{
+	TypeCertificate:        KindCertificate,
+	TypePQCCertificate:     KindCertificate,
+	TypeTrustedCertificate: KindCertificate,
+	TypePrivateKey:         KindPrivateKey,
+	TypeEncryptedKey:       KindPrivateKey,
+	TypeECPrivateKey:       KindPrivateKey,
+	TypeRSAPrivateKey:      KindPrivateKey,
+	TypeDSAPrivateKey:      KindPrivateKey,
+	TypeMLKEMPrivateKey:    KindPrivateKey,
+	TypeMLDSAPrivateKey:    KindPrivateKey,
+	TypePublicKey:          KindPublicKey,
+	TypeRSAPublicKey:       KindPublicKey,
+	TypeECPublicKey:        KindPublicKey,
+	TypeMLKEMPublicKey:     KindPublicKey,
+	TypeMLDSAPublicKey:     KindPublicKey,
+	TypeCSR:                KindCSR,
+	TypeNewCSR:             KindCSR,
+	TypeCRL:                KindCRL,
+	TypePKCS7:              KindPKCS7,
+	TypeCMS:                KindPKCS7,
+	TypeOpenSSHPrivateKey:  KindSSHKey,
+}
+
+// RegisterBlockType maps a PEM block type to the kind of object it holds.
+func RegisterBlockType(t BlockType, kind Kind) {
+	blockKinds[t] = kind
+}
+
+// KindOf returns the kind of object a PEM block type holds.
+func KindOf(t BlockType) Kind {
+	if kind, ok := blockKinds[t]; ok {
+		return kind
+	}
+	return KindUnknown
+}
+
+// Detect returns every crypto object in data.
+func Detect(data []byte) []Object {
+	for _, d := range detectors {
+		if objects := d.detect(data); len(objects) > 0 {
+			return objects
+		}
+	}
+	return nil
+}
+
+// Classify returns the kind of a file from the objects found in it: a file
+// with several certificates, or with objects of different kinds, is a
+// bundle.
+func Classify(objects []Object) Kind {
+	if len(objects) == 0 {
+		return KindUnknown
+	}
+	kind := objects[0].Kind
+	for _, o := range objects[1:] {
+		if o.Kind != kind {
+			return KindBundle
+		}
+	}
+	if kind == KindCertificate && len(objects) > 1 {
+		return KindBundle
+	}
+	return kind
+}
+
+func init() {
+	Register("pem", detectPEM)
+	Register("jks", detectJKS)
+	Register("ssh", detectSSHPublicKeys)
+	Register("der", detectDER)
+}
+
+func detectPEM(data []byte) []Object {
+	if !IsPEM(data) {
+		return nil
+	}
+	var objects []Object
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return objects
+		}
+		if kind := KindOf(BlockType(block.Type)); kind != KindUnknown {
+			der := block.Bytes
+			if block.Type == string(TypeTrustedCertificate) {
+				der = trimTrustedCertificate(der)
+			}
+			objects = append(objects, Object{Kind: kind, Encoding: "PEM", Label: block.Type, Headers: block.Headers, Bytes: der})
+		}
+		data = rest
+	}
+}
+
+// trimTrustedCertificate drops the trust settings OpenSSL appends to the
+// certificate of a TRUSTED CERTIFICATE block.
+func trimTrustedCertificate(der []byte) []byte {
+	var cert asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &cert); err != nil {
+		return der
+	}
+	return cert.FullBytes
+}
+
+var (
+	jksMagic   = []byte{0xfe, 0xed, 0xfe, 0xed}
+	jceksMagic = []byte{0xce, 0xce, 0xce, 0xce}
+)
+
+func detectJKS(data []byte) []Object {
+	if len(data) < 12 {
+		return nil
+	}
+	switch {
+	case bytes.HasPrefix(data, jksMagic):
+		return []Object{{Kind: KindJKS, Encoding: "JKS", Label: "JKS", Bytes: data}}
+	case bytes.HasPrefix(data, jceksMagic):
+		return []Object{{Kind: KindJKS, Encoding: "JCEKS", Label: "JCEKS", Bytes: data}}
+	}
+	return nil
+}
+
+// JKSEntries returns the number of entries in a JKS or JCEKS keystore.
+func JKSEntries(data []byte) int {
+	if len(data) < 12 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(data[8:12]))
+}
+
+// detectSSHPublicKeys finds OpenSSH public keys, one per line as in
+// authorized_keys and .pub files.
+func detectSSHPublicKeys(data []byte) []Object {
+	var objects []Object
+	for len(data) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return objects
+		}
+		objects = append(objects, Object{Kind: KindSSHKey, Encoding: "OpenSSH", Label: key.Type(), Bytes: key.Marshal()})
+		data = rest
+	}
+	return objects
+}
+
+var (
+	oidData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs8 struct {
+	Version    int
+	Algorithm  algorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue `asn1:"optional,tag:0"`
+	PublicKey  asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type encryptedPKCS8 struct {
+	Algorithm     algorithmIdentifier
+	EncryptedData []byte
+}
+
+type spki struct {
+	Algorithm algorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+type pfx struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  asn1.RawValue `asn1:"optional"`
+}
+
+// unmarshalAll reports whether der is exactly one value of type out.
+func unmarshalAll(der []byte, out any) bool {
+	rest, err := asn1.Unmarshal(der, out)
+	return err == nil && len(rest) == 0
+}
+
+// isBERPFX recognises PKCS#12 files written with BER indefinite lengths,
+// which encoding/asn1 cannot decode: a SEQUENCE of unknown length starting
+// with version 3 and the authSafe content info.
+func isBERPFX(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0x30, 0x80, 0x02, 0x01, 0x03, 0x30})
+}
+
+// detectDER recognises binary files by their ASN.1 structure, so that keys
+// and certificates of algorithms the standard library does not know are
+// still found.
+func detectDER(data []byte) []Object {
+	if len(data) == 0 || data[0] != 0x30 {
+		return nil
+	}
+	der := func(kind Kind, label string) []Object {
+		return []Object{{Kind: kind, Encoding: "DER", Label: label, Bytes: data}}
+	}
+
+	if certs, err := x509.ParseCertificates(data); err == nil && len(certs) > 0 {
+		objects := make([]Object, 0, len(certs))
+		for _, c := range certs {
+			objects = append(objects, Object{Kind: KindCertificate, Encoding: "DER", Label: string(TypeCertificate), Bytes: c.Raw})
+		}
+		return objects
+	}
+	if _, err := x509.ParseCertificateRequest(data); err == nil {
+		return der(KindCSR, string(TypeCSR))
+	}
+	if _, err := x509.ParseRevocationList(data); err == nil {
+		return der(KindCRL, string(TypeCRL))
+	}
+
+	var p pfx
+	if (unmarshalAll(data, &p) && p.Version == 3 && p.AuthSafe.ContentType.Equal(oidData)) || isBERPFX(data) {
+		return der(KindPKCS12, "PKCS12")
+	}
+	var ci contentInfo
+	if unmarshalAll(data, &ci) && ci.ContentType.Equal(oidSignedData) {
+		return der(KindPKCS7, string(TypePKCS7))
+	}
+
+	var k pkcs8
+	if unmarshalAll(data, &k) && (k.Version == 0 || k.Version == 1) {
+		return der(KindPrivateKey, string(TypePrivateKey))
+	}
+	var ek encryptedPKCS8
+	if unmarshalAll(data, &ek) {
+		return der(KindPrivateKey, string(TypeEncryptedKey))
+	}
+	if _, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return der(KindPrivateKey, string(TypeRSAPrivateKey))
+	}
+	if _, err := x509.ParseECPrivateKey(data); err == nil {
+		return der(KindPrivateKey, string(TypeECPrivateKey))
+	}
+	var pub spki
+	if unmarshalAll(data, &pub) {
+		return der(KindPublicKey, string(TypePublicKey))
+	}
+	if _, err := x509.ParsePKCS1PublicKey(data); err == nil {
+		return der(KindPublicKey, string(TypeRSAPublicKey))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package pem
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func readTestFile(t *testing.T, relPath string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "test_certs", relPath))
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	return data
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		filePath string
+		kind     Kind
+		encoding string
+		objects  int
+	}{
+		{"traditional/rsa/server-rsa2048.crt", KindCertificate, "PEM", 1},
+		{"chain/fullchain.crt", KindBundle, "PEM", 2},
+		{"traditional/rsa/server-rsa2048.key", KindPrivateKey, "PEM", 1},
+		{"traditional/rsa-encrypted/ca-rsa2048-encrypted.key", KindPrivateKey, "PEM", 1},
+		{"csr/server-rsa.csr", KindCSR, "PEM", 1},
+		{"csr/server-ecdsa.der", KindCSR, "DER", 1},
+		{"crl/ca.crl", KindCRL, "PEM", 1},
+		{"crl/ca-der.crl", KindCRL, "DER", 1},
+		{"p12-format/server-rsa2048.pfx", KindPKCS12, "DER", 1},
+		{"p12-format/server-ber-indefinite.pfx", KindPKCS12, "DER", 1},
+		{"README.md", KindUnknown, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			objects := Detect(readTestFile(t, tt.filePath))
+			if len(objects) != tt.objects {
+				t.Fatalf("Detect() found %d objects, expected %d", len(objects), tt.objects)
+			}
+			if kind := Classify(objects); kind != tt.kind {
+				t.Errorf("Classify() = %q, expected %q", kind, tt.kind)
+			}
+			for _, o := range objects {
+				if o.Encoding != tt.encoding {
+					t.Errorf("Encoding = %q, expected %q", o.Encoding, tt.encoding)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectDERKeys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(priv)
+	spki, _ := x509.MarshalPKIXPublicKey(pub)
+
+	if kind := Classify(Detect(pkcs8)); kind != KindPrivateKey {
+		t.Errorf("PKCS#8 key classified as %q", kind)
+	}
+	if kind := Classify(Detect(spki)); kind != KindPublicKey {
+		t.Errorf("SubjectPublicKeyInfo classified as %q", kind)
+	}
+}
+
+func TestDetectSSHAndJKS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := ssh.MarshalAuthorizedKey(sshPub)
+	authorizedKeys := append(append([]byte{}, line...), line...)
+
+	objects := Detect(authorizedKeys)
+	if len(objects) != 2 || Classify(objects) != KindSSHKey {
+		t.Errorf("authorized_keys: found %d objects of kind %q", len(objects), Classify(objects))
+	}
+	if objects[0].Label != ssh.KeyAlgoED25519 {
+		t.Errorf("Label = %q, expected %q", objects[0].Label, ssh.KeyAlgoED25519)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := Classify(Detect(pem.EncodeToMemory(block))); kind != KindSSHKey {
+		t.Errorf("OpenSSH private key classified as %q", kind)
+	}
+
+	jks := []byte{0xfe, 0xed, 0xfe, 0xed, 0, 0, 0, 2, 0, 0, 0, 3}
+	objects = Detect(jks)
+	if Classify(objects) != KindJKS || JKSEntries(objects[0].Bytes) != 3 {
+		t.Errorf("JKS keystore: kind %q, %d entries", Classify(objects), JKSEntries(jks))
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := detectors
+	defer func() { detectors = saved }()
+
+	data := []byte("custom format")
+	if objects := Detect(data); len(objects) != 0 {
+		t.Fatalf("Detect() found %d objects before registration", len(objects))
+	}
+
+	Register("custom", func(data []byte) []Object {
+		if string(data) != "custom format" {
+			return nil
+		}
+		return []Object{{Kind: KindPublicKey, Encoding: "custom", Bytes: data}}
+	})
+	if kind := Classify(Detect(data)); kind != KindPublicKey {
+		t.Errorf("Classify() = %q after registration, expected %q", kind, KindPublicKey)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	if kind := KindOf(TypeCertificate); kind != KindCertificate {
+		t.Errorf("KindOf(CERTIFICATE) = %q", kind)
+	}
+	if kind := KindOf("X509 ATTRIBUTE CERTIFICATE"); kind != KindUnknown {
+		t.Errorf("KindOf(unregistered) = %q", kind)
+	}
+
+	RegisterBlockType("X509 ATTRIBUTE CERTIFICATE", KindCertificate)
+	defer delete(blockKinds, "X509 ATTRIBUTE CERTIFICATE")
+	if kind := KindOf("X509 ATTRIBUTE CERTIFICATE"); kind != KindCertificate {
+		t.Errorf("KindOf(registered) = %q", kind)
+	}
+}
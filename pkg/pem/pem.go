@@ -2,7 +2,6 @@ package pem
 
 import (
 	"bytes"
-	"encoding/pem"
 )
 
 var pemHeader = []byte("-----BEGIN")
@@ -27,20 +26,32 @@ const (
 	TypeMLDSAPrivateKey BlockType = "ML-DSA PRIVATE KEY"
 	TypeMLDSAPublicKey  BlockType = "ML-DSA PUBLIC KEY"
 	TypePQCCertificate  BlockType = "POST-QUANTUM CERTIFICATE"
+	TypeCSR             BlockType = "CERTIFICATE REQUEST"
+	TypeNewCSR          BlockType = "NEW CERTIFICATE REQUEST"
+	TypeCRL             BlockType = "X509 CRL"
 )
 
+// FindBlock returns the contents of the first object in data that is of
+// one of types, or holds the same kind of object as one of them. Objects
+// are found with Detect, so block types added with RegisterBlockType and
+// formats added with Register are found as well.
 func FindBlock(data []byte, types ...BlockType) ([]byte, bool) {
-	for {
-		block, rest := pem.Decode(data)
-		if block == nil {
-			return nil, false
+	for _, o := range Detect(data) {
+		if o.isOneOf(types) {
+			return o.Bytes, true
 		}
+	}
+	return nil, false
+}
 
-		for _, t := range types {
-			if block.Type == string(t) {
-				return block.Bytes, true
-			}
+// FindAllBlocks returns the contents of every object in data that FindBlock
+// would accept, in file order.
+func FindAllBlocks(data []byte, types ...BlockType) [][]byte {
+	var blocks [][]byte
+	for _, o := range Detect(data) {
+		if o.isOneOf(types) {
+			blocks = append(blocks, o.Bytes)
 		}
-		data = rest
 	}
+	return blocks
 }
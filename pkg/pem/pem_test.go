@@ -1,6 +1,9 @@
 package pem
 
 import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
 	"os"
 	"path/filepath"
 	"testing"
@@ -78,3 +81,55 @@ func TestFindBlock_KeyWithTextPrefix(t *testing.T) {
 		t.Error("FindBlock() returned empty block")
 	}
 }
+
+func TestFindAllBlocks(t *testing.T) {
+	root, err := os.ReadFile(filepath.Join("..", "..", "test_certs/chain/root-ca.crt"))
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	intermediate, err := os.ReadFile(filepath.Join("..", "..", "test_certs/chain/intermediate-ca.crt"))
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+
+	bundle := append(append([]byte{}, intermediate...), root...)
+	blocks := FindAllBlocks(bundle, TypeCertificate)
+	if len(blocks) != 2 {
+		t.Fatalf("FindAllBlocks() returned %d blocks, expected 2", len(blocks))
+	}
+
+	if blocks := FindAllBlocks(bundle, TypePrivateKey); len(blocks) != 0 {
+		t.Errorf("FindAllBlocks() returned %d private key blocks, expected 0", len(blocks))
+	}
+}
+
+func TestFindAllBlocksByKind(t *testing.T) {
+	block, _ := pem.Decode(readTestFile(t, "chain/root-ca.crt"))
+	if block == nil {
+		t.Fatal("no PEM block in root-ca.crt")
+	}
+
+	// OpenSSL appends the trust settings to the certificate.
+	aux, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := pem.EncodeToMemory(&pem.Block{Type: string(TypeTrustedCertificate), Bytes: append(append([]byte{}, block.Bytes...), aux...)})
+	der, found := FindBlock(trusted, TypeCertificate)
+	if !found {
+		t.Fatal("FindBlock() should find a TRUSTED CERTIFICATE block as a certificate")
+	}
+	if _, err := x509.ParseCertificate(der); err != nil {
+		t.Errorf("the trust settings should be dropped: %v", err)
+	}
+
+	relabelled := pem.EncodeToMemory(&pem.Block{Type: "X509 CERTIFICATE", Bytes: block.Bytes})
+	if blocks := FindAllBlocks(relabelled, TypeCertificate); len(blocks) != 0 {
+		t.Errorf("FindAllBlocks() returned %d blocks of an unregistered type, expected 0", len(blocks))
+	}
+	RegisterBlockType("X509 CERTIFICATE", KindCertificate)
+	defer delete(blockKinds, "X509 CERTIFICATE")
+	if blocks := FindAllBlocks(relabelled, TypeCertificate); len(blocks) != 1 {
+		t.Errorf("FindAllBlocks() returned %d blocks of a registered type, expected 1", len(blocks))
+	}
+}
@@ -1,6 +1,7 @@
 package pkcs12
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
@@ -11,7 +12,10 @@ import (
 	"strings"
 
 	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pem"
 	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/walk"
 	"software.sslmate.com/src/go-pkcs12"
 )
 
@@ -463,3 +467,74 @@ func ParseP12FromBytes(data []byte, filename string, password ...string) (*P12In
 
 	return parseP12Data(data, filename, pwd)
 }
+
+// fileSummary is what one file contributes to a directory summary.
+type fileSummary struct {
+	certs []certificate.CertificateSummary
+	keys  []privatekey.KeySummary
+	diag  *diag.Diagnostic
+}
+
+func summarizeFile(f walk.File, password string) fileSummary {
+	if f.Err != nil {
+		d := diag.ForPath(f.RelPath, f.Err)
+		return fileSummary{diag: &d}
+	}
+
+	data, err := f.ReadFile()
+	if err != nil {
+		d := diag.ForFile(f.RelPath, nil, err)
+		return fileSummary{diag: &d}
+	}
+
+	p12, err := ParseP12FromBytes(data, f.RelPath, password)
+	if err != nil {
+		d := diag.ForFile(f.RelPath, data, err, pem.KindPKCS12)
+		return fileSummary{diag: &d}
+	}
+
+	var result fileSummary
+	for i, c := range p12.Certificates {
+		c.Cert.Index = i
+		result.certs = append(result.certs, certificate.NewCertificateSummary(f.RelPath, c.Cert))
+	}
+	for _, k := range p12.PrivateKeys {
+		result.keys = append(result.keys, privatekey.NewKeySummary(f.RelPath, k.Key))
+	}
+	return result
+}
+
+// SummarizeDirectoryRecursive lists the certificates and private keys of
+// every PKCS#12 file below dirPath that opens with the password, and the
+// diagnostics of the files that could not be opened.
+func SummarizeDirectoryRecursive(dirPath string, password ...string) ([]certificate.CertificateSummary, []privatekey.KeySummary, []diag.Diagnostic, error) {
+	pwd := ""
+	if len(password) > 0 {
+		pwd = password[0]
+	}
+
+	return SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Recursive: true}, pwd)
+}
+
+// SummarizeDirectoryContext is SummarizeDirectoryRecursive with the walk
+// depth and the number of workers set by opts.
+func SummarizeDirectoryContext(ctx context.Context, dirPath string, opts walk.Options, password string) ([]certificate.CertificateSummary, []privatekey.KeySummary, []diag.Diagnostic, error) {
+	files, err := walk.Map(ctx, dirPath, opts, func(f walk.File) fileSummary {
+		return summarizeFile(f, password)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	certs := make([]certificate.CertificateSummary, 0, len(files))
+	keys := make([]privatekey.KeySummary, 0, len(files))
+	var diags []diag.Diagnostic
+	for _, f := range files {
+		certs = append(certs, f.certs...)
+		keys = append(keys, f.keys...)
+		if f.diag != nil {
+			diags = append(diags, *f.diag)
+		}
+	}
+	return certs, keys, diags, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/marco-introini/certinfo/pkg/diag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -216,3 +217,30 @@ func TestParseP12BERIndefiniteLength(t *testing.T) {
 	assert.Equal(t, "RSA", key.KeyType, "Key should be RSA")
 	assert.NotZero(t, key.Bits, "Key should have bit length")
 }
+
+func TestSummarizeDirectoryRecursive(t *testing.T) {
+	certs, keys, _, err := SummarizeDirectoryRecursive(getTestP12Path("p12-format"), "testpass")
+	require.NoError(t, err)
+	require.NotEmpty(t, certs)
+	require.NotEmpty(t, keys)
+
+	files := make(map[string]bool)
+	for _, c := range certs {
+		files[c.Filename] = true
+		assert.NotEmpty(t, c.KeyType)
+	}
+	assert.True(t, files["server-rsa2048.pfx"])
+	for _, k := range keys {
+		assert.True(t, files[k.Filename], k.Filename)
+	}
+
+	certs, keys, diags, err := SummarizeDirectoryRecursive(getTestP12Path("p12-format"), "wrong")
+	require.NoError(t, err)
+	assert.Empty(t, certs)
+	assert.Empty(t, keys)
+	categories := make(map[string]diag.Category)
+	for _, d := range diags {
+		categories[d.Path] = d.Category
+	}
+	assert.Equal(t, diag.Encrypted, categories["server-rsa2048.pfx"])
+}
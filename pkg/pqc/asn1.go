@@ -0,0 +1,128 @@
+package pqc
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// signed is the outer shape shared by certificates, CSRs and CRLs.
+type signed struct {
+	TBS       asn1.RawValue
+	Algorithm pkix.AlgorithmIdentifier
+	Signature asn1.BitString
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type privateKeyInfo struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue  `asn1:"optional,tag:0"`
+	PublicKey  asn1.BitString `asn1:"optional,tag:1"`
+}
+
+// SignatureAlgorithmOID returns the signature algorithm of a DER-encoded
+// certificate, CSR or CRL.
+func SignatureAlgorithmOID(der []byte) (string, error) {
+	var s signed
+	if _, err := asn1.Unmarshal(der, &s); err != nil {
+		return "", fmt.Errorf("invalid signed structure: %w", err)
+	}
+	return s.Algorithm.Algorithm.String(), nil
+}
+
+// PublicKeyAlgorithmOID returns the algorithm of a DER-encoded
+// SubjectPublicKeyInfo.
+func PublicKeyAlgorithmOID(spki []byte) (string, error) {
+	oid, _, err := PublicKey(spki)
+	return oid, err
+}
+
+// PublicKey returns the algorithm and the raw subjectPublicKey bits of a
+// DER-encoded SubjectPublicKeyInfo.
+func PublicKey(spki []byte) (string, []byte, error) {
+	var s subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(spki, &s); err != nil {
+		return "", nil, fmt.Errorf("invalid SubjectPublicKeyInfo: %w", err)
+	}
+	return s.Algorithm.Algorithm.String(), s.PublicKey.Bytes, nil
+}
+
+// PrivateKeyAlgorithmOID returns the algorithm of a DER-encoded PKCS#8
+// PrivateKeyInfo.
+func PrivateKeyAlgorithmOID(pkcs8 []byte) (string, error) {
+	oid, _, err := PrivateKey(pkcs8)
+	return oid, err
+}
+
+// PrivateKey returns the algorithm and the privateKey octets of a
+// DER-encoded PKCS#8 PrivateKeyInfo or OneAsymmetricKey.
+func PrivateKey(pkcs8 []byte) (string, []byte, error) {
+	var k privateKeyInfo
+	if _, err := asn1.Unmarshal(pkcs8, &k); err != nil {
+		return "", nil, fmt.Errorf("invalid PKCS#8 structure: %w", err)
+	}
+	return k.Algorithm.Algorithm.String(), k.PrivateKey, nil
+}
+
+// MarshalPublicKey returns the DER-encoded SubjectPublicKeyInfo of a raw
+// public key of the algorithm with the given OID.
+func MarshalPublicKey(oid string, key []byte) ([]byte, error) {
+	id, err := parseOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: id},
+		PublicKey: asn1.BitString{Bytes: key, BitLength: 8 * len(key)},
+	})
+}
+
+func parseOID(dotted string) (asn1.ObjectIdentifier, error) {
+	var oid asn1.ObjectIdentifier
+	for _, part := range strings.Split(dotted, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", dotted)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
+
+// Detect returns the registered algorithms of a signed structure's
+// signature and of its SubjectPublicKeyInfo. Either may be nil.
+func Detect(signedDER, spki []byte) (sig, key *Algorithm) {
+	if oid, err := SignatureAlgorithmOID(signedDER); err == nil {
+		if alg, ok := ByOID(oid); ok {
+			sig = &alg
+		}
+	}
+	if oid, err := PublicKeyAlgorithmOID(spki); err == nil {
+		if alg, ok := ByOID(oid); ok {
+			key = &alg
+		}
+	}
+	return sig, key
+}
+
+// Names returns the distinct names of the given algorithms, skipping nils.
+func Names(algs ...*Algorithm) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, alg := range algs {
+		if alg == nil || seen[alg.Name] {
+			continue
+		}
+		seen[alg.Name] = true
+		names = append(names, alg.Name)
+	}
+	return names
+}
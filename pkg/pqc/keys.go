@@ -0,0 +1,202 @@
+package pqc
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem512"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+	"github.com/cloudflare/circl/sign"
+)
+
+// Private key encodings allowed by the IETF LAMPS ML-DSA and ML-KEM
+// drafts: the seed, the expanded key, or both.
+const (
+	KeyEncodingSeed     = "seed"
+	KeyEncodingExpanded = "expanded"
+	KeyEncodingBoth     = "both"
+)
+
+// PrivateKeyContents is the decoded private key of an ML-DSA or ML-KEM
+// PKCS#8 structure.
+type PrivateKeyContents struct {
+	Encoding  string
+	Seed      []byte
+	Expanded  []byte
+	PublicKey []byte
+	// SeedMatches reports whether the expanded key is the one the seed
+	// derives. It is nil unless both are present.
+	SeedMatches *bool
+}
+
+// keyScheme derives expanded and public keys for one parameter set.
+type keyScheme struct {
+	seedSize     int
+	expandedSize int
+	publicSize   int
+	derive       func(seed []byte) (expanded, public []byte, err error)
+	public       func(expanded []byte) ([]byte, error)
+}
+
+func signKeyScheme(s sign.Scheme) keyScheme {
+	return keyScheme{
+		seedSize:     s.SeedSize(),
+		expandedSize: s.PrivateKeySize(),
+		publicSize:   s.PublicKeySize(),
+		derive: func(seed []byte) ([]byte, []byte, error) {
+			pub, priv := s.DeriveKey(seed)
+			return marshalPair(priv, pub)
+		},
+		public: func(expanded []byte) ([]byte, error) {
+			priv, err := s.UnmarshalBinaryPrivateKey(expanded)
+			if err != nil {
+				return nil, err
+			}
+			return priv.Public().(sign.PublicKey).MarshalBinary()
+		},
+	}
+}
+
+func kemKeyScheme(s kem.Scheme) keyScheme {
+	return keyScheme{
+		seedSize:     s.SeedSize(),
+		expandedSize: s.PrivateKeySize(),
+		publicSize:   s.PublicKeySize(),
+		derive: func(seed []byte) ([]byte, []byte, error) {
+			pub, priv := s.DeriveKeyPair(seed)
+			return marshalPair(priv, pub)
+		},
+		public: func(expanded []byte) ([]byte, error) {
+			priv, err := s.UnmarshalBinaryPrivateKey(expanded)
+			if err != nil {
+				return nil, err
+			}
+			return priv.Public().MarshalBinary()
+		},
+	}
+}
+
+type binaryMarshaler interface {
+	MarshalBinary() ([]byte, error)
+}
+
+func marshalPair(priv, pub binaryMarshaler) ([]byte, []byte, error) {
+	expanded, err := priv.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	public, err := pub.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	return expanded, public, nil
+}
+
+// keySchemeFor covers the FIPS 203 and FIPS 204 parameter sets, including
+// HashML-DSA, whose keys are plain ML-DSA keys.
+func keySchemeFor(alg Algorithm) (keyScheme, bool) {
+	switch alg.Standard {
+	case StandardFIPS204:
+		if s, ok := mldsaSchemes["ML-DSA-"+strconv.Itoa(alg.Bits)]; ok {
+			return signKeyScheme(s), true
+		}
+	case StandardFIPS203:
+		switch alg.Bits {
+		case 512:
+			return kemKeyScheme(mlkem512.Scheme()), true
+		case 768:
+			return kemKeyScheme(mlkem768.Scheme()), true
+		case 1024:
+			return kemKeyScheme(mlkem1024.Scheme()), true
+		}
+	}
+	return keyScheme{}, false
+}
+
+// HasPrivateKeyContents reports whether DecodePrivateKey supports alg.
+func HasPrivateKeyContents(alg Algorithm) bool {
+	_, ok := keySchemeFor(alg)
+	return ok
+}
+
+// DecodePrivateKey decodes the privateKey field of an ML-DSA or ML-KEM
+// PKCS#8 structure:
+//
+//	PrivateKey ::= CHOICE {
+//	  seed        [0] OCTET STRING,
+//	  expandedKey OCTET STRING,
+//	  both        SEQUENCE { seed OCTET STRING, expandedKey OCTET STRING } }
+//
+// Untagged raw seeds and expanded keys written before the drafts settled,
+// optionally followed by the public key, are accepted too. The public key is
+// derived from the seed or the expanded key.
+func DecodePrivateKey(alg Algorithm, data []byte) (*PrivateKeyContents, error) {
+	ks, ok := keySchemeFor(alg)
+	if !ok {
+		return nil, fmt.Errorf("%s private keys cannot be decoded", alg.Name)
+	}
+
+	contents, err := splitPrivateKey(ks, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s private key: %w", alg.Name, err)
+	}
+	if contents.Seed != nil && len(contents.Seed) != ks.seedSize {
+		return nil, fmt.Errorf("invalid %s private key: seed is %d bytes, want %d", alg.Name, len(contents.Seed), ks.seedSize)
+	}
+	if contents.Expanded != nil && len(contents.Expanded) != ks.expandedSize {
+		return nil, fmt.Errorf("invalid %s private key: expanded key is %d bytes, want %d", alg.Name, len(contents.Expanded), ks.expandedSize)
+	}
+
+	if contents.Seed != nil {
+		expanded, public, err := ks.derive(contents.Seed)
+		if err != nil {
+			return nil, err
+		}
+		if contents.Expanded != nil {
+			matches := bytes.Equal(expanded, contents.Expanded)
+			contents.SeedMatches = &matches
+		}
+		contents.PublicKey = public
+		return contents, nil
+	}
+
+	public, err := ks.public(contents.Expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s private key: %w", alg.Name, err)
+	}
+	contents.PublicKey = public
+	return contents, nil
+}
+
+func splitPrivateKey(ks keyScheme, data []byte) (*PrivateKeyContents, error) {
+	var raw asn1.RawValue
+	if rest, err := asn1.Unmarshal(data, &raw); err == nil && len(rest) == 0 {
+		switch {
+		case raw.Class == asn1.ClassContextSpecific && raw.Tag == 0 && !raw.IsCompound:
+			return &PrivateKeyContents{Encoding: KeyEncodingSeed, Seed: raw.Bytes}, nil
+		case raw.Class == asn1.ClassUniversal && raw.Tag == asn1.TagOctetString:
+			return &PrivateKeyContents{Encoding: KeyEncodingExpanded, Expanded: raw.Bytes}, nil
+		case raw.Class == asn1.ClassUniversal && raw.Tag == asn1.TagSequence:
+			var both struct {
+				Seed     []byte
+				Expanded []byte
+			}
+			if _, err := asn1.Unmarshal(data, &both); err != nil {
+				return nil, err
+			}
+			return &PrivateKeyContents{Encoding: KeyEncodingBoth, Seed: both.Seed, Expanded: both.Expanded}, nil
+		}
+	}
+
+	switch len(data) {
+	case ks.seedSize:
+		return &PrivateKeyContents{Encoding: KeyEncodingSeed, Seed: data}, nil
+	case ks.expandedSize, ks.expandedSize + ks.publicSize:
+		return &PrivateKeyContents{Encoding: KeyEncodingExpanded, Expanded: data[:ks.expandedSize]}, nil
+	}
+	return nil, fmt.Errorf("unrecognised encoding of %d bytes", len(data))
+}
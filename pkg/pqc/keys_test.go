@@ -0,0 +1,166 @@
+package pqc
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedOf(size int) []byte {
+	return bytes.Repeat([]byte{0x2a}, size)
+}
+
+func marshalSeed(t *testing.T, seed []byte) []byte {
+	der, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: seed})
+	require.NoError(t, err)
+	return der
+}
+
+func marshalBoth(t *testing.T, seed, expanded []byte) []byte {
+	der, err := asn1.Marshal(struct {
+		Seed     []byte
+		Expanded []byte
+	}{seed, expanded})
+	require.NoError(t, err)
+	return der
+}
+
+func TestDecodePrivateKey(t *testing.T) {
+	for _, name := range []string{"ML-DSA-44", "ML-DSA-65", "ML-DSA-87", "ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"} {
+		t.Run(name, func(t *testing.T) {
+			alg, ok := ByName(name)
+			require.True(t, ok)
+			ks, ok := keySchemeFor(alg)
+			require.True(t, ok)
+
+			seed := seedOf(ks.seedSize)
+			expanded, public, err := ks.derive(seed)
+			require.NoError(t, err)
+			assert.Len(t, expanded, ks.expandedSize)
+			assert.Len(t, public, ks.publicSize)
+
+			octetString, err := asn1.Marshal(expanded)
+			require.NoError(t, err)
+
+			tests := []struct {
+				name     string
+				data     []byte
+				encoding string
+				seed     bool
+				expanded bool
+			}{
+				{"seed", marshalSeed(t, seed), KeyEncodingSeed, true, false},
+				{"expanded", octetString, KeyEncodingExpanded, false, true},
+				{"both", marshalBoth(t, seed, expanded), KeyEncodingBoth, true, true},
+				{"raw seed", seed, KeyEncodingSeed, true, false},
+				{"raw expanded", expanded, KeyEncodingExpanded, false, true},
+				{"raw expanded with public key", append(append([]byte{}, expanded...), public...), KeyEncodingExpanded, false, true},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					contents, err := DecodePrivateKey(alg, tt.data)
+					require.NoError(t, err)
+					assert.Equal(t, tt.encoding, contents.Encoding)
+					assert.Equal(t, public, contents.PublicKey)
+					if tt.seed {
+						assert.Equal(t, seed, contents.Seed)
+					} else {
+						assert.Nil(t, contents.Seed)
+					}
+					if tt.expanded {
+						assert.Equal(t, expanded, contents.Expanded)
+					} else {
+						assert.Nil(t, contents.Expanded)
+					}
+					if tt.seed && tt.expanded {
+						require.NotNil(t, contents.SeedMatches)
+						assert.True(t, *contents.SeedMatches)
+					} else {
+						assert.Nil(t, contents.SeedMatches)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestDecodePrivateKeySeedMismatch(t *testing.T) {
+	for _, name := range []string{"ML-DSA-65", "ML-KEM-768"} {
+		t.Run(name, func(t *testing.T) {
+			alg, _ := ByName(name)
+			ks, _ := keySchemeFor(alg)
+			seed := seedOf(ks.seedSize)
+			other := bytes.Repeat([]byte{0x01}, ks.seedSize)
+			expanded, public, err := ks.derive(other)
+			require.NoError(t, err)
+
+			contents, err := DecodePrivateKey(alg, marshalBoth(t, seed, expanded))
+			require.NoError(t, err)
+			require.NotNil(t, contents.SeedMatches)
+			assert.False(t, *contents.SeedMatches)
+			// The public key comes from the seed, not the expanded key.
+			assert.NotEqual(t, public, contents.PublicKey)
+		})
+	}
+}
+
+func TestDecodePrivateKeyInvalid(t *testing.T) {
+	mldsa44, _ := ByName("ML-DSA-44")
+	mlkem512, _ := ByName("ML-KEM-512")
+	slhdsa, _ := ByName("SLH-DSA-SHA2-128S")
+	composite, _ := ByName("MLDSA65-ECDSA-P256-SHA512")
+
+	tests := []struct {
+		name string
+		alg  Algorithm
+		data []byte
+		err  string
+	}{
+		{"short seed", mldsa44, marshalSeed(t, make([]byte, 16)), "seed is 16 bytes, want 32"},
+		{"empty seed", mlkem512, marshalSeed(t, nil), "seed is 0 bytes, want 64"},
+		{"short expanded", mldsa44, []byte{0x04, 0x02, 0x00, 0x00}, "expanded key is 2 bytes, want 2560"},
+		{"unknown length", mlkem512, make([]byte, 100), "unrecognised encoding of 100 bytes"},
+		{"SLH-DSA", slhdsa, make([]byte, 64), "cannot be decoded"},
+		{"composite", composite, make([]byte, 64), "cannot be decoded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePrivateKey(tt.alg, tt.data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestHasPrivateKeyContents(t *testing.T) {
+	for _, name := range []string{"ML-DSA-44", "ML-KEM-1024"} {
+		alg, _ := ByName(name)
+		assert.True(t, HasPrivateKeyContents(alg), name)
+	}
+	for _, name := range []string{"SLH-DSA-SHAKE-256F", "MLDSA65-ECDSA-P256-SHA512", "FN-DSA-512"} {
+		alg, _ := ByName(name)
+		assert.False(t, HasPrivateKeyContents(alg), name)
+	}
+}
+
+func TestPrivateKey(t *testing.T) {
+	oid, key, err := PrivateKey(pkcs8(t, "2.16.840.1.101.3.4.3.17", []byte{0x80, 0x00}))
+	require.NoError(t, err)
+	assert.Equal(t, "2.16.840.1.101.3.4.3.17", oid)
+	assert.Equal(t, []byte{0x80, 0x00}, key)
+
+	_, _, err = PrivateKey([]byte{0x30, 0x00})
+	assert.Error(t, err)
+}
+
+func pkcs8(t *testing.T, oid string, key []byte) []byte {
+	id, err := parseOID(oid)
+	require.NoError(t, err)
+	der, err := asn1.Marshal(privateKeyInfo{Algorithm: pkix.AlgorithmIdentifier{Algorithm: id}, PrivateKey: key})
+	require.NoError(t, err)
+	return der
+}
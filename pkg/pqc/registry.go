@@ -0,0 +1,305 @@
+package pqc
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	FamilyMLDSA  = "ML-DSA"
+	FamilyMLKEM  = "ML-KEM"
+	FamilySLHDSA = "SLH-DSA"
+	FamilyFNDSA  = "FN-DSA"
+)
+
+const (
+	StandardFIPS203   = "FIPS 203"
+	StandardFIPS204   = "FIPS 204"
+	StandardFIPS205   = "FIPS 205"
+	StandardComposite = "draft-ietf-lamps-pq-composite-sigs"
+	StandardOQS       = "OQS (pre-standard)"
+)
+
+// Algorithm describes a post-quantum or composite algorithm identifier.
+type Algorithm struct {
+	OID      string
+	Name     string
+	Family   string
+	Standard string
+	// Category is the NIST security category (1-5).
+	Category int
+	// Bits is the parameter set number shown in key listings
+	// (44 for ML-DSA-44, 768 for ML-KEM-768, 128 for SLH-DSA-*-128*).
+	Bits int
+	// Encoded public key and signature sizes in bytes, where fixed.
+	PublicKeySize int
+	SignatureSize int
+	Signature     bool
+	// For composite algorithms, Component is the ML-DSA parameter set and
+	// Classical the traditional algorithm it is paired with.
+	Composite        bool
+	Component        string
+	Classical        string
+	ClassicalKeyType string
+	ClassicalBits    int
+	Aliases          []string
+}
+
+// KeyType is the key type shown for keys of this algorithm: the family, or
+// "Composite ML-DSA" for composite algorithms.
+func (a Algorithm) KeyType() string {
+	if a.Composite {
+		return "Composite " + a.Family
+	}
+	return a.Family
+}
+
+var (
+	mlkemPublicKeySizes = map[int]int{512: 800, 768: 1184, 1024: 1568}
+	mldsaPublicKeySizes = map[int]int{44: 1312, 65: 1952, 87: 2592}
+	mldsaSignatureSizes = map[int]int{44: 2420, 65: 3309, 87: 4627}
+)
+
+// slhdsaSignatureSizes is indexed by security level and the s/f variant.
+var slhdsaSignatureSizes = map[int]map[bool]int{
+	128: {false: 7856, true: 17088},
+	192: {false: 16224, true: 35664},
+	256: {false: 29792, true: 49856},
+}
+
+func mlkem(oid, name string, category, bits int) Algorithm {
+	return Algorithm{
+		OID:           oid,
+		Name:          name,
+		Family:        FamilyMLKEM,
+		Standard:      StandardFIPS203,
+		Category:      category,
+		Bits:          bits,
+		PublicKeySize: mlkemPublicKeySizes[bits],
+	}
+}
+
+func mldsa(oid, name string, category, bits int) Algorithm {
+	return Algorithm{
+		OID:           oid,
+		Name:          name,
+		Family:        FamilyMLDSA,
+		Standard:      StandardFIPS204,
+		Category:      category,
+		Bits:          bits,
+		PublicKeySize: mldsaPublicKeySizes[bits],
+		SignatureSize: mldsaSignatureSizes[bits],
+		Signature:     true,
+	}
+}
+
+func slhdsa(oid, name string, category, bits int) Algorithm {
+	fast := strings.Contains(name, strconv.Itoa(bits)+"F")
+	return Algorithm{
+		OID:           oid,
+		Name:          name,
+		Family:        FamilySLHDSA,
+		Standard:      StandardFIPS205,
+		Category:      category,
+		Bits:          bits,
+		PublicKeySize: bits / 4,
+		SignatureSize: slhdsaSignatureSizes[bits][fast],
+		Signature:     true,
+	}
+}
+
+func composite(oid, name, component, classicalKeyType string, classicalBits int, classical string) Algorithm {
+	base, _ := ByName(component)
+	return Algorithm{
+		OID:              oid,
+		Name:             name,
+		Family:           FamilyMLDSA,
+		Standard:         StandardComposite,
+		Category:         base.Category,
+		Bits:             base.Bits,
+		Signature:        true,
+		Composite:        true,
+		Component:        component,
+		Classical:        classical,
+		ClassicalKeyType: classicalKeyType,
+		ClassicalBits:    classicalBits,
+	}
+}
+
+func oqs(oid, name, family string, category, bits int, signature bool, aliases ...string) Algorithm {
+	return Algorithm{OID: oid, Name: name, Family: family, Standard: StandardOQS, Category: category, Bits: bits, Signature: signature, Aliases: aliases}
+}
+
+var standardAlgorithms = []Algorithm{
+	// FIPS 203
+	mlkem("2.16.840.1.101.3.4.4.1", "ML-KEM-512", 1, 512),
+	mlkem("2.16.840.1.101.3.4.4.2", "ML-KEM-768", 3, 768),
+	mlkem("2.16.840.1.101.3.4.4.3", "ML-KEM-1024", 5, 1024),
+
+	// FIPS 204
+	mldsa("2.16.840.1.101.3.4.3.17", "ML-DSA-44", 2, 44),
+	mldsa("2.16.840.1.101.3.4.3.18", "ML-DSA-65", 3, 65),
+	mldsa("2.16.840.1.101.3.4.3.19", "ML-DSA-87", 5, 87),
+	mldsa("2.16.840.1.101.3.4.3.32", "HashML-DSA-44-SHA512", 2, 44),
+	mldsa("2.16.840.1.101.3.4.3.33", "HashML-DSA-65-SHA512", 3, 65),
+	mldsa("2.16.840.1.101.3.4.3.34", "HashML-DSA-87-SHA512", 5, 87),
+
+	// FIPS 205
+	slhdsa("2.16.840.1.101.3.4.3.20", "SLH-DSA-SHA2-128S", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.21", "SLH-DSA-SHA2-128F", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.22", "SLH-DSA-SHA2-192S", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.23", "SLH-DSA-SHA2-192F", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.24", "SLH-DSA-SHA2-256S", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.25", "SLH-DSA-SHA2-256F", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.26", "SLH-DSA-SHAKE-128S", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.27", "SLH-DSA-SHAKE-128F", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.28", "SLH-DSA-SHAKE-192S", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.29", "SLH-DSA-SHAKE-192F", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.30", "SLH-DSA-SHAKE-256S", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.31", "SLH-DSA-SHAKE-256F", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.35", "HashSLH-DSA-SHA2-128S-SHA256", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.36", "HashSLH-DSA-SHA2-128F-SHA256", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.37", "HashSLH-DSA-SHA2-192S-SHA512", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.38", "HashSLH-DSA-SHA2-192F-SHA512", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.39", "HashSLH-DSA-SHA2-256S-SHA512", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.40", "HashSLH-DSA-SHA2-256F-SHA512", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.41", "HashSLH-DSA-SHAKE-128S-SHAKE128", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.42", "HashSLH-DSA-SHAKE-128F-SHAKE128", 1, 128),
+	slhdsa("2.16.840.1.101.3.4.3.43", "HashSLH-DSA-SHAKE-192S-SHAKE256", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.44", "HashSLH-DSA-SHAKE-192F-SHAKE256", 3, 192),
+	slhdsa("2.16.840.1.101.3.4.3.45", "HashSLH-DSA-SHAKE-256S-SHAKE256", 5, 256),
+	slhdsa("2.16.840.1.101.3.4.3.46", "HashSLH-DSA-SHAKE-256F-SHAKE256", 5, 256),
+}
+
+// FIPS 206 (FN-DSA) has no NIST-assigned OIDs yet, so FN-DSA is only
+// recognised through the Open Quantum Safe Falcon identifiers below.
+var draftAlgorithms = []Algorithm{
+	oqs("1.3.6.1.4.1.2.267.12.4.4", "ML-DSA-44-ipd", FamilyMLDSA, 2, 44, true),
+	oqs("1.3.6.1.4.1.2.267.12.6.5", "ML-DSA-65-ipd", FamilyMLDSA, 3, 65, true),
+	oqs("1.3.6.1.4.1.2.267.12.8.7", "ML-DSA-87-ipd", FamilyMLDSA, 5, 87, true),
+	oqs("1.3.6.1.4.1.2.267.7.4.4", "Dilithium2", FamilyMLDSA, 2, 44, true),
+	oqs("1.3.6.1.4.1.2.267.7.6.5", "Dilithium3", FamilyMLDSA, 3, 65, true),
+	oqs("1.3.6.1.4.1.2.267.7.8.7", "Dilithium5", FamilyMLDSA, 5, 87, true),
+	oqs("1.3.9999.3.11", "FN-DSA-512", FamilyFNDSA, 1, 512, true, "Falcon-512"),
+	oqs("1.3.9999.3.14", "FN-DSA-1024", FamilyFNDSA, 5, 1024, true, "Falcon-1024"),
+	oqs("1.3.9999.3.16", "Falcon-padded-512", FamilyFNDSA, 1, 512, true),
+	oqs("1.3.9999.3.19", "Falcon-padded-1024", FamilyFNDSA, 5, 1024, true),
+	oqs("1.3.9999.6.4.13", "SPHINCS+-SHA2-128f-simple", FamilySLHDSA, 1, 128, true),
+	oqs("1.3.9999.6.4.16", "SPHINCS+-SHA2-128s-simple", FamilySLHDSA, 1, 128, true),
+	oqs("1.3.9999.6.5.10", "SPHINCS+-SHA2-192f-simple", FamilySLHDSA, 3, 192, true),
+	oqs("1.3.9999.6.7.13", "SPHINCS+-SHAKE-128f-simple", FamilySLHDSA, 1, 128, true),
+	oqs("1.3.6.1.4.1.22554.5.6.1", "Kyber512", FamilyMLKEM, 1, 512, false),
+	oqs("1.3.6.1.4.1.22554.5.6.2", "Kyber768", FamilyMLKEM, 3, 768, false),
+	oqs("1.3.6.1.4.1.22554.5.6.3", "Kyber1024", FamilyMLKEM, 5, 1024, false),
+}
+
+var registry []Algorithm
+
+var (
+	byOID  = map[string]Algorithm{}
+	byName = map[string]Algorithm{}
+)
+
+func register(algs ...Algorithm) {
+	for _, alg := range algs {
+		registry = append(registry, alg)
+		byOID[alg.OID] = alg
+		byName[normalizeName(alg.Name)] = alg
+		for _, alias := range alg.Aliases {
+			byName[normalizeName(alias)] = alg
+		}
+	}
+}
+
+func init() {
+	register(standardAlgorithms...)
+	register(draftAlgorithms...)
+	// Composite entries look up their ML-DSA component, so they are
+	// registered last.
+	register(
+		composite("1.3.6.1.5.5.7.6.37", "MLDSA44-RSA2048-PSS-SHA256", "ML-DSA-44", "RSA", 2048, "RSA-PSS 2048"),
+		composite("1.3.6.1.5.5.7.6.38", "MLDSA44-RSA2048-PKCS15-SHA256", "ML-DSA-44", "RSA", 2048, "RSA 2048"),
+		composite("1.3.6.1.5.5.7.6.39", "MLDSA44-Ed25519-SHA512", "ML-DSA-44", "Ed25519", 256, "Ed25519"),
+		composite("1.3.6.1.5.5.7.6.40", "MLDSA44-ECDSA-P256-SHA256", "ML-DSA-44", "ECDSA", 256, "ECDSA P-256"),
+		composite("1.3.6.1.5.5.7.6.41", "MLDSA65-RSA3072-PSS-SHA512", "ML-DSA-65", "RSA", 3072, "RSA-PSS 3072"),
+		composite("1.3.6.1.5.5.7.6.42", "MLDSA65-RSA3072-PKCS15-SHA512", "ML-DSA-65", "RSA", 3072, "RSA 3072"),
+		composite("1.3.6.1.5.5.7.6.43", "MLDSA65-RSA4096-PSS-SHA512", "ML-DSA-65", "RSA", 4096, "RSA-PSS 4096"),
+		composite("1.3.6.1.5.5.7.6.44", "MLDSA65-RSA4096-PKCS15-SHA512", "ML-DSA-65", "RSA", 4096, "RSA 4096"),
+		composite("1.3.6.1.5.5.7.6.45", "MLDSA65-ECDSA-P256-SHA512", "ML-DSA-65", "ECDSA", 256, "ECDSA P-256"),
+		composite("1.3.6.1.5.5.7.6.46", "MLDSA65-ECDSA-P384-SHA512", "ML-DSA-65", "ECDSA", 384, "ECDSA P-384"),
+		composite("1.3.6.1.5.5.7.6.47", "MLDSA65-ECDSA-brainpoolP256r1-SHA512", "ML-DSA-65", "ECDSA", 256, "ECDSA brainpoolP256r1"),
+		composite("1.3.6.1.5.5.7.6.48", "MLDSA65-Ed25519-SHA512", "ML-DSA-65", "Ed25519", 256, "Ed25519"),
+		composite("1.3.6.1.5.5.7.6.49", "MLDSA87-ECDSA-P384-SHA512", "ML-DSA-87", "ECDSA", 384, "ECDSA P-384"),
+		composite("1.3.6.1.5.5.7.6.50", "MLDSA87-ECDSA-brainpoolP384r1-SHA512", "ML-DSA-87", "ECDSA", 384, "ECDSA brainpoolP384r1"),
+		composite("1.3.6.1.5.5.7.6.51", "MLDSA87-Ed448-SHAKE256", "ML-DSA-87", "Ed448", 448, "Ed448"),
+		composite("1.3.6.1.5.5.7.6.52", "MLDSA87-RSA3072-PSS-SHA512", "ML-DSA-87", "RSA", 3072, "RSA-PSS 3072"),
+		composite("1.3.6.1.5.5.7.6.53", "MLDSA87-RSA4096-PSS-SHA512", "ML-DSA-87", "RSA", 4096, "RSA-PSS 4096"),
+		composite("1.3.6.1.5.5.7.6.54", "MLDSA87-ECDSA-P521-SHA512", "ML-DSA-87", "ECDSA", 521, "ECDSA P-521"),
+	)
+}
+
+// familyMarkers maps name fragments, in normalized form, to the family they
+// indicate. Order matters: the first match wins.
+var familyMarkers = []struct {
+	marker string
+	family string
+}{
+	{"mldsa", FamilyMLDSA},
+	{"dilithium", FamilyMLDSA},
+	{"mlkem", FamilyMLKEM},
+	{"kyber", FamilyMLKEM},
+	{"slhdsa", FamilySLHDSA},
+	{"sphincs", FamilySLHDSA},
+	{"fndsa", FamilyFNDSA},
+	{"falcon", FamilyFNDSA},
+	{"rainbow", "Rainbow"},
+}
+
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ByOID returns the registered algorithm with the given dotted OID.
+func ByOID(oid string) (Algorithm, bool) {
+	if alg, ok := byOID[oid]; ok {
+		return alg, true
+	}
+	return Algorithm{}, false
+}
+
+// ByName looks up an algorithm by name or alias, ignoring case and
+// punctuation ("ml-dsa-44", "MLDSA44" and "ML-DSA-44" are the same).
+func ByName(name string) (Algorithm, bool) {
+	if alg, ok := byName[normalizeName(name)]; ok {
+		return alg, true
+	}
+	return Algorithm{}, false
+}
+
+// FamilyFromName returns the PQC family a free-form algorithm name refers
+// to, or "" when it names no PQC algorithm.
+func FamilyFromName(name string) string {
+	if alg, ok := ByName(name); ok {
+		return alg.Family
+	}
+	normalized := normalizeName(name)
+	for _, m := range familyMarkers {
+		if strings.Contains(normalized, m.marker) {
+			return m.family
+		}
+	}
+	return ""
+}
+
+// All returns every registered algorithm.
+func All() []Algorithm {
+	algs := make([]Algorithm, len(registry))
+	copy(algs, registry)
+	return algs
+}
@@ -0,0 +1,208 @@
+package pqc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByOID(t *testing.T) {
+	tests := []struct {
+		oid      string
+		name     string
+		family   string
+		category int
+		bits     int
+	}{
+		{"2.16.840.1.101.3.4.4.1", "ML-KEM-512", FamilyMLKEM, 1, 512},
+		{"2.16.840.1.101.3.4.4.2", "ML-KEM-768", FamilyMLKEM, 3, 768},
+		{"2.16.840.1.101.3.4.4.3", "ML-KEM-1024", FamilyMLKEM, 5, 1024},
+		{"2.16.840.1.101.3.4.3.17", "ML-DSA-44", FamilyMLDSA, 2, 44},
+		{"2.16.840.1.101.3.4.3.18", "ML-DSA-65", FamilyMLDSA, 3, 65},
+		{"2.16.840.1.101.3.4.3.19", "ML-DSA-87", FamilyMLDSA, 5, 87},
+		{"2.16.840.1.101.3.4.3.32", "HashML-DSA-44-SHA512", FamilyMLDSA, 2, 44},
+		{"2.16.840.1.101.3.4.3.20", "SLH-DSA-SHA2-128S", FamilySLHDSA, 1, 128},
+		{"2.16.840.1.101.3.4.3.23", "SLH-DSA-SHA2-192F", FamilySLHDSA, 3, 192},
+		{"2.16.840.1.101.3.4.3.31", "SLH-DSA-SHAKE-256F", FamilySLHDSA, 5, 256},
+		{"2.16.840.1.101.3.4.3.46", "HashSLH-DSA-SHAKE-256F-SHAKE256", FamilySLHDSA, 5, 256},
+		{"1.3.6.1.5.5.7.6.40", "MLDSA44-ECDSA-P256-SHA256", FamilyMLDSA, 2, 44},
+		{"1.3.6.1.5.5.7.6.51", "MLDSA87-Ed448-SHAKE256", FamilyMLDSA, 5, 87},
+		{"1.3.9999.3.14", "FN-DSA-1024", FamilyFNDSA, 5, 1024},
+		{"1.3.6.1.4.1.2.267.7.6.5", "Dilithium3", FamilyMLDSA, 3, 65},
+		{"1.3.6.1.4.1.22554.5.6.2", "Kyber768", FamilyMLKEM, 3, 768},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, ok := ByOID(tt.oid)
+			require.True(t, ok)
+			assert.Equal(t, tt.name, alg.Name)
+			assert.Equal(t, tt.family, alg.Family)
+			assert.Equal(t, tt.category, alg.Category)
+			assert.Equal(t, tt.bits, alg.Bits)
+		})
+	}
+
+	_, ok := ByOID("1.2.840.113549.1.1.11")
+	assert.False(t, ok)
+}
+
+func TestCompositeAlgorithms(t *testing.T) {
+	alg, ok := ByOID("1.3.6.1.5.5.7.6.45")
+	require.True(t, ok)
+	assert.True(t, alg.Composite)
+	assert.True(t, alg.Signature)
+	assert.Equal(t, "ML-DSA-65", alg.Component)
+	assert.Equal(t, "ECDSA P-256", alg.Classical)
+	assert.Equal(t, StandardComposite, alg.Standard)
+
+	for _, alg := range All() {
+		if alg.Composite {
+			assert.NotZero(t, alg.Category, alg.Name)
+		}
+	}
+}
+
+func TestRegistryUnique(t *testing.T) {
+	oids := map[string]bool{}
+	names := map[string]bool{}
+	for _, alg := range All() {
+		assert.False(t, oids[alg.OID], "duplicate OID %s", alg.OID)
+		assert.False(t, names[normalizeName(alg.Name)], "duplicate name %s", alg.Name)
+		oids[alg.OID] = true
+		names[normalizeName(alg.Name)] = true
+		_, err := x509.ParseOID(alg.OID)
+		assert.NoError(t, err, alg.OID)
+	}
+}
+
+func TestByName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ml-dsa-44", "ML-DSA-44"},
+		{"MLDSA65", "ML-DSA-65"},
+		{"ML-KEM-1024", "ML-KEM-1024"},
+		{"slh-dsa-sha2-128s", "SLH-DSA-SHA2-128S"},
+		{"falcon-512", "FN-DSA-512"},
+		{"fn-dsa-1024", "FN-DSA-1024"},
+		{"dilithium2", "Dilithium2"},
+		{"id-MLDSA65-ECDSA-P256-SHA512", ""},
+		{"MLDSA65-ECDSA-P256-SHA512", "MLDSA65-ECDSA-P256-SHA512"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			alg, ok := ByName(tt.input)
+			if tt.expected == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, alg.Name)
+		})
+	}
+}
+
+func TestFamilyFromName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"ml-kem-768", FamilyMLKEM},
+		{"ml-dsa-44", FamilyMLDSA},
+		{"ml-dsa-45", FamilyMLDSA},
+		{"slh-dsa-sha2-128s", FamilySLHDSA},
+		{"fn-dsa", FamilyFNDSA},
+		{"falcon-512", FamilyFNDSA},
+		{"dilithium2", FamilyMLDSA},
+		{"kyber512", FamilyMLKEM},
+		{"sphincs-sha2-128s", FamilySLHDSA},
+		{"rainbow", "Rainbow"},
+		{"rsaEncryption", ""},
+		{"ecdsa-with-sha256", ""},
+		{"SHA256-RSA", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FamilyFromName(tt.name))
+		})
+	}
+}
+
+func TestAlgorithmOIDs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "classical"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	sigOID, err := SignatureAlgorithmOID(cert.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.840.10045.4.3.2", sigOID)
+
+	keyOID, err := PublicKeyAlgorithmOID(cert.RawSubjectPublicKeyInfo)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.840.10045.2.1", keyOID)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	privOID, err := PrivateKeyAlgorithmOID(pkcs8)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.840.10045.2.1", privOID)
+
+	sig, pub := Detect(cert.Raw, cert.RawSubjectPublicKeyInfo)
+	assert.Nil(t, sig)
+	assert.Nil(t, pub)
+
+	_, err = SignatureAlgorithmOID([]byte("not der"))
+	assert.Error(t, err)
+}
+
+func TestNames(t *testing.T) {
+	a, _ := ByName("ML-DSA-44")
+	b, _ := ByName("ML-KEM-768")
+	assert.Equal(t, []string{"ML-DSA-44", "ML-KEM-768"}, Names(&a, nil, &a, &b))
+	assert.Nil(t, Names(nil, nil))
+}
+
+func TestAlgorithmSizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicKey int
+		signature int
+	}{
+		{"ML-DSA-44", 1312, 2420},
+		{"ML-DSA-87", 2592, 4627},
+		{"ML-KEM-768", 1184, 0},
+		{"SLH-DSA-SHA2-128S", 32, 7856},
+		{"SLH-DSA-SHAKE-192F", 48, 35664},
+		{"HashSLH-DSA-SHA2-256F-SHA512", 64, 49856},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, ok := ByName(tt.name)
+			require.True(t, ok)
+			assert.Equal(t, tt.publicKey, alg.PublicKeySize)
+			assert.Equal(t, tt.signature, alg.SignatureSize)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package pqc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+	slh "github.com/cloudflare/circl/sign/slhdsa"
+)
+
+var ErrInvalidSignature = fmt.Errorf("invalid signature")
+var ErrUnsupportedAlgorithm = fmt.Errorf("signature verification not supported")
+
+// compositePrefix starts the message both components of a composite
+// signature sign (draft-ietf-lamps-pq-composite-sigs).
+const compositePrefix = "CompositeAlgorithmSignatures2025"
+
+var mldsaSchemes = map[string]sign.Scheme{
+	"ML-DSA-44": mldsa44.Scheme(),
+	"ML-DSA-65": mldsa65.Scheme(),
+	"ML-DSA-87": mldsa87.Scheme(),
+}
+
+// scheme returns the pure ML-DSA or SLH-DSA scheme of alg. Pre-hash
+// variants and pre-standard identifiers have none.
+func scheme(alg Algorithm) (sign.Scheme, bool) {
+	if s, ok := mldsaSchemes[alg.Name]; ok {
+		return s, true
+	}
+	if alg.Family == FamilySLHDSA && alg.Standard == StandardFIPS205 && !strings.HasPrefix(alg.Name, "Hash") {
+		if id, err := slh.IDByName(alg.Name); err == nil {
+			return id.Scheme(), true
+		}
+	}
+	return nil, false
+}
+
+// Verify checks signature over message against the DER-encoded
+// SubjectPublicKeyInfo of the signer, for the signature algorithm with the
+// given OID. X.509 signatures use the empty context.
+func Verify(oid string, spki, message, signature []byte) error {
+	alg, ok := ByOID(oid)
+	if !ok || !alg.Signature {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, oid)
+	}
+	keyOID, key, err := PublicKey(spki)
+	if err != nil {
+		return err
+	}
+	if keyOID != alg.OID {
+		keyName := keyOID
+		if keyAlg, ok := ByOID(keyOID); ok {
+			keyName = keyAlg.Name
+		}
+		return fmt.Errorf("%s key cannot verify a %s signature", keyName, alg.Name)
+	}
+
+	if alg.Composite {
+		return verifyComposite(alg, key, message, signature)
+	}
+	return verifyPure(alg, key, message, signature, nil)
+}
+
+func verifyPure(alg Algorithm, key, message, signature, context []byte) error {
+	s, ok := scheme(alg)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg.Name)
+	}
+	pub, err := s.UnmarshalBinaryPublicKey(key)
+	if err != nil {
+		return fmt.Errorf("invalid %s public key: %w", alg.Name, err)
+	}
+	if len(signature) != s.SignatureSize() {
+		return fmt.Errorf("%w: %s signature is %d bytes, want %d", ErrInvalidSignature, alg.Name, len(signature), s.SignatureSize())
+	}
+	if !s.Verify(pub, message, signature, &sign.SignatureOpts{Context: string(context)}) {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, alg.Name)
+	}
+	return nil
+}
+
+// compositeMessage builds M' = Prefix || Label || len(ctx) || ctx || PH(M)
+// with an empty context, where PH is named by the last part of the
+// composite algorithm name.
+func compositeMessage(alg Algorithm, message []byte) ([]byte, error) {
+	var digest []byte
+	switch {
+	case strings.HasSuffix(alg.Name, "-SHA256"):
+		sum := sha256.Sum256(message)
+		digest = sum[:]
+	case strings.HasSuffix(alg.Name, "-SHA512"):
+		sum := sha512.Sum512(message)
+		digest = sum[:]
+	case strings.HasSuffix(alg.Name, "-SHAKE256"):
+		digest = sha3.SumSHAKE256(message, 64)
+	default:
+		return nil, fmt.Errorf("%w: unknown pre-hash for %s", ErrUnsupportedAlgorithm, alg.Name)
+	}
+
+	m := []byte(compositePrefix + compositeLabel(alg))
+	m = append(m, 0)
+	return append(m, digest...), nil
+}
+
+func compositeLabel(alg Algorithm) string {
+	return "COMPSIG-" + alg.Name
+}
+
+func verifyComposite(alg Algorithm, key, message, signature []byte) error {
+	component, ok := ByName(alg.Component)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg.Name)
+	}
+	if len(key) < component.PublicKeySize || len(signature) < component.SignatureSize {
+		return fmt.Errorf("%w: %s signature or key is truncated", ErrInvalidSignature, alg.Name)
+	}
+	mldsaKey, tradKey := key[:component.PublicKeySize], key[component.PublicKeySize:]
+	mldsaSig, tradSig := signature[:component.SignatureSize], signature[component.SignatureSize:]
+
+	m, err := compositeMessage(alg, message)
+	if err != nil {
+		return err
+	}
+	if err := verifyPure(component, mldsaKey, m, mldsaSig, []byte(compositeLabel(alg))); err != nil {
+		return err
+	}
+	return verifyClassical(alg, tradKey, m, tradSig)
+}
+
+// classicalHash is the digest the traditional component of a composite
+// signature uses, following the key size.
+func classicalHash(alg Algorithm) crypto.Hash {
+	switch {
+	case alg.ClassicalBits >= 521:
+		return crypto.SHA512
+	case alg.ClassicalBits == 384 || alg.ClassicalBits == 4096:
+		return crypto.SHA384
+	default:
+		return crypto.SHA256
+	}
+}
+
+func verifyClassical(alg Algorithm, key, message, signature []byte) error {
+	invalid := fmt.Errorf("%w: %s", ErrInvalidSignature, alg.Classical)
+
+	switch alg.ClassicalKeyType {
+	case "Ed25519":
+		if len(key) != ed25519.PublicKeySize || !ed25519.Verify(key, message, signature) {
+			return invalid
+		}
+		return nil
+	case "Ed448":
+		if len(key) != ed448.PublicKeySize || !ed448.Verify(key, message, signature, "") {
+			return invalid
+		}
+		return nil
+	}
+
+	h := classicalHash(alg).New()
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	switch alg.ClassicalKeyType {
+	case "RSA":
+		pub, err := x509.ParsePKCS1PublicKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid %s public key: %w", alg.Classical, err)
+		}
+		if strings.HasPrefix(alg.Classical, "RSA-PSS") {
+			opts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: classicalHash(alg)}
+			err = rsa.VerifyPSS(pub, classicalHash(alg), digest, signature, opts)
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, classicalHash(alg), digest, signature)
+		}
+		if err != nil {
+			return invalid
+		}
+		return nil
+	case "ECDSA":
+		var curve elliptic.Curve
+		switch alg.Classical {
+		case "ECDSA P-256":
+			curve = elliptic.P256()
+		case "ECDSA P-384":
+			curve = elliptic.P384()
+		case "ECDSA P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg.Classical)
+		}
+		pub, err := ecdsa.ParseUncompressedPublicKey(curve, key)
+		if err != nil {
+			return fmt.Errorf("invalid %s public key: %w", alg.Classical, err)
+		}
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return invalid
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg.Classical)
+}
@@ -1,6 +1,8 @@
 package privatekey
 
 import (
+	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -9,10 +11,14 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
+	"github.com/marco-introini/certinfo/pkg/cache"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
 	certpem "github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/marco-introini/certinfo/pkg/strength"
+	"github.com/marco-introini/certinfo/pkg/walk"
 )
 
 type KeyInfo struct {
@@ -23,144 +29,112 @@ type KeyInfo struct {
 	Bits          int
 	Curve         string
 	IsQuantumSafe bool
+	SPKISHA256    string
+	SPKISHA256Hex string
+	Strength      strength.Strength
+	PQC           *PQCKeyInfo
+}
+
+// PQCKeyInfo describes a post-quantum private key. The encoding, sizes and
+// seed check are only filled in for ML-DSA and ML-KEM keys whose contents
+// could be decoded.
+type PQCKeyInfo struct {
+	ParameterSet  string
+	Category      int
+	Encoding      string
+	SeedSize      int
+	ExpandedSize  int
+	PublicKeySize int
+	SeedMatches   *bool
+	Error         string
 }
 
 type KeySummary struct {
 	Filename      string
 	Encoding      string
 	KeyType       string
+	Algorithm     string
 	Bits          int
 	Curve         string
 	IsQuantumSafe bool
+	SPKISHA256    string
+	Strength      strength.Strength
 }
 
-func isPQCCheck(algo string) bool {
-	lowerAlgo := strings.ToLower(algo)
-	return strings.Contains(lowerAlgo, "ml-kem") ||
-		strings.Contains(lowerAlgo, "ml-dsa") ||
-		strings.Contains(lowerAlgo, "slh-dsa") ||
-		strings.Contains(lowerAlgo, "fn-dsa") ||
-		strings.Contains(lowerAlgo, "falcon") ||
-		strings.Contains(lowerAlgo, "dilithium") ||
-		strings.Contains(lowerAlgo, "kyber") ||
-		strings.Contains(lowerAlgo, "sphincs") ||
-		strings.Contains(lowerAlgo, "rainbow")
+func setPQCKey(info *KeyInfo, alg pqc.Algorithm) {
+	info.KeyType = alg.KeyType()
+	info.Algorithm = alg.Name
+	info.Bits = alg.Bits
+	info.IsQuantumSafe = true
+	info.Strength = strength.ForAlgorithm(alg)
+	info.PQC = &PQCKeyInfo{ParameterSet: alg.Name, Category: alg.Category}
 }
 
-func detectPQCFromText(data []byte) []string {
-	var pqcTypes []string
-	dataStr := string(data)
-	if strings.Contains(dataStr, "ML-DSA-44") || strings.Contains(dataStr, "MLDSA44") {
-		pqcTypes = append(pqcTypes, "ML-DSA-44")
-	}
-	if strings.Contains(dataStr, "ML-DSA-65") || strings.Contains(dataStr, "MLDSA65") {
-		pqcTypes = append(pqcTypes, "ML-DSA-65")
-	}
-	if strings.Contains(dataStr, "ML-DSA-87") || strings.Contains(dataStr, "MLDSA87") {
-		pqcTypes = append(pqcTypes, "ML-DSA-87")
-	}
-	if strings.Contains(dataStr, "ML-KEM-512") || strings.Contains(dataStr, "MLKEM512") {
-		pqcTypes = append(pqcTypes, "ML-KEM-512")
-	}
-	if strings.Contains(dataStr, "ML-KEM-768") || strings.Contains(dataStr, "MLKEM768") {
-		pqcTypes = append(pqcTypes, "ML-KEM-768")
-	}
-	if strings.Contains(dataStr, "ML-KEM-1024") || strings.Contains(dataStr, "MLKEM1024") {
-		pqcTypes = append(pqcTypes, "ML-KEM-1024")
-	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-128S") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-128S")
-	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-128F") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-128F")
-	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-192S") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-192S")
+// setPQCContents records the decoded seed and expanded key of an ML-DSA or
+// ML-KEM private key, and fingerprints the public key they derive.
+func setPQCContents(info *KeyInfo, alg pqc.Algorithm, contents *pqc.PrivateKeyContents) {
+	info.PQC.Encoding = contents.Encoding
+	info.PQC.SeedSize = len(contents.Seed)
+	info.PQC.ExpandedSize = len(contents.Expanded)
+	info.PQC.PublicKeySize = len(contents.PublicKey)
+	info.PQC.SeedMatches = contents.SeedMatches
+	if spki, err := pqc.MarshalPublicKey(alg.OID, contents.PublicKey); err == nil {
+		info.SPKISHA256, info.SPKISHA256Hex = fingerprint.SPKI(spki)
 	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-192F") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-192F")
-	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-256S") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-256S")
-	}
-	if strings.Contains(dataStr, "SLH-DSA-SHA2-256F") {
-		pqcTypes = append(pqcTypes, "SLH-DSA-SHA2-256F")
-	}
-	if strings.Contains(dataStr, "FALCON-512") {
-		pqcTypes = append(pqcTypes, "FALCON-512")
-	}
-	if strings.Contains(dataStr, "FALCON-1024") {
-		pqcTypes = append(pqcTypes, "FALCON-1024")
-	}
-	return pqcTypes
 }
 
-func detectPQCFromOID(oid string) string {
-	oidMap := map[string]string{
-		"2.16.840.1.101.3.4.3.17": "ML-DSA-44",
-		"2.16.840.1.101.3.4.3.18": "ML-DSA-65",
-		"2.16.840.1.101.3.4.3.19": "ML-DSA-87",
-		"2.16.840.1.101.3.4.4.1":  "ML-KEM-512",
-		"2.16.840.1.101.3.4.4.2":  "ML-KEM-768",
-		"2.16.840.1.101.3.4.4.3":  "ML-KEM-1024",
-		"2.16.840.1.101.3.4.3.23": "SLH-DSA-SHA2-128S",
-		"2.16.840.1.101.3.4.3.24": "SLH-DSA-SHA2-128F",
-		"2.16.840.1.101.3.4.3.25": "SLH-DSA-SHA2-192S",
-		"2.16.840.1.101.3.4.3.26": "SLH-DSA-SHA2-192F",
-		"2.16.840.1.101.3.4.3.27": "SLH-DSA-SHA2-256S",
-		"2.16.840.1.101.3.4.3.28": "SLH-DSA-SHA2-256F",
-		"2.16.840.1.101.3.4.3.29": "FALCON-512",
-		"2.16.840.1.101.3.4.3.30": "FALCON-1024",
+// decodeLabelledPQCKey identifies the parameter set of a key in a
+// non-standard PQC PEM block from the size of its contents. Only an
+// unambiguous match counts: the parameter sets of a family share the seed
+// size, so a key holding only its seed is reported with the family alone.
+func decodeLabelledPQCKey(info *KeyInfo, der []byte, family string) bool {
+	var matches []pqc.Algorithm
+	var contents *pqc.PrivateKeyContents
+	for _, alg := range pqc.All() {
+		if alg.Family != family || !pqc.HasPrivateKeyContents(alg) {
+			continue
+		}
+		if c, err := pqc.DecodePrivateKey(alg, der); err == nil {
+			matches = append(matches, alg)
+			contents = c
+		}
 	}
-	return oidMap[oid]
+	switch len(matches) {
+	case 0:
+		return false
+	case 1:
+		setPQCKey(info, matches[0])
+		setPQCContents(info, matches[0], contents)
+		return true
+	}
+	info.KeyType = family
+	info.Algorithm = family
+	info.IsQuantumSafe = true
+	info.PQC = &PQCKeyInfo{
+		ParameterSet: family + " (parameter set unknown)",
+		Encoding:     contents.Encoding,
+		SeedSize:     len(contents.Seed),
+		ExpandedSize: len(contents.Expanded),
+	}
+	return true
 }
 
-func detectPQCOIDFromError(errMsg string) string {
-	oidMap := map[string]string{
-		"2.16.840.1.101.3.4.3.17": "ML-DSA-44",
-		"2.16.840.1.101.3.4.3.18": "ML-DSA-65",
-		"2.16.840.1.101.3.4.3.19": "ML-DSA-87",
-		"2.16.840.1.101.3.4.4.1":  "ML-KEM-512",
-		"2.16.840.1.101.3.4.4.2":  "ML-KEM-768",
-		"2.16.840.1.101.3.4.4.3":  "ML-KEM-1024",
-		"2.16.840.1.101.3.4.3.23": "SLH-DSA-SHA2-128S",
-		"2.16.840.1.101.3.4.3.24": "SLH-DSA-SHA2-128F",
-		"2.16.840.1.101.3.4.3.25": "SLH-DSA-SHA2-192S",
-		"2.16.840.1.101.3.4.3.26": "SLH-DSA-SHA2-192F",
-		"2.16.840.1.101.3.4.3.27": "SLH-DSA-SHA2-256S",
-		"2.16.840.1.101.3.4.3.28": "SLH-DSA-SHA2-256F",
-		"2.16.840.1.101.3.4.3.29": "FALCON-512",
-		"2.16.840.1.101.3.4.3.30": "FALCON-1024",
-	}
-	for oid, name := range oidMap {
-		if strings.Contains(errMsg, oid) {
-			return name
-		}
-	}
-	return ""
+// pemLabelFamilies maps the non-standard PQC PEM labels to the family they
+// announce, for keys whose contents are not PKCS#8.
+var pemLabelFamilies = map[string]string{
+	string(certpem.TypeMLDSAPrivateKey): pqc.FamilyMLDSA,
+	string(certpem.TypeMLKEMPrivateKey): pqc.FamilyMLKEM,
 }
 
-func getPQCBits(pqcType string) int {
-	bitMap := map[string]int{
-		"ML-DSA-44":         44,
-		"ML-DSA-65":         65,
-		"ML-DSA-87":         87,
-		"ML-KEM-512":        512,
-		"ML-KEM-768":        768,
-		"ML-KEM-1024":       1024,
-		"SLH-DSA-SHA2-128S": 128,
-		"SLH-DSA-SHA2-128F": 128,
-		"SLH-DSA-SHA2-192S": 192,
-		"SLH-DSA-SHA2-192F": 192,
-		"SLH-DSA-SHA2-256S": 256,
-		"SLH-DSA-SHA2-256F": 256,
-		"FALCON-512":        512,
-		"FALCON-1024":       1024,
-		"FN-DSA-128":        128,
-		"FN-DSA-192":        192,
-		"FN-DSA-256":        256,
+func setPublicKey(info *KeyInfo, pub crypto.PublicKey) {
+	if s := strength.ForPublicKey(pub); s.Known() {
+		info.Strength = s
 	}
-	return bitMap[pqcType]
+	spki, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return
+	}
+	info.SPKISHA256, info.SPKISHA256Hex = fingerprint.SPKI(spki)
 }
 
 var ErrEncryptedKey = fmt.Errorf("private key is encrypted, password required")
@@ -194,13 +168,7 @@ func parsePrivateKeyData(data []byte, filename string, password string) (*KeyInf
 			if block == nil {
 				break
 			}
-			blockType := block.Type
-			if blockType == string(certpem.TypeEncryptedKey) ||
-				blockType == string(certpem.TypeECPrivateKey) ||
-				blockType == string(certpem.TypePrivateKey) ||
-				blockType == string(certpem.TypeRSAPrivateKey) ||
-				blockType == string(certpem.TypeMLKEMPrivateKey) ||
-				blockType == string(certpem.TypeMLDSAPrivateKey) {
+			if certpem.KindOf(certpem.BlockType(block.Type)) == certpem.KindPrivateKey {
 				found = true
 				break
 			}
@@ -212,7 +180,7 @@ func parsePrivateKeyData(data []byte, filename string, password string) (*KeyInf
 		encoding = "PEM"
 	} else {
 		encoding = "DER"
-		return parseKey(data, filename, encoding, nil)
+		return parseKey(data, filename, encoding, "")
 	}
 
 	decryptedKeyBytes, err := decryptKey(block, password)
@@ -220,9 +188,7 @@ func parsePrivateKeyData(data []byte, filename string, password string) (*KeyInf
 		return nil, err
 	}
 
-	pqcTypes := detectPQCFromText(data)
-
-	return parseKey(decryptedKeyBytes, filename, encoding, pqcTypes)
+	return parseKey(decryptedKeyBytes, filename, encoding, pemLabelFamilies[block.Type])
 }
 
 func ParsePrivateKey(filePath string, password ...string) (*KeyInfo, error) {
@@ -248,7 +214,7 @@ func ParsePrivateKeyFromBytes(data []byte, filename string, password ...string)
 	return parsePrivateKeyData(data, filename, pwd)
 }
 
-func parseKey(der []byte, filename string, encoding string, pqcTypes []string) (*KeyInfo, error) {
+func parseKey(der []byte, filename string, encoding string, labelFamily string) (*KeyInfo, error) {
 	info := &KeyInfo{Filename: filename, Encoding: encoding}
 
 	key, err := x509.ParsePKCS1PrivateKey(der)
@@ -256,6 +222,7 @@ func parseKey(der []byte, filename string, encoding string, pqcTypes []string) (
 		info.KeyType = "RSA"
 		info.Bits = key.N.BitLen()
 		info.Algorithm = "PKCS#1 v1.5"
+		setPublicKey(info, &key.PublicKey)
 		return info, nil
 	}
 
@@ -265,11 +232,35 @@ func parseKey(der []byte, filename string, encoding string, pqcTypes []string) (
 		info.Bits = ecKey.Curve.Params().BitSize
 		info.Curve = ecKey.Curve.Params().Name
 		info.Algorithm = "ECDSA"
+		setPublicKey(info, &ecKey.PublicKey)
 		return info, nil
 	}
 
 	pkcs8Key, err := x509.ParsePKCS8PrivateKey(der)
+
+	// PQC keys are identified by their PKCS#8 algorithm OID, whether or not
+	// crypto/x509 can parse the key material itself.
+	if oid, keyData, oidErr := pqc.PrivateKey(der); oidErr == nil {
+		if alg, ok := pqc.ByOID(oid); ok {
+			setPQCKey(info, alg)
+			if signer, ok := pkcs8Key.(crypto.Signer); ok && err == nil {
+				setPublicKey(info, signer.Public())
+			}
+			if pqc.HasPrivateKeyContents(alg) {
+				if contents, decodeErr := pqc.DecodePrivateKey(alg, keyData); decodeErr == nil {
+					setPQCContents(info, alg, contents)
+				} else {
+					info.PQC.Error = decodeErr.Error()
+				}
+			}
+			return info, nil
+		}
+	}
+
 	if err == nil {
+		if signer, ok := pkcs8Key.(crypto.Signer); ok {
+			setPublicKey(info, signer.Public())
+		}
 		switch key := pkcs8Key.(type) {
 		case *rsa.PrivateKey:
 			info.KeyType = "RSA"
@@ -300,169 +291,155 @@ func parseKey(der []byte, filename string, encoding string, pqcTypes []string) (
 			info.Algorithm = "EdDSA"
 			return info, nil
 		default:
-			typeName := fmt.Sprintf("%T", key)
-			info.KeyType = typeName
+			info.KeyType = fmt.Sprintf("%T", key)
 			info.Algorithm = "PKCS#8"
-			if isPQCCheck(typeName) {
-				info.IsQuantumSafe = true
-				if strings.Contains(strings.ToLower(typeName), "mlkem") {
-					info.KeyType = "ML-KEM"
-					info.Algorithm = "ML-KEM"
-					info.Bits = 0
-				} else if strings.Contains(strings.ToLower(typeName), "mldsa") {
-					info.KeyType = "ML-DSA"
-					info.Algorithm = "ML-DSA"
-					info.Bits = 0
-				} else if strings.Contains(strings.ToLower(typeName), "slhdsa") {
-					info.KeyType = "SLH-DSA"
-					info.Algorithm = "SLH-DSA"
-					info.Bits = 0
-				} else if strings.Contains(strings.ToLower(typeName), "fndsa") {
-					info.KeyType = "FN-DSA"
-					info.Algorithm = "FN-DSA"
-					info.Bits = 0
-				}
-			}
-			for _, pqc := range pqcTypes {
-				info.IsQuantumSafe = true
-				info.Bits = getPQCBits(pqc)
-				if strings.HasPrefix(pqc, "ML-DSA") {
-					info.KeyType = "ML-DSA"
-					info.Algorithm = "ML-DSA"
-				} else if strings.HasPrefix(pqc, "ML-KEM") {
-					info.KeyType = "ML-KEM"
-					info.Algorithm = "ML-KEM"
-				} else if strings.HasPrefix(pqc, "SLH-DSA") {
-					info.KeyType = "SLH-DSA"
-					info.Algorithm = "SLH-DSA"
-				} else if strings.HasPrefix(pqc, "FALCON") {
-					info.KeyType = "FALCON"
-					info.Algorithm = "FALCON"
-				}
-			}
 			return info, nil
 		}
 	}
 
-	pqcOID := detectPQCOIDFromError(err.Error())
-	if pqcOID != "" {
-		info.IsQuantumSafe = true
-		info.Algorithm = "PKCS#8"
-		info.Bits = getPQCBits(pqcOID)
-		if strings.HasPrefix(pqcOID, "ML-DSA") {
-			info.KeyType = "ML-DSA"
-		} else if strings.HasPrefix(pqcOID, "ML-KEM") {
-			info.KeyType = "ML-KEM"
-		} else if strings.HasPrefix(pqcOID, "SLH-DSA") {
-			info.KeyType = "SLH-DSA"
-		} else if strings.HasPrefix(pqcOID, "FALCON") {
-			info.KeyType = "FALCON"
+	if labelFamily != "" {
+		if decodeLabelledPQCKey(info, der, labelFamily) {
+			return info, nil
 		}
+		info.KeyType = labelFamily
+		info.Algorithm = labelFamily
+		info.IsQuantumSafe = true
 		return info, nil
 	}
 
 	info.KeyType = fmt.Sprintf("%T", pkcs8Key)
 	info.Algorithm = "Unknown"
-	for _, pqc := range pqcTypes {
-		info.IsQuantumSafe = true
-		info.Bits = getPQCBits(pqc)
-		if strings.HasPrefix(pqc, "ML-DSA") {
-			info.KeyType = "ML-DSA"
-			info.Algorithm = "ML-DSA"
-		} else if strings.HasPrefix(pqc, "ML-KEM") {
-			info.KeyType = "ML-KEM"
-			info.Algorithm = "ML-KEM"
-		} else if strings.HasPrefix(pqc, "SLH-DSA") {
-			info.KeyType = "SLH-DSA"
-			info.Algorithm = "SLH-DSA"
-		} else if strings.HasPrefix(pqc, "FALCON") {
-			info.KeyType = "FALCON"
-			info.Algorithm = "FALCON"
-		}
-	}
-	if info.KeyType == "<nil>" && len(pqcTypes) > 0 {
-		info.IsQuantumSafe = true
-	}
 	return info, nil
 }
 
-func SummarizeDirectory(dirPath string, password ...string) ([]KeySummary, error) {
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		return nil, err
+// NewKeySummary returns the directory listing row of key.
+func NewKeySummary(filename string, key *KeyInfo) KeySummary {
+	return KeySummary{
+		Filename:      filename,
+		Encoding:      key.Encoding,
+		KeyType:       key.KeyType,
+		Algorithm:     key.Algorithm,
+		Bits:          key.Bits,
+		Curve:         key.Curve,
+		IsQuantumSafe: key.IsQuantumSafe,
+		SPKISHA256:    key.SPKISHA256,
+		Strength:      key.Strength,
 	}
+}
 
-	summaries := make([]KeySummary, 0, len(entries))
-
-	pwd := ""
-	if len(password) > 0 {
-		pwd = password[0]
+// WeakerThan returns the summaries whose security strength is known and
+// below bits.
+func WeakerThan(summaries []KeySummary, bits int) []KeySummary {
+	weak := make([]KeySummary, 0, len(summaries))
+	for _, s := range summaries {
+		if s.Strength.Below(bits) {
+			weak = append(weak, s)
+		}
 	}
+	return weak
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
+// fileSummary is what one file contributes to a directory summary.
+type fileSummary struct {
+	summary *KeySummary
+	diag    *diag.Diagnostic
+}
 
-		filePath := filepath.Join(dirPath, entry.Name())
+// cachedFile is what the cache keeps of a file: its key, or the diagnostic
+// of why it holds none.
+type cachedFile struct {
+	Key  *KeyInfo         `json:",omitempty"`
+	Diag *diag.Diagnostic `json:",omitempty"`
+}
 
-		key, err := ParsePrivateKey(filePath, pwd)
+// summarizeFile returns the key in f, or the diagnostic of why it holds
+// none. Files that parse to an unknown algorithm are not keys. Encrypted
+// keys are never cached, since what they parse to depends on the password,
+// and neither are archive entries, which are parsed in memory.
+func summarizeFile(f walk.File, c *cache.Cache, password string) fileSummary {
+	if f.Err != nil {
+		d := diag.ForPath(f.RelPath, f.Err)
+		return fileSummary{diag: &d}
+	}
+
+	parse := func(data []byte) (cachedFile, bool) {
+		cacheable := true
+		for _, o := range certpem.Detect(data) {
+			if o.Encrypted() {
+				cacheable = false
+			}
+		}
+
+		key, err := ParsePrivateKeyFromBytes(data, f.RelPath, password)
+		if err == nil && key.Algorithm == "Unknown" {
+			err = fmt.Errorf("unsupported private key algorithm in %s", f.RelPath)
+		}
 		if err != nil {
-			continue
+			d := diag.ForFile(f.RelPath, data, err, certpem.KindPrivateKey)
+			return cachedFile{Diag: &d}, cacheable
 		}
+		return cachedFile{Key: key}, cacheable
+	}
 
-		summaries = append(summaries, KeySummary{
-			Filename:      entry.Name(),
-			Encoding:      key.Encoding,
-			KeyType:       key.KeyType,
-			Bits:          key.Bits,
-			Curve:         key.Curve,
-			IsQuantumSafe: key.IsQuantumSafe,
-		})
+	var parsed cachedFile
+	var err error
+	if f.Data != nil {
+		parsed, _ = parse(f.Data)
+	} else {
+		parsed, err = cache.Load(c, "privatekey", f.Path, parse)
+	}
+	if err != nil {
+		d := diag.ForFile(f.RelPath, nil, err)
+		return fileSummary{diag: &d}
+	}
+	if parsed.Diag != nil {
+		parsed.Diag.Path = f.RelPath
+		return fileSummary{diag: parsed.Diag}
 	}
 
-	return summaries, nil
+	summary := NewKeySummary(f.RelPath, parsed.Key)
+	return fileSummary{summary: &summary}
 }
 
-func SummarizeDirectoryRecursive(dirPath string, password ...string) ([]KeySummary, error) {
-	summaries := make([]KeySummary, 0, 32)
-
+// SummarizeDirectory lists the private keys in dirPath, and the diagnostics
+// of the files that hold none.
+func SummarizeDirectory(dirPath string, password ...string) ([]KeySummary, []diag.Diagnostic, error) {
 	pwd := ""
 	if len(password) > 0 {
 		pwd = password[0]
 	}
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		relPath, _ := filepath.Rel(dirPath, path)
+	return SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{}, pwd)
+}
 
-		key, err := ParsePrivateKey(path, pwd)
-		if err != nil {
-			return nil
-		}
+func SummarizeDirectoryRecursive(dirPath string, password ...string) ([]KeySummary, []diag.Diagnostic, error) {
+	pwd := ""
+	if len(password) > 0 {
+		pwd = password[0]
+	}
 
-		summaries = append(summaries, KeySummary{
-			Filename:      relPath,
-			Encoding:      key.Encoding,
-			KeyType:       key.KeyType,
-			Bits:          key.Bits,
-			Curve:         key.Curve,
-			IsQuantumSafe: key.IsQuantumSafe,
-		})
+	return SummarizeDirectoryContext(context.Background(), dirPath, walk.Options{Recursive: true}, pwd)
+}
 
-		return nil
+// SummarizeDirectoryContext is SummarizeDirectory with the files parsed on
+// opts.Jobs workers. The summaries keep the walk order.
+func SummarizeDirectoryContext(ctx context.Context, dirPath string, opts walk.Options, password string) ([]KeySummary, []diag.Diagnostic, error) {
+	files, err := walk.Map(ctx, dirPath, opts, func(f walk.File) fileSummary {
+		return summarizeFile(f, opts.Cache, password)
 	})
-
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return summaries, nil
+	summaries := make([]KeySummary, 0, len(files))
+	var diags []diag.Diagnostic
+	for _, f := range files {
+		if f.summary != nil {
+			summaries = append(summaries, *f.summary)
+		}
+		if f.diag != nil {
+			diags = append(diags, *f.diag)
+		}
+	}
+	return summaries, diags, nil
 }
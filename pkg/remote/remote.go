@@ -0,0 +1,236 @@
+package remote
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/ocsp"
+)
+
+const defaultPort = "443"
+
+const defaultTimeout = 10 * time.Second
+
+var defaultALPN = []string{"h2", "http/1.1"}
+
+type Options struct {
+	// Protocol selects a STARTTLS dialect (see Protocols); empty means
+	// the server speaks TLS directly.
+	Protocol   string
+	ServerName string
+	ALPN       []string
+	RootsPath  string
+	Timeout    time.Duration
+}
+
+type SNIInfo struct {
+	ServerName           string
+	CoversServerName     bool
+	NoSNICommonName      string
+	NoSNISameCertificate bool
+	NoSNIError           string
+}
+
+type Result struct {
+	Address     string
+	Protocol    string `json:",omitempty"`
+	TLSVersion  string
+	CipherSuite string
+	ALPN        string
+	KeyExchange string
+	SNI         SNIInfo
+	Verified    bool
+	VerifyError string
+	OCSPStaple  *ocsp.ResponseInfo
+	// OCSPStapleError is why the stapled OCSP response could not be
+	// decoded; the rest of the result is still filled in.
+	OCSPStapleError string `json:",omitempty"`
+	Chain           []*certificate.CertificateInfo
+}
+
+// normalizeAddress adds port when address has none.
+func normalizeAddress(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(address, port)
+	}
+	return address
+}
+
+// handshake connects to address and runs a TLS handshake, first speaking
+// the plaintext upgrade dialog when upgrade is set.
+func handshake(address string, config *tls.Config, timeout time.Duration, upgrade upgrader) (*tls.ConnectionState, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	if upgrade == nil {
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, config)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		state := conn.ConnectionState()
+		return &state, nil
+	}
+
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	serverName := config.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(address)
+	}
+	r := bufio.NewReader(conn)
+	if err := upgrade(conn, r, serverName); err != nil {
+		return nil, fmt.Errorf("STARTTLS: %w", err)
+	}
+	if r.Buffered() > 0 {
+		return nil, fmt.Errorf("STARTTLS: unexpected data before TLS handshake")
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	state := tlsConn.ConnectionState()
+	return &state, nil
+}
+
+func verifyChain(chain []*x509.Certificate, serverName, rootsPath string) error {
+	opts := x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if rootsPath != "" {
+		roots, err := certificate.LoadX509Certificates(rootsPath)
+		if err != nil {
+			return err
+		}
+		opts.Roots = x509.NewCertPool()
+		for _, root := range roots {
+			opts.Roots.AddCert(root)
+		}
+	}
+	_, err := chain[0].Verify(opts)
+	return err
+}
+
+// Inspect runs a TLS handshake with address and reports the negotiated
+// parameters and the served chain. The handshake accepts any certificate;
+// trust is reported separately in Verified. A second handshake without SNI
+// shows whether the server depends on it. With opts.Protocol set, each
+// handshake is preceded by that protocol's STARTTLS dialog.
+func Inspect(address string, opts Options) (*Result, error) {
+	port := defaultPort
+	var upgrade upgrader
+	if opts.Protocol != "" {
+		p, ok := protocols[opts.Protocol]
+		if !ok {
+			return nil, fmt.Errorf("unsupported STARTTLS protocol %q (supported: %s)", opts.Protocol, strings.Join(Protocols(), ", "))
+		}
+		port = p.defaultPort
+		upgrade = p.upgrade
+	}
+
+	address = normalizeAddress(address, port)
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName := opts.ServerName
+	if serverName == "" && net.ParseIP(host) == nil {
+		serverName = host
+	}
+	alpn := opts.ALPN
+	if len(alpn) == 0 && opts.Protocol == "" {
+		alpn = defaultALPN
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	state, err := handshake(address, &tls.Config{
+		ServerName:         serverName,
+		NextProtos:         alpn,
+		InsecureSkipVerify: true,
+	}, timeout, upgrade)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s sent no certificates", address)
+	}
+
+	result := &Result{
+		Address:     address,
+		Protocol:    opts.Protocol,
+		TLSVersion:  tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+		KeyExchange: state.CurveID.String(),
+		SNI:         SNIInfo{ServerName: serverName},
+	}
+
+	for i, cert := range state.PeerCertificates {
+		info, err := certificate.ParseCertificateDER(cert.Raw, "TLS", address, i)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		result.Chain = append(result.Chain, info)
+	}
+
+	leaf := state.PeerCertificates[0]
+	checkName := serverName
+	if checkName == "" {
+		checkName = host
+	}
+	result.SNI.CoversServerName = result.Chain[0].CoversName(checkName)
+
+	if err := verifyChain(state.PeerCertificates, checkName, opts.RootsPath); err != nil {
+		result.VerifyError = err.Error()
+	} else {
+		result.Verified = true
+	}
+
+	if len(state.OCSPResponse) > 0 {
+		var issuer *x509.Certificate
+		if len(state.PeerCertificates) > 1 {
+			issuer = state.PeerCertificates[1]
+		}
+		if staple, err := ocsp.DecodeResponse(state.OCSPResponse, issuer, "stapled"); err != nil {
+			result.OCSPStapleError = err.Error()
+		} else {
+			result.OCSPStaple = staple
+		}
+	}
+
+	if serverName != "" {
+		noSNI, err := handshake(address, &tls.Config{
+			NextProtos:         alpn,
+			InsecureSkipVerify: true,
+		}, timeout, upgrade)
+		if err != nil {
+			result.SNI.NoSNIError = err.Error()
+		} else if len(noSNI.PeerCertificates) > 0 {
+			result.SNI.NoSNICommonName = noSNI.PeerCertificates[0].Subject.CommonName
+			result.SNI.NoSNISameCertificate = noSNI.PeerCertificates[0].Equal(leaf)
+		}
+	}
+
+	return result, nil
+}
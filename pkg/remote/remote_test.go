@@ -0,0 +1,201 @@
+package remote
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testPKI struct {
+	ca      *x509.Certificate
+	caKey   crypto.Signer
+	leaf    tls.Certificate
+	other   tls.Certificate
+	caPath  string
+	staple  []byte
+	serials int64
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func (p *testPKI) issue(t *testing.T, cn string, dnsNames ...string) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+	p.serials++
+	key := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(p.serials),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.ca, key.Public(), p.caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der, p.ca.Raw}, PrivateKey: key, Leaf: cert}, cert
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1000),
+		Subject:               pkix.Name{CommonName: "Remote Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	p := &testPKI{ca: ca, caKey: caKey}
+	var leafCert *x509.Certificate
+	p.leaf, leafCert = p.issue(t, "www.test.local", "www.test.local")
+	p.other, _ = p.issue(t, "default.test.local", "default.test.local")
+
+	p.staple, err = ocsp.CreateResponse(ca, ca, ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: leafCert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Hour),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}, caKey)
+	require.NoError(t, err)
+	p.leaf.OCSPStaple = p.staple
+
+	p.caPath = filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(p.caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0600))
+	return p
+}
+
+// startServer serves pki.leaf to clients that send SNI and pki.other to
+// clients that do not.
+func startServer(t *testing.T, pki *testPKI) string {
+	t.Helper()
+	config := &tls.Config{
+		NextProtos:       []string{"h2", "http/1.1"},
+		CurvePreferences: []tls.CurveID{tls.X25519MLKEM768, tls.X25519},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName == "" {
+				return &pki.other, nil
+			}
+			return &pki.leaf, nil
+		},
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				_ = c.(*tls.Conn).Handshake()
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestInspect(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startServer(t, pki)
+
+	result, err := Inspect(addr, Options{ServerName: "www.test.local", RootsPath: pki.caPath})
+	require.NoError(t, err)
+
+	assert.Equal(t, "TLS 1.3", result.TLSVersion)
+	assert.NotEmpty(t, result.CipherSuite)
+	assert.Equal(t, "h2", result.ALPN)
+	assert.Equal(t, "X25519MLKEM768", result.KeyExchange)
+
+	require.Len(t, result.Chain, 2)
+	assert.Equal(t, "www.test.local", result.Chain[0].CommonName)
+	assert.Equal(t, "TLS", result.Chain[0].Encoding)
+	assert.Equal(t, "Remote Test CA", result.Chain[1].CommonName)
+
+	assert.True(t, result.Verified, result.VerifyError)
+	assert.True(t, result.SNI.CoversServerName)
+	assert.False(t, result.SNI.NoSNISameCertificate)
+	assert.Equal(t, "default.test.local", result.SNI.NoSNICommonName)
+
+	require.NotNil(t, result.OCSPStaple)
+	assert.Equal(t, certificate.RevocationGood, result.OCSPStaple.Status)
+	assert.True(t, result.OCSPStaple.SignatureValid)
+}
+
+func TestInspectInvalidStaple(t *testing.T) {
+	pki := newTestPKI(t)
+	pki.leaf.OCSPStaple = []byte("not an OCSP response")
+	addr := startServer(t, pki)
+
+	result, err := Inspect(addr, Options{ServerName: "www.test.local", RootsPath: pki.caPath})
+	require.NoError(t, err, "a bad staple does not fail the inspection")
+	assert.Nil(t, result.OCSPStaple)
+	assert.NotEmpty(t, result.OCSPStapleError)
+	assert.Equal(t, "TLS 1.3", result.TLSVersion)
+	assert.NotEmpty(t, result.CipherSuite)
+	assert.Equal(t, "X25519MLKEM768", result.KeyExchange)
+	assert.Len(t, result.Chain, 2)
+	assert.True(t, result.Verified, result.VerifyError)
+}
+
+func TestInspectUntrustedAndWrongName(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startServer(t, pki)
+
+	result, err := Inspect(addr, Options{ServerName: "api.test.local", ALPN: []string{"http/1.1"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http/1.1", result.ALPN)
+	assert.False(t, result.Verified)
+	assert.NotEmpty(t, result.VerifyError)
+	assert.False(t, result.SNI.CoversServerName)
+}
+
+func TestInspectConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, err = Inspect(addr, Options{Timeout: time.Second})
+	assert.Error(t, err)
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	assert.Equal(t, "example.com:443", normalizeAddress("example.com", defaultPort))
+	assert.Equal(t, "example.com:8443", normalizeAddress("example.com:8443", defaultPort))
+	assert.Equal(t, "[::1]:443", normalizeAddress("::1", defaultPort))
+}
@@ -0,0 +1,362 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+// maxDialogSize bounds how much plaintext a server may send before TLS.
+const maxDialogSize = 64 * 1024
+
+// upgrader speaks the plaintext part of a protocol until the server is ready
+// for the TLS handshake.
+type upgrader func(conn net.Conn, r *bufio.Reader, serverName string) error
+
+type protocol struct {
+	defaultPort string
+	upgrade     upgrader
+}
+
+var protocols = map[string]protocol{
+	"smtp":     {"25", upgradeSMTP},
+	"imap":     {"143", upgradeIMAP},
+	"pop3":     {"110", upgradePOP3},
+	"ldap":     {"389", upgradeLDAP},
+	"postgres": {"5432", upgradePostgres},
+	"mysql":    {"3306", upgradeMySQL},
+	"xmpp":     {"5222", upgradeXMPP},
+	"ftp":      {"21", upgradeFTP},
+}
+
+func Protocols() []string {
+	names := make([]string, 0, len(protocols))
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readReply reads a numeric reply as used by SMTP and FTP, following
+// continuation lines ("250-...") up to the final "250 ..." line.
+func readReply(r *bufio.Reader, expected string) error {
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		if len(line) < 3 || line[:3] != expected {
+			return fmt.Errorf("unexpected reply %q, expected %s", line, expected)
+		}
+		if len(line) == 3 || line[3] == ' ' {
+			return nil
+		}
+	}
+}
+
+func upgradeSMTP(conn net.Conn, r *bufio.Reader, serverName string) error {
+	if err := readReply(r, "220"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "EHLO certinfo\r\n"); err != nil {
+		return err
+	}
+	if err := readReply(r, "250"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "STARTTLS\r\n"); err != nil {
+		return err
+	}
+	return readReply(r, "220")
+}
+
+func upgradeFTP(conn net.Conn, r *bufio.Reader, serverName string) error {
+	if err := readReply(r, "220"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "AUTH TLS\r\n"); err != nil {
+		return err
+	}
+	return readReply(r, "234")
+}
+
+func upgradeIMAP(conn net.Conn, r *bufio.Reader, serverName string) error {
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected IMAP greeting %q", line)
+	}
+	if _, err := fmt.Fprintf(conn, "a001 STARTTLS\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a001 OK") {
+			return fmt.Errorf("STARTTLS refused: %q", line)
+		}
+		return nil
+	}
+}
+
+func upgradePOP3(conn net.Conn, r *bufio.Reader, serverName string) error {
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected POP3 greeting %q", line)
+	}
+	if _, err := fmt.Fprintf(conn, "STLS\r\n"); err != nil {
+		return err
+	}
+	line, err = readLine(r)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("STLS refused: %q", line)
+	}
+	return nil
+}
+
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+const (
+	ldapTagExtendedRequest  = 23
+	ldapTagExtendedResponse = 24
+)
+
+type ldapExtendedRequest struct {
+	Name []byte `asn1:"tag:0"`
+}
+
+type ldapMessage struct {
+	MessageID int
+	Op        asn1.RawValue
+}
+
+// readBER reads one complete BER element (tag, definite length, content).
+func readBER(r *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return nil, fmt.Errorf("unsupported BER length encoding")
+		}
+		lenBytes := make([]byte, n)
+		if _, err := io.ReadFull(r, lenBytes); err != nil {
+			return nil, err
+		}
+		header = append(header, lenBytes...)
+		length = 0
+		for _, b := range lenBytes {
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxDialogSize {
+		return nil, fmt.Errorf("LDAP response too large")
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return append(header, content...), nil
+}
+
+func upgradeLDAP(conn net.Conn, r *bufio.Reader, serverName string) error {
+	op, err := asn1.MarshalWithParams(ldapExtendedRequest{Name: []byte(ldapStartTLSOID)}, fmt.Sprintf("application,tag:%d", ldapTagExtendedRequest))
+	if err != nil {
+		return err
+	}
+	request, err := asn1.Marshal(ldapMessage{MessageID: 1, Op: asn1.RawValue{FullBytes: op}})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	data, err := readBER(r)
+	if err != nil {
+		return err
+	}
+	var msg ldapMessage
+	if _, err := asn1.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("invalid LDAP response: %w", err)
+	}
+	if msg.Op.Class != asn1.ClassApplication || msg.Op.Tag != ldapTagExtendedResponse {
+		return fmt.Errorf("unexpected LDAP response (tag %d)", msg.Op.Tag)
+	}
+	var resultCode asn1.Enumerated
+	if _, err := asn1.Unmarshal(msg.Op.Bytes, &resultCode); err != nil {
+		return fmt.Errorf("invalid LDAP extended response: %w", err)
+	}
+	if resultCode != 0 {
+		return fmt.Errorf("StartTLS refused (LDAP result code %d)", resultCode)
+	}
+	return nil
+}
+
+// postgresSSLRequestCode is the protocol version number that marks an
+// SSLRequest message.
+const postgresSSLRequestCode = 80877103
+
+func upgradePostgres(conn net.Conn, r *bufio.Reader, serverName string) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	answer, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if answer != 'S' {
+		return fmt.Errorf("server does not accept SSL (answered %q)", answer)
+	}
+	return nil
+}
+
+const (
+	mysqlClientProtocol41 = 0x00000200
+	mysqlClientSSL        = 0x00000800
+	mysqlMaxPacketSize    = 1 << 24
+	mysqlCharsetUTF8MB4   = 45
+)
+
+func readMySQLPacket(r *bufio.Reader) ([]byte, byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length > maxDialogSize {
+		return nil, 0, fmt.Errorf("MySQL packet too large")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+	return payload, header[3], nil
+}
+
+func upgradeMySQL(conn net.Conn, r *bufio.Reader, serverName string) error {
+	payload, seq, err := readMySQLPacket(r)
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 || payload[0] != 10 {
+		return fmt.Errorf("unsupported MySQL handshake")
+	}
+
+	// protocol version, NUL-terminated server version, connection id,
+	// 8 bytes of auth data and a filler byte precede the capability flags.
+	end := bytes.IndexByte(payload[1:], 0)
+	if end < 0 {
+		return fmt.Errorf("malformed MySQL handshake")
+	}
+	offset := 1 + end + 1 + 4 + 8 + 1
+	if len(payload) < offset+2 {
+		return fmt.Errorf("malformed MySQL handshake")
+	}
+	capabilities := binary.LittleEndian.Uint16(payload[offset : offset+2])
+	if capabilities&mysqlClientSSL == 0 {
+		return fmt.Errorf("server does not support TLS")
+	}
+
+	request := make([]byte, 4+32)
+	request[0] = 32
+	request[3] = seq + 1
+	binary.LittleEndian.PutUint32(request[4:8], mysqlClientProtocol41|mysqlClientSSL)
+	binary.LittleEndian.PutUint32(request[8:12], mysqlMaxPacketSize)
+	request[12] = mysqlCharsetUTF8MB4
+	_, err = conn.Write(request)
+	return err
+}
+
+// readUntil reads from r until the accumulated data ends with marker. It
+// reads up to each occurrence of the last byte of marker and only compares
+// the tail, so the time it takes grows with the data read, not its square.
+func readUntil(r *bufio.Reader, marker string) (string, error) {
+	var buf []byte
+	for len(buf) < maxDialogSize {
+		chunk, err := r.ReadSlice(marker[len(marker)-1])
+		buf = append(buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return string(buf), err
+		}
+		if bytes.HasSuffix(buf, []byte(marker)) {
+			return string(buf), nil
+		}
+	}
+	return string(buf), fmt.Errorf("no %q in server response", marker)
+}
+
+func upgradeXMPP(conn net.Conn, r *bufio.Reader, serverName string) error {
+	// The name goes into an XML attribute, so quotes and brackets in it must
+	// not end the stream header early.
+	var to bytes.Buffer
+	if err := xml.EscapeText(&to, []byte(serverName)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream to='%s' xmlns='jabber:client' "+
+		"xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>", to.String())
+	if err != nil {
+		return err
+	}
+
+	features, err := readUntil(r, "</stream:features>")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(features, "<starttls") {
+		return fmt.Errorf("server does not offer STARTTLS")
+	}
+
+	if _, err := fmt.Fprintf(conn, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"); err != nil {
+		return err
+	}
+	answer, err := readUntil(r, ">")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(answer, "<proceed") {
+		return fmt.Errorf("STARTTLS refused: %s", answer)
+	}
+	return nil
+}
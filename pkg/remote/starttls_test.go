@@ -0,0 +1,257 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverDialog is the server side of a STARTTLS exchange, run by the fake
+// servers before they switch to TLS.
+type serverDialog func(conn net.Conn, r *bufio.Reader) error
+
+func expectLine(r *bufio.Reader, want string) error {
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	if line != want {
+		return fmt.Errorf("got %q, want %q", line, want)
+	}
+	return nil
+}
+
+var fakeServers = map[string]serverDialog{
+	"smtp": func(conn net.Conn, r *bufio.Reader) error {
+		fmt.Fprintf(conn, "220 mail.test.local ESMTP\r\n")
+		if err := expectLine(r, "EHLO certinfo"); err != nil {
+			return err
+		}
+		fmt.Fprintf(conn, "250-mail.test.local\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+		if err := expectLine(r, "STARTTLS"); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(conn, "220 Ready to start TLS\r\n")
+		return err
+	},
+	"imap": func(conn net.Conn, r *bufio.Reader) error {
+		fmt.Fprintf(conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS] ready\r\n")
+		if err := expectLine(r, "a001 STARTTLS"); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(conn, "a001 OK Begin TLS negotiation now\r\n")
+		return err
+	},
+	"pop3": func(conn net.Conn, r *bufio.Reader) error {
+		fmt.Fprintf(conn, "+OK POP3 ready\r\n")
+		if err := expectLine(r, "STLS"); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(conn, "+OK Begin TLS negotiation\r\n")
+		return err
+	},
+	"ftp": func(conn net.Conn, r *bufio.Reader) error {
+		fmt.Fprintf(conn, "220-Welcome\r\n220 FTP ready\r\n")
+		if err := expectLine(r, "AUTH TLS"); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(conn, "234 AUTH TLS successful\r\n")
+		return err
+	},
+	"ldap": func(conn net.Conn, r *bufio.Reader) error {
+		request, err := readBER(r)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(request, []byte(ldapStartTLSOID)) {
+			return fmt.Errorf("not a StartTLS request: %x", request)
+		}
+		// messageID 1, ExtendedResponse { success, "", "" }
+		_, err = conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+		return err
+	},
+	"postgres": func(conn net.Conn, r *bufio.Reader) error {
+		request := make([]byte, 8)
+		if _, err := io.ReadFull(r, request); err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint32(request[4:]) != postgresSSLRequestCode {
+			return fmt.Errorf("not an SSLRequest: %x", request)
+		}
+		_, err := conn.Write([]byte{'S'})
+		return err
+	},
+	"mysql": func(conn net.Conn, r *bufio.Reader) error {
+		var payload bytes.Buffer
+		payload.WriteByte(10)
+		payload.WriteString("8.0.36\x00")
+		payload.Write([]byte{1, 0, 0, 0})
+		payload.WriteString("abcdefgh")
+		payload.WriteByte(0)
+		binary.Write(&payload, binary.LittleEndian, uint16(mysqlClientProtocol41|mysqlClientSSL))
+		payload.Write([]byte{mysqlCharsetUTF8MB4, 2, 0, 0, 0})
+		header := []byte{byte(payload.Len()), 0, 0, 0}
+		conn.Write(append(header, payload.Bytes()...))
+
+		request, seq, err := readMySQLPacket(r)
+		if err != nil {
+			return err
+		}
+		if seq != 1 || len(request) != 32 || binary.LittleEndian.Uint32(request)&mysqlClientSSL == 0 {
+			return fmt.Errorf("not an SSLRequest: %x", request)
+		}
+		return nil
+	},
+	"xmpp": func(conn net.Conn, r *bufio.Reader) error {
+		header, err := readUntil(r, "version='1.0'>")
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(header, "to='www.test.local'") {
+			return fmt.Errorf("unexpected stream header %q", header)
+		}
+		fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream from='www.test.local' id='1' "+
+			"xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+			"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls></stream:features>")
+		if _, err := readUntil(r, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+		return err
+	},
+}
+
+// bufferedConn reads through the dialog's reader so that bytes it already
+// buffered (a MySQL client sends its ClientHello right after the
+// SSLRequest) reach the TLS server.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// startSTARTTLSServer runs dialog on each connection and then serves
+// pki.leaf over TLS.
+func startSTARTTLSServer(t *testing.T, pki *testPKI, dialog serverDialog) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	config := &tls.Config{Certificates: []tls.Certificate{pki.leaf}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				c.SetDeadline(time.Now().Add(5 * time.Second))
+				r := bufio.NewReader(c)
+				if err := dialog(c, r); err != nil {
+					return
+				}
+				_ = tls.Server(bufferedConn{c, r}, config).Handshake()
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestInspectSTARTTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	for _, name := range Protocols() {
+		t.Run(name, func(t *testing.T) {
+			require.Contains(t, fakeServers, name)
+			addr := startSTARTTLSServer(t, pki, fakeServers[name])
+
+			result, err := Inspect(addr, Options{
+				Protocol:   name,
+				ServerName: "www.test.local",
+				RootsPath:  pki.caPath,
+				Timeout:    5 * time.Second,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, name, result.Protocol)
+			assert.Equal(t, "TLS 1.3", result.TLSVersion)
+			assert.Empty(t, result.ALPN)
+			require.Len(t, result.Chain, 2)
+			assert.Equal(t, "www.test.local", result.Chain[0].CommonName)
+			assert.True(t, result.Verified, result.VerifyError)
+			assert.True(t, result.SNI.CoversServerName)
+		})
+	}
+}
+
+func TestInspectSTARTTLSRefused(t *testing.T) {
+	pki := newTestPKI(t)
+	addr := startSTARTTLSServer(t, pki, func(conn net.Conn, r *bufio.Reader) error {
+		fmt.Fprintf(conn, "220 mail.test.local ESMTP\r\n")
+		readLine(r)
+		fmt.Fprintf(conn, "250 mail.test.local\r\n")
+		readLine(r)
+		fmt.Fprintf(conn, "454 TLS not available\r\n")
+		return fmt.Errorf("refused")
+	})
+
+	_, err := Inspect(addr, Options{Protocol: "smtp", Timeout: 5 * time.Second})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "454")
+}
+
+func TestInspectUnsupportedProtocol(t *testing.T) {
+	_, err := Inspect("127.0.0.1:1", Options{Protocol: "gopher"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported STARTTLS protocol")
+}
+
+func TestUpgradeXMPPEscapesServerName(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go upgradeXMPP(client, bufio.NewReader(client), "evil'/><iq type='set'/><x a='")
+
+	header, err := readUntil(bufio.NewReader(server), "version='1.0'>")
+	server.Close()
+	require.NoError(t, err)
+	assert.Contains(t, header, "to='evil&#39;/&gt;&lt;iq type=&#39;set&#39;/&gt;&lt;x a=&#39;'")
+	assert.NotContains(t, header, "<iq")
+}
+
+func TestReadUntil(t *testing.T) {
+	r := bufio.NewReaderSize(strings.NewReader("<a><b></stream:features><c>"), 16)
+	got, err := readUntil(r, "</stream:features>")
+	require.NoError(t, err)
+	assert.Equal(t, "<a><b></stream:features>", got)
+	rest, _ := io.ReadAll(r)
+	assert.Equal(t, "<c>", string(rest), "nothing past the marker is consumed")
+
+	r = bufio.NewReader(strings.NewReader(strings.Repeat("<x/>", maxDialogSize)))
+	got, err = readUntil(r, "</stream:features>")
+	assert.ErrorContains(t, err, "no \"</stream:features>\" in server response")
+	assert.Len(t, got, maxDialogSize)
+
+	_, err = readUntil(bufio.NewReader(strings.NewReader("<stream>")), "</stream>")
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestNormalizeAddressProtocolPort(t *testing.T) {
+	assert.Equal(t, "mail.example.com:25", normalizeAddress("mail.example.com", protocols["smtp"].defaultPort))
+	assert.Equal(t, "db.example.com:5432", normalizeAddress("db.example.com", protocols["postgres"].defaultPort))
+}
@@ -0,0 +1,483 @@
+// Package report builds the post-quantum migration readiness report of a
+// directory tree: every certificate and private key classified as
+// classical, hybrid or PQC, with its harvest-now-decrypt-later exposure and
+// its NIST IR 8547 and CNSA 2.0 transition dates.
+package report
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pkcs12"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/strength"
+	"github.com/marco-introini/certinfo/pkg/walk"
+)
+
+const (
+	KindCertificate = "certificate"
+	KindKey         = "key"
+)
+
+// Harvest-now-decrypt-later exposure: traffic protected by a classical key
+// exchange can be recorded today and decrypted once a quantum computer
+// exists. Signature-only keys are not exposed this way.
+const (
+	ExposureHigh   = "high"
+	ExposureMedium = "medium"
+	ExposureNone   = "none"
+)
+
+// CNSA 2.0 status of an item.
+const (
+	CNSACompliant    = "compliant"
+	CNSATransitional = "transitional"
+	CNSANotApproved  = "not approved"
+	CNSAReplace      = "replace"
+)
+
+// NIST IR 8547 transition years for quantum-vulnerable public key
+// algorithms: 112-bit strength is deprecated after 2030, everything is
+// disallowed after 2035. Below 112 bits SP 800-131A disallowed them after
+// 2013.
+const (
+	nistDeprecated112 = 2030
+	nistDisallowed    = 2035
+	nistDisallowedNow = 2013
+)
+
+// CNSA 2.0 deadlines for exclusive use of quantum-resistant algorithms:
+// software and firmware signing by 2030, web servers, cloud services and the
+// rest of the network stack by 2033.
+const (
+	cnsaCodeSigning = 2030
+	cnsaDefault     = 2033
+)
+
+// cnsaAlgorithms are the CNSA 2.0 parameter sets among the registry ones.
+var cnsaAlgorithms = map[string]bool{"ML-DSA-87": true, "ML-KEM-1024": true}
+
+type Item struct {
+	Filename    string
+	Kind        string
+	Name        string `json:",omitempty"`
+	Issuer      string `json:",omitempty"`
+	IssuerDN    string `json:",omitempty"`
+	Algorithm   string
+	Class       string
+	Strength    strength.Strength
+	KeyExchange bool
+	Exposure    string
+	IsCA        bool      `json:",omitempty"`
+	NotAfter    time.Time `json:",omitzero"`
+	// Deprecated and Disallowed are the last years NIST IR 8547 (or SP
+	// 800-131A) allows the algorithm without and with restrictions.
+	Deprecated    int `json:",omitempty"`
+	Disallowed    int `json:",omitempty"`
+	CNSA2         string
+	CNSA2Deadline int `json:",omitempty"`
+	Priority      int
+	Reasons       []string `json:",omitempty"`
+
+	pqcKey      bool
+	codeSigning bool
+}
+
+// NISTStatus is "disallowed", "deprecated" or "acceptable" in the given year.
+func (i *Item) NISTStatus(year int) string {
+	switch {
+	case i.Disallowed > 0 && year > i.Disallowed:
+		return "disallowed"
+	case i.Deprecated > 0 && year > i.Deprecated:
+		return "deprecated"
+	}
+	return "acceptable"
+}
+
+// NISTDates describes Deprecated and Disallowed for tables.
+func (i *Item) NISTDates() string {
+	switch {
+	case i.Disallowed == 0:
+		return "-"
+	case i.Disallowed == nistDisallowedNow:
+		return "disallowed"
+	case i.Deprecated > 0:
+		return fmt.Sprintf("deprecated %d, disallowed %d", i.Deprecated, i.Disallowed)
+	}
+	return fmt.Sprintf("disallowed %d", i.Disallowed)
+}
+
+// CNSA describes CNSA2 and CNSA2Deadline for tables.
+func (i *Item) CNSA() string {
+	if i.CNSA2 == CNSAReplace {
+		return fmt.Sprintf("replace by %d", i.CNSA2Deadline)
+	}
+	return i.CNSA2
+}
+
+type Summary struct {
+	Total          int
+	Certificates   int
+	Keys           int
+	Classical      int
+	Hybrid         int
+	PQC            int
+	ExposureHigh   int
+	ExposureMedium int
+	DisallowedNow  int
+	Deprecated2030 int
+	Disallowed2035 int
+	CNSACompliant  int
+}
+
+// Ready is the number of hybrid and PQC items.
+func (s Summary) Ready() int {
+	return s.Hybrid + s.PQC
+}
+
+type IssuerSummary struct {
+	Issuer       string
+	IssuerDN     string
+	Certificates int
+	Classical    int
+	Hybrid       int
+	PQC          int
+	Exposed      int
+}
+
+type Report struct {
+	Directory    string
+	Generated    time.Time
+	Summary      Summary
+	Issuers      []IssuerSummary
+	MigrateFirst []Item
+	Items        []Item
+	// Diagnostics are the files that hold no certificate or key the report
+	// could use.
+	Diagnostics []diag.Diagnostic `json:",omitempty"`
+}
+
+// Generate builds the report of every certificate, private key and PKCS#12
+// file below dirPath. The password opens encrypted keys and PKCS#12 files.
+func Generate(dirPath, password string) (*Report, error) {
+	return GenerateContext(context.Background(), []string{dirPath}, password, walk.Options{})
+}
+
+// GenerateContext is Generate over one or more directories with the files
+// parsed on opts.Jobs workers. The walk is always recursive.
+func GenerateContext(ctx context.Context, dirPaths []string, password string, opts walk.Options) (*Report, error) {
+	opts.Recursive = true
+	opts.FullPaths = opts.FullPaths || len(dirPaths) > 1
+
+	var certs []certificate.CertificateSummary
+	var keys []privatekey.KeySummary
+	var certDiags, keyDiags, p12Diags []diag.Diagnostic
+	for _, dirPath := range dirPaths {
+		c, d, err := certificate.SummarizeDirectoryContext(ctx, dirPath, opts)
+		if err != nil {
+			return nil, err
+		}
+		certs, certDiags = append(certs, c...), append(certDiags, d...)
+
+		k, d, err := privatekey.SummarizeDirectoryContext(ctx, dirPath, opts, password)
+		if err != nil {
+			return nil, err
+		}
+		keys, keyDiags = append(keys, k...), append(keyDiags, d...)
+
+		p12Certs, p12Keys, d, err := pkcs12.SummarizeDirectoryContext(ctx, dirPath, opts, password)
+		if err != nil {
+			return nil, err
+		}
+		certs, keys, p12Diags = append(certs, p12Certs...), append(keys, p12Keys...), append(p12Diags, d...)
+	}
+	used := make(map[string]bool)
+	for _, c := range certs {
+		used[c.Filename] = true
+	}
+	for _, k := range keys {
+		used[k.Filename] = true
+	}
+
+	r := New(strings.Join(dirPaths, ", "), certs, keys, time.Now())
+	r.Diagnostics = diag.Merge(used, certDiags, keyDiags, p12Diags)
+	return r, nil
+}
+
+// New builds the report of the given certificates and keys as of now.
+func New(dirPath string, certs []certificate.CertificateSummary, keys []privatekey.KeySummary, now time.Time) *Report {
+	r := &Report{Directory: dirPath, Generated: now}
+
+	counts := make(map[string]int)
+	for _, c := range certs {
+		counts[c.Filename]++
+	}
+	for _, c := range certs {
+		r.Items = append(r.Items, certificateItem(c, counts))
+	}
+	for _, k := range keys {
+		// Files that are not keys show up with an unknown algorithm.
+		if k.Algorithm == "Unknown" {
+			continue
+		}
+		r.Items = append(r.Items, keyItem(k))
+	}
+
+	issuers := make(map[string]*IssuerSummary)
+	for i := range r.Items {
+		item := &r.Items[i]
+		assess(item, now)
+		r.count(item, now)
+		if item.Kind == KindCertificate {
+			is, ok := issuers[item.IssuerDN]
+			if !ok {
+				is = &IssuerSummary{Issuer: item.Issuer, IssuerDN: item.IssuerDN}
+				issuers[item.IssuerDN] = is
+			}
+			is.add(item)
+		}
+		if item.Priority > 0 {
+			r.MigrateFirst = append(r.MigrateFirst, *item)
+		}
+	}
+
+	for _, is := range issuers {
+		r.Issuers = append(r.Issuers, *is)
+	}
+	slices.SortFunc(r.Issuers, func(a, b IssuerSummary) int {
+		if a.Certificates != b.Certificates {
+			return b.Certificates - a.Certificates
+		}
+		return strings.Compare(a.IssuerDN, b.IssuerDN)
+	})
+	slices.SortStableFunc(r.MigrateFirst, func(a, b Item) int {
+		return b.Priority - a.Priority
+	})
+	return r
+}
+
+func (r *Report) count(item *Item, now time.Time) {
+	s := &r.Summary
+	s.Total++
+	if item.Kind == KindCertificate {
+		s.Certificates++
+	} else {
+		s.Keys++
+	}
+	switch item.Class {
+	case certificate.QuantumPQC:
+		s.PQC++
+	case certificate.QuantumHybrid:
+		s.Hybrid++
+	default:
+		s.Classical++
+	}
+	switch item.Exposure {
+	case ExposureHigh:
+		s.ExposureHigh++
+	case ExposureMedium:
+		s.ExposureMedium++
+	}
+	switch {
+	case item.NISTStatus(now.Year()) == "disallowed":
+		s.DisallowedNow++
+	case item.Deprecated > 0:
+		s.Deprecated2030++
+	case item.Disallowed > 0:
+		s.Disallowed2035++
+	}
+	if item.CNSA2 == CNSACompliant {
+		s.CNSACompliant++
+	}
+}
+
+func (is *IssuerSummary) add(item *Item) {
+	is.Certificates++
+	switch item.Class {
+	case certificate.QuantumPQC:
+		is.PQC++
+	case certificate.QuantumHybrid:
+		is.Hybrid++
+	default:
+		is.Classical++
+	}
+	if item.Exposure != ExposureNone {
+		is.Exposed++
+	}
+}
+
+func certificateItem(c certificate.CertificateSummary, counts map[string]int) Item {
+	filename := c.Filename
+	if counts[c.Filename] > 1 {
+		filename = fmt.Sprintf("%s[%d]", c.Filename, c.Index+1)
+	}
+
+	item := Item{
+		Filename:    filename,
+		Kind:        KindCertificate,
+		Name:        c.CommonName,
+		Issuer:      c.Issuer,
+		IssuerDN:    c.IssuerDN,
+		Class:       c.QuantumSafety,
+		Strength:    c.Strength,
+		IsCA:        c.IsCA,
+		NotAfter:    c.NotAfter,
+		pqcKey:      pqc.FamilyFromName(c.KeyType) != "",
+		codeSigning: slices.Contains(c.ExtKeyUsage, "Code Signing"),
+	}
+	if item.Class == "" {
+		item.Class = certificate.QuantumClassical
+	}
+
+	label := keyLabel(c.KeyType, c.Bits, "")
+	switch {
+	case len(c.PQCTypes) == 0:
+		item.Algorithm = label
+	case item.pqcKey:
+		item.Algorithm = strings.Join(c.PQCTypes, ", ")
+	default:
+		item.Algorithm = label + " + " + strings.Join(c.PQCTypes, ", ")
+	}
+
+	if !item.pqcKey {
+		exchange := slices.ContainsFunc(c.KeyUsage, func(u string) bool {
+			return u == "Key Encipherment" || u == "Key Agreement" || u == "Data Encipherment"
+		})
+		switch {
+		case exchange:
+			item.Exposure = ExposureHigh
+		case len(c.KeyUsage) == 0 && !c.IsCA && (c.KeyType == "RSA" || c.KeyType == "ECDSA"):
+			// Without a Key Usage extension an RSA key may be used for
+			// key transport and an EC key for static ECDH.
+			item.Exposure = ExposureMedium
+		}
+	}
+	return item
+}
+
+func keyItem(k privatekey.KeySummary) Item {
+	item := Item{
+		Filename:  k.Filename,
+		Kind:      KindKey,
+		Class:     certificate.QuantumClassical,
+		Strength:  k.Strength,
+		Algorithm: keyLabel(k.KeyType, k.Bits, k.Curve),
+	}
+	if alg, ok := pqc.ByName(k.Algorithm); ok {
+		item.Algorithm = alg.Name
+		item.Class = certificate.QuantumPQC
+		if alg.Composite {
+			item.Class = certificate.QuantumHybrid
+		}
+		item.pqcKey = true
+	} else if k.IsQuantumSafe {
+		item.Class = certificate.QuantumPQC
+		item.pqcKey = true
+	}
+
+	if !item.pqcKey && (k.KeyType == "RSA" || k.KeyType == "EC") {
+		// A bare key says nothing about its use.
+		item.Exposure = ExposureMedium
+	}
+	return item
+}
+
+func keyLabel(keyType string, bits int, curve string) string {
+	switch keyType {
+	case "RSA":
+		return fmt.Sprintf("RSA-%d", bits)
+	case "EC", "ECDSA":
+		if curve == "" {
+			curve = fmt.Sprintf("P-%d", bits)
+		}
+		return "ECDSA " + curve
+	}
+	return keyType
+}
+
+// assess fills in the exposure, transition dates and migration priority of
+// item.
+func assess(item *Item, now time.Time) {
+	if item.Exposure == "" {
+		item.Exposure = ExposureNone
+	}
+	item.KeyExchange = item.Exposure != ExposureNone
+
+	if item.pqcKey {
+		item.CNSA2 = cnsaStatus(item)
+		return
+	}
+
+	switch {
+	case item.Strength.IsWeak():
+		item.Disallowed = nistDisallowedNow
+	case item.Strength.Bits == strength.Minimum:
+		item.Deprecated = nistDeprecated112
+		item.Disallowed = nistDisallowed
+	default:
+		item.Disallowed = nistDisallowed
+	}
+	item.CNSA2 = CNSAReplace
+	item.CNSA2Deadline = cnsaDefault
+	if item.codeSigning {
+		item.CNSA2Deadline = cnsaCodeSigning
+	}
+
+	if item.Kind == KindCertificate && now.After(item.NotAfter) {
+		// Expired certificates need replacing anyway.
+		return
+	}
+
+	add := func(points int, reason string) {
+		item.Priority += points
+		item.Reasons = append(item.Reasons, reason)
+	}
+	if item.NISTStatus(now.Year()) == "disallowed" {
+		add(100, fmt.Sprintf("%s, already disallowed", item.Strength))
+	}
+	switch item.Exposure {
+	case ExposureHigh:
+		add(50, "harvest-now-decrypt-later: used for key exchange")
+	case ExposureMedium:
+		add(20, "harvest-now-decrypt-later: may be used for key exchange")
+	}
+	if item.Deprecated > 0 {
+		add(20, fmt.Sprintf("deprecated after %d", item.Deprecated))
+	} else if item.Disallowed > now.Year() {
+		add(10, fmt.Sprintf("disallowed after %d", item.Disallowed))
+	}
+	if item.IsCA {
+		add(15, "CA: its certificates cannot migrate before it does")
+	}
+	deadline := item.Disallowed
+	if item.Deprecated > 0 {
+		deadline = item.Deprecated
+	}
+	if item.Kind == KindCertificate && item.NotAfter.Year() > deadline && deadline > now.Year() {
+		add(15, fmt.Sprintf("valid until %d, past the %d deadline", item.NotAfter.Year(), deadline))
+	}
+	if item.CNSA2Deadline == cnsaCodeSigning {
+		add(5, fmt.Sprintf("CNSA 2.0: code signing quantum-resistant by %d", cnsaCodeSigning))
+	}
+}
+
+func cnsaStatus(item *Item) string {
+	names := strings.Split(item.Algorithm, ", ")
+	status := CNSACompliant
+	for _, name := range names {
+		alg, ok := pqc.ByName(name)
+		switch {
+		case ok && alg.Composite && cnsaAlgorithms[alg.Component]:
+			status = CNSATransitional
+		case !ok || !cnsaAlgorithms[alg.Name]:
+			return CNSANotApproved
+		}
+	}
+	return status
+}
@@ -0,0 +1,215 @@
+package report
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/strength"
+	"github.com/marco-introini/certinfo/pkg/walk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func cert(filename, issuer, keyType string, bits, strengthBits int, class string, pqcTypes ...string) certificate.CertificateSummary {
+	return certificate.CertificateSummary{
+		Filename:      filename,
+		CommonName:    filename,
+		Issuer:        issuer,
+		IssuerDN:      "CN=" + issuer,
+		KeyType:       keyType,
+		Bits:          bits,
+		NotAfter:      now.AddDate(1, 0, 0),
+		QuantumSafety: class,
+		PQCTypes:      pqcTypes,
+		Strength:      strength.Strength{Bits: strengthBits},
+	}
+}
+
+func itemByName(t *testing.T, r *Report, filename string) Item {
+	for _, item := range r.Items {
+		if item.Filename == filename {
+			return item
+		}
+	}
+	t.Fatalf("no item %s", filename)
+	return Item{}
+}
+
+func TestNewClassification(t *testing.T) {
+	tls := cert("tls.crt", "RSA CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	tls.KeyUsage = []string{"Digital Signature", "Key Encipherment"}
+	sha1 := cert("sha1.crt", "RSA CA", "RSA", 4096, 63, certificate.QuantumClassical)
+	sha1.Strength.LimitedBy = "SHA-1 signature"
+	codeSigning := cert("code.crt", "RSA CA", "ECDSA", 384, 192, certificate.QuantumClassical)
+	codeSigning.KeyUsage = []string{"Digital Signature"}
+	codeSigning.ExtKeyUsage = []string{"Code Signing"}
+	ed25519 := cert("ed25519.crt", "Ed CA", "Ed25519", 256, 128, certificate.QuantumClassical)
+	mldsa87 := cert("mldsa87.crt", "PQC CA", "ML-DSA", 87, 256, certificate.QuantumPQC, "ML-DSA-87")
+	mldsa65 := cert("mldsa65.crt", "PQC CA", "ML-DSA", 65, 192, certificate.QuantumPQC, "ML-DSA-65")
+	composite := cert("composite.crt", "PQC CA", "Composite ML-DSA", 87, 256, certificate.QuantumHybrid, "MLDSA87-ECDSA-P384-SHA512")
+	pqcSigned := cert("pqc-signed.crt", "PQC CA", "RSA", 3072, 128, certificate.QuantumHybrid, "ML-DSA-65")
+
+	keys := []privatekey.KeySummary{
+		{Filename: "rsa.key", KeyType: "RSA", Algorithm: "PKCS#8", Bits: 2048, Strength: strength.Strength{Bits: 112}},
+		{Filename: "ec.key", KeyType: "EC", Algorithm: "ECDSA", Bits: 256, Curve: "P-256", Strength: strength.Strength{Bits: 128}},
+		{Filename: "mlkem.key", KeyType: "ML-KEM", Algorithm: "ML-KEM-1024", Bits: 1024, IsQuantumSafe: true, Strength: strength.Strength{Bits: 256, Category: 5}},
+		{Filename: "README.md", KeyType: "<nil>", Algorithm: "Unknown"},
+	}
+
+	r := New("certs", []certificate.CertificateSummary{tls, sha1, codeSigning, ed25519, mldsa87, mldsa65, composite, pqcSigned}, keys, now)
+
+	tests := []struct {
+		filename   string
+		algorithm  string
+		class      string
+		exposure   string
+		deprecated int
+		disallowed int
+		cnsa       string
+	}{
+		{"tls.crt", "RSA-2048", certificate.QuantumClassical, ExposureHigh, 2030, 2035, "replace by 2033"},
+		{"sha1.crt", "RSA-4096", certificate.QuantumClassical, ExposureMedium, 0, 2013, "replace by 2033"},
+		{"code.crt", "ECDSA P-384", certificate.QuantumClassical, ExposureNone, 0, 2035, "replace by 2030"},
+		{"ed25519.crt", "Ed25519", certificate.QuantumClassical, ExposureNone, 0, 2035, "replace by 2033"},
+		{"mldsa87.crt", "ML-DSA-87", certificate.QuantumPQC, ExposureNone, 0, 0, CNSACompliant},
+		{"mldsa65.crt", "ML-DSA-65", certificate.QuantumPQC, ExposureNone, 0, 0, CNSANotApproved},
+		{"composite.crt", "MLDSA87-ECDSA-P384-SHA512", certificate.QuantumHybrid, ExposureNone, 0, 0, CNSATransitional},
+		{"pqc-signed.crt", "RSA-3072 + ML-DSA-65", certificate.QuantumHybrid, ExposureMedium, 0, 2035, "replace by 2033"},
+		{"rsa.key", "RSA-2048", certificate.QuantumClassical, ExposureMedium, 2030, 2035, "replace by 2033"},
+		{"ec.key", "ECDSA P-256", certificate.QuantumClassical, ExposureMedium, 0, 2035, "replace by 2033"},
+		{"mlkem.key", "ML-KEM-1024", certificate.QuantumPQC, ExposureNone, 0, 0, CNSACompliant},
+	}
+
+	require.Len(t, r.Items, len(tests), "README.md is not a key")
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			item := itemByName(t, r, tt.filename)
+			assert.Equal(t, tt.algorithm, item.Algorithm)
+			assert.Equal(t, tt.class, item.Class)
+			assert.Equal(t, tt.exposure, item.Exposure)
+			assert.Equal(t, tt.exposure != ExposureNone, item.KeyExchange)
+			assert.Equal(t, tt.deprecated, item.Deprecated)
+			assert.Equal(t, tt.disallowed, item.Disallowed)
+			assert.Equal(t, tt.cnsa, item.CNSA())
+		})
+	}
+
+	s := r.Summary
+	assert.Equal(t, 11, s.Total)
+	assert.Equal(t, 8, s.Certificates)
+	assert.Equal(t, 3, s.Keys)
+	assert.Equal(t, 6, s.Classical)
+	assert.Equal(t, 2, s.Hybrid)
+	assert.Equal(t, 3, s.PQC)
+	assert.Equal(t, 5, s.Ready())
+	assert.Equal(t, 1, s.ExposureHigh)
+	assert.Equal(t, 4, s.ExposureMedium)
+	assert.Equal(t, 1, s.DisallowedNow)
+	assert.Equal(t, 2, s.Deprecated2030)
+	assert.Equal(t, 4, s.Disallowed2035)
+	assert.Equal(t, 2, s.CNSACompliant)
+}
+
+func TestNewMigrateFirst(t *testing.T) {
+	tls := cert("tls.crt", "RSA CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	tls.KeyUsage = []string{"Key Encipherment"}
+	sha1 := cert("sha1.crt", "RSA CA", "RSA", 4096, 63, certificate.QuantumClassical)
+	sha1.Strength.LimitedBy = "SHA-1 signature"
+	ca := cert("ca.crt", "Root", "ECDSA", 384, 192, certificate.QuantumClassical)
+	ca.IsCA = true
+	ca.KeyUsage = []string{"Certificate Sign", "CRL Sign"}
+	ca.NotAfter = time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := cert("expired.crt", "RSA CA", "RSA", 1024, 80, certificate.QuantumClassical)
+	expired.NotAfter = now.AddDate(0, -1, 0)
+	mldsa := cert("mldsa.crt", "PQC CA", "ML-DSA", 87, 256, certificate.QuantumPQC, "ML-DSA-87")
+
+	r := New("certs", []certificate.CertificateSummary{mldsa, ca, expired, tls, sha1}, nil, now)
+
+	var order []string
+	for _, item := range r.MigrateFirst {
+		order = append(order, item.Filename)
+	}
+	assert.Equal(t, []string{"sha1.crt", "tls.crt", "ca.crt"}, order)
+
+	assert.Contains(t, r.MigrateFirst[0].Reasons, "63 bits, limited by SHA-1 signature, already disallowed")
+	assert.Contains(t, r.MigrateFirst[2].Reasons, "CA: its certificates cannot migrate before it does")
+	assert.Contains(t, r.MigrateFirst[2].Reasons, "valid until 2040, past the 2035 deadline")
+	expiredItem := itemByName(t, r, "expired.crt")
+	assert.Equal(t, "disallowed", expiredItem.NISTStatus(now.Year()))
+	assert.Zero(t, expiredItem.Priority)
+	assert.Zero(t, itemByName(t, r, "mldsa.crt").Priority)
+}
+
+func TestNewIssuers(t *testing.T) {
+	a := cert("a.crt", "RSA CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	b := cert("b.crt", "RSA CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	b.KeyUsage = []string{"Key Encipherment"}
+	c := cert("c.crt", "PQC CA", "ML-DSA", 65, 192, certificate.QuantumPQC, "ML-DSA-65")
+
+	r := New("certs", []certificate.CertificateSummary{c, a, b}, nil, now)
+	require.Len(t, r.Issuers, 2)
+	assert.Equal(t, IssuerSummary{Issuer: "RSA CA", IssuerDN: "CN=RSA CA", Certificates: 2, Classical: 2, Exposed: 2}, r.Issuers[0])
+	assert.Equal(t, IssuerSummary{Issuer: "PQC CA", IssuerDN: "CN=PQC CA", Certificates: 1, PQC: 1}, r.Issuers[1])
+}
+
+func TestNewBundleIndex(t *testing.T) {
+	first := cert("bundle.pem", "CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	second := cert("bundle.pem", "CA", "RSA", 2048, 112, certificate.QuantumClassical)
+	second.Index = 1
+
+	r := New("certs", []certificate.CertificateSummary{first, second}, nil, now)
+	assert.Equal(t, "bundle.pem[1]", r.Items[0].Filename)
+	assert.Equal(t, "bundle.pem[2]", r.Items[1].Filename)
+}
+
+func TestGenerate(t *testing.T) {
+	r, err := Generate(filepath.Join("..", "..", "test_certs"), "testpass")
+	require.NoError(t, err)
+	assert.Positive(t, r.Summary.Certificates)
+	assert.Positive(t, r.Summary.Keys)
+	assert.NotEmpty(t, r.Issuers)
+	assert.NotEmpty(t, r.MigrateFirst)
+
+	var p12 bool
+	for _, item := range r.Items {
+		if filepath.Ext(item.Filename) == ".pfx" {
+			p12 = true
+		}
+	}
+	assert.True(t, p12, "PKCS#12 contents are part of the report")
+
+	var readme *diag.Diagnostic
+	for i, d := range r.Diagnostics {
+		assert.NotContains(t, []string{"traditional/rsa/server-rsa2048.key", "chain/server.crt"}, d.Path, "used files are not diagnosed")
+		if d.Path == "README.md" {
+			readme = &r.Diagnostics[i]
+		}
+	}
+	require.NotNil(t, readme)
+	assert.Equal(t, diag.Unrecognized, readme.Category)
+
+	_, err = Generate("/nonexistent/path", "")
+	assert.Error(t, err)
+}
+
+func TestGenerateContextSeveralDirectories(t *testing.T) {
+	chainDir := filepath.Join("..", "..", "test_certs", "chain")
+	clientDir := filepath.Join("..", "..", "test_certs", "client")
+	r, err := GenerateContext(context.Background(), []string{chainDir, clientDir}, "", walk.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, chainDir+", "+clientDir, r.Directory)
+
+	names := make(map[string]bool)
+	for _, item := range r.Items {
+		names[item.Filename] = true
+	}
+	assert.True(t, names[filepath.Join(chainDir, "server.crt")], "files are named by their full path")
+	assert.True(t, names[filepath.Join(clientDir, "client.crt")])
+}
@@ -0,0 +1,373 @@
+// Package scan classifies every file below a path by the crypto objects it
+// holds, using the detectors registered in pkg/pem.
+package scan
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	encpem "encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marco-introini/certinfo/pkg/cache"
+	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/crl"
+	"github.com/marco-introini/certinfo/pkg/csr"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pkcs12"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/walk"
+	"golang.org/x/crypto/ssh"
+)
+
+// Kinds lists the file kinds in the order they are reported.
+var Kinds = []pem.Kind{
+	pem.KindCertificate,
+	pem.KindBundle,
+	pem.KindPrivateKey,
+	pem.KindPublicKey,
+	pem.KindCSR,
+	pem.KindCRL,
+	pem.KindPKCS7,
+	pem.KindPKCS12,
+	pem.KindJKS,
+	pem.KindSSHKey,
+	pem.KindUnknown,
+}
+
+type Options struct {
+	// Walk selects the files of a directory scan.
+	walk.Options
+	// Password is tried on encrypted private keys and PKCS#12 files.
+	Password string
+}
+
+type Object struct {
+	Kind      pem.Kind
+	Encoding  string
+	Subject   string `json:",omitempty"`
+	Algorithm string `json:",omitempty"`
+	Detail    string `json:",omitempty"`
+	// Error is why the object could not be decoded.
+	Error string `json:",omitempty"`
+}
+
+type File struct {
+	Filename string
+	Kind     pem.Kind
+	Objects  []Object `json:",omitempty"`
+}
+
+type Inventory struct {
+	Path  string
+	Files []File
+	// Diagnostics are the paths that could not be read and the objects
+	// that could not be decoded.
+	Diagnostics []diag.Diagnostic `json:",omitempty"`
+}
+
+// Counts returns the number of files of each kind.
+func (inv *Inventory) Counts() map[pem.Kind]int {
+	counts := make(map[pem.Kind]int)
+	for _, f := range inv.Files {
+		counts[f.Kind]++
+	}
+	return counts
+}
+
+// Objects returns the number of crypto objects found in all files.
+func (inv *Inventory) Objects() int {
+	n := 0
+	for _, f := range inv.Files {
+		n += len(f.Objects)
+	}
+	return n
+}
+
+// Scan classifies the file at path, or every file in the directory at path.
+func Scan(path string, opts Options) (*Inventory, error) {
+	return ScanContext(context.Background(), path, opts)
+}
+
+// fileScan is what one walked file contributes to an inventory.
+type fileScan struct {
+	file *File
+	diag *diag.Diagnostic
+}
+
+// ScanContext is Scan with the files classified on opts.Jobs workers. The
+// files keep the walk order.
+func ScanContext(ctx context.Context, path string, opts Options) (*Inventory, error) {
+	scans, err := walk.Map(ctx, path, opts.Options, func(f walk.File) fileScan {
+		if f.Err != nil {
+			d := diag.ForPath(f.RelPath, f.Err)
+			return fileScan{diag: &d}
+		}
+		// What is found in encrypted keys and PKCS#12 files depends on the
+		// password, so those files are not cached.
+		parse := func(data []byte) (File, bool) {
+			cacheable := true
+			for _, o := range pem.Detect(data) {
+				if o.Encrypted() || o.Kind == pem.KindPKCS12 {
+					cacheable = false
+				}
+			}
+			return ScanData(f.RelPath, data, opts), cacheable
+		}
+
+		var file File
+		var err error
+		if f.Data != nil {
+			file, _ = parse(f.Data)
+		} else {
+			file, err = cache.Load(opts.Cache, "scan", f.Path, parse)
+		}
+		if err != nil {
+			d := diag.ForFile(f.RelPath, nil, err)
+			return fileScan{diag: &d}
+		}
+		file.Filename = f.RelPath
+		return fileScan{file: &file}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{Path: path}
+	for _, s := range scans {
+		if s.diag != nil {
+			inv.Diagnostics = append(inv.Diagnostics, *s.diag)
+		}
+		if s.file != nil {
+			inv.add(*s.file)
+		}
+	}
+	return inv, nil
+}
+
+// add appends f, and a diagnostic for each of its objects that could not be
+// decoded.
+func (inv *Inventory) add(f File) {
+	inv.Files = append(inv.Files, f)
+	for _, o := range f.Objects {
+		if o.Error != "" {
+			inv.Diagnostics = append(inv.Diagnostics, diag.Diagnostic{
+				Path:     f.Filename,
+				Type:     o.Kind,
+				Category: diag.Categorize(errors.New(o.Error)),
+				Error:    o.Error,
+			})
+		}
+	}
+}
+
+// ScanData classifies the contents of one file.
+func ScanData(filename string, data []byte, opts Options) File {
+	objects := pem.Detect(data)
+	file := File{Filename: filename, Kind: pem.Classify(objects)}
+	for i, o := range objects {
+		file.Objects = append(file.Objects, describe(filename, i, o, opts.Password))
+	}
+	return file
+}
+
+func describe(filename string, index int, o pem.Object, password string) Object {
+	obj := Object{Kind: o.Kind, Encoding: o.Encoding}
+	switch o.Kind {
+	case pem.KindCertificate:
+		describeCertificate(&obj, filename, index, o)
+	case pem.KindPrivateKey:
+		describePrivateKey(&obj, filename, o, password)
+	case pem.KindPublicKey:
+		describePublicKey(&obj, o)
+	case pem.KindCSR:
+		describeCSR(&obj, o)
+	case pem.KindCRL:
+		describeCRL(&obj, o)
+	case pem.KindPKCS7:
+		describePKCS7(&obj, o)
+	case pem.KindPKCS12:
+		describePKCS12(&obj, filename, o, password)
+	case pem.KindJKS:
+		obj.Detail = fmt.Sprintf("%s keystore, %d entries", o.Label, pem.JKSEntries(o.Bytes))
+	case pem.KindSSHKey:
+		describeSSHKey(&obj, o)
+	}
+	return obj
+}
+
+func algorithmLabel(keyType string, bits int) string {
+	if keyType == "<nil>" {
+		return ""
+	}
+	if bits == 0 {
+		return keyType
+	}
+	return fmt.Sprintf("%s %d", keyType, bits)
+}
+
+func describeCertificate(obj *Object, filename string, index int, o pem.Object) {
+	cert, err := certificate.ParseCertificateDER(o.Bytes, o.Encoding, filename, index)
+	if err != nil {
+		obj.Error = err.Error()
+		return
+	}
+	obj.Subject = cert.Subject
+	obj.Algorithm = algorithmLabel(cert.KeyType, cert.Bits)
+	if len(cert.PQCTypes) > 0 {
+		obj.Algorithm = strings.Join(cert.PQCTypes, ", ")
+	}
+	if cert.NotAfter.Before(time.Now()) {
+		obj.Detail = "expired " + cert.NotAfter.Format("2006-01-02")
+	} else {
+		obj.Detail = "expires " + cert.NotAfter.Format("2006-01-02")
+	}
+}
+
+func describePrivateKey(obj *Object, filename string, o pem.Object, password string) {
+	data := o.Bytes
+	if o.Encoding == "PEM" {
+		data = encpem.EncodeToMemory(&encpem.Block{Type: o.Label, Headers: o.Headers, Bytes: o.Bytes})
+	}
+	key, err := privatekey.ParsePrivateKeyFromBytes(data, filename, password)
+	if err != nil {
+		obj.Error = err.Error()
+		return
+	}
+
+	obj.Algorithm = algorithmLabel(key.KeyType, key.Bits)
+	if key.Curve != "" {
+		obj.Algorithm = key.KeyType + " " + key.Curve
+	}
+	if key.PQC != nil {
+		obj.Algorithm = key.PQC.ParameterSet
+	}
+	if o.Encrypted() {
+		obj.Detail = "encrypted"
+	}
+}
+
+func describePublicKey(obj *Object, o pem.Object) {
+	oid, err := pqc.PublicKeyAlgorithmOID(o.Bytes)
+	if alg, ok := pqc.ByOID(oid); err == nil && ok {
+		obj.Algorithm = alg.Name
+		return
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(o.Bytes)
+	if err != nil {
+		pub, err = x509.ParsePKCS1PublicKey(o.Bytes)
+	}
+	if err != nil {
+		obj.Algorithm = oid
+		return
+	}
+	obj.Algorithm = algorithmLabel(certificate.KeyTypeAndBits(pub))
+}
+
+func describeCSR(obj *Object, o pem.Object) {
+	req, err := csr.ParseCSRFromBytes(o.Bytes)
+	if err != nil {
+		obj.Error = err.Error()
+		return
+	}
+	obj.Subject = req.Subject
+	obj.Algorithm = algorithmLabel(req.KeyType, req.Bits)
+	if req.SignatureValid {
+		obj.Detail = "signature valid"
+	} else {
+		obj.Detail = "signature invalid"
+	}
+}
+
+func describeCRL(obj *Object, o pem.Object) {
+	info, err := crl.ParseCRLFromBytes(o.Bytes)
+	if err != nil {
+		obj.Error = err.Error()
+		return
+	}
+	obj.Subject = info.IssuerDN
+	obj.Algorithm = info.Algorithm
+	obj.Detail = fmt.Sprintf("%d revoked", len(info.Revoked))
+	if info.Stale {
+		obj.Detail += ", stale"
+	}
+}
+
+// contentInfo is a PKCS#7 ContentInfo; Content is the [0] EXPLICIT wrapper
+// of the content.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+func describePKCS7(obj *Object, o pem.Object) {
+	var ci contentInfo
+	var sd signedData
+	if _, err := asn1.Unmarshal(o.Bytes, &ci); err != nil {
+		obj.Error = "invalid PKCS#7 structure"
+		return
+	}
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		obj.Error = "invalid PKCS#7 signed data"
+		return
+	}
+	certs, _ := x509.ParseCertificates(sd.Certificates.Bytes)
+	obj.Detail = fmt.Sprintf("%d certificates", len(certs))
+	if len(certs) > 0 {
+		obj.Subject = certs[0].Subject.String()
+	}
+}
+
+func describePKCS12(obj *Object, filename string, o pem.Object, password string) {
+	p12, err := pkcs12.ParseP12FromBytes(o.Bytes, filename, password)
+	if err != nil {
+		obj.Error = err.Error()
+		return
+	}
+	obj.Detail = fmt.Sprintf("%d certificates, %d keys", len(p12.Certificates), len(p12.PrivateKeys))
+	if len(p12.Certificates) > 0 {
+		obj.Subject = p12.Certificates[0].Cert.Subject
+	}
+}
+
+func describeSSHKey(obj *Object, o pem.Object) {
+	if o.Encoding == "OpenSSH" {
+		obj.Algorithm = o.Label
+		obj.Detail = "public key"
+		return
+	}
+
+	data := encpem.EncodeToMemory(&encpem.Block{Type: o.Label, Bytes: o.Bytes})
+	key, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing):
+		if missing.PublicKey != nil {
+			obj.Algorithm = missing.PublicKey.Type()
+		}
+		obj.Detail = "encrypted private key"
+	case err != nil:
+		obj.Error = err.Error()
+	default:
+		signer, err := ssh.NewSignerFromKey(key)
+		if err != nil {
+			obj.Error = err.Error()
+			return
+		}
+		obj.Algorithm = signer.PublicKey().Type()
+		obj.Detail = "private key"
+	}
+}
@@ -0,0 +1,203 @@
+package scan
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	encpem "encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/pem"
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/marco-introini/certinfo/pkg/walk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func getTestPath(relPath string) string {
+	return filepath.Join("..", "..", "test_certs", relPath)
+}
+
+func readTestFile(t *testing.T, relPath string) []byte {
+	data, err := os.ReadFile(getTestPath(relPath))
+	require.NoError(t, err)
+	return data
+}
+
+// pkcs7 wraps DER certificates in a degenerate PKCS#7 SignedData, as in a
+// .p7b certificate bundle.
+func pkcs7(t *testing.T, certs ...[]byte) []byte {
+	var raw []byte
+	for _, c := range certs {
+		raw = append(raw, c...)
+	}
+	data, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+	}{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})
+	require.NoError(t, err)
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      asn1.RawValue{FullBytes: data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw},
+	})
+	require.NoError(t, err)
+	der, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd}})
+	require.NoError(t, err)
+	return der
+}
+
+func TestScanData(t *testing.T) {
+	chain := readTestFile(t, "chain/fullchain.crt")
+	root, _ := encpem.Decode(readTestFile(t, "chain/root-ca.crt"))
+
+	tests := []struct {
+		name      string
+		data      []byte
+		kind      pem.Kind
+		objects   int
+		subject   string
+		algorithm string
+		detail    string
+	}{
+		{"certificate", readTestFile(t, "traditional/rsa/server-rsa2048.crt"), pem.KindCertificate, 1, "CN=localhost,O=TestServer,C=IT", "RSA 2048", "expires"},
+		{"bundle", chain, pem.KindBundle, 2, "CN=localhost,O=TestServer,C=IT", "RSA 2048", "expires"},
+		{"private key", readTestFile(t, "traditional/ecdsa/server-ecdsa-p384.key"), pem.KindPrivateKey, 1, "", "EC P-384", ""},
+		{"encrypted key", readTestFile(t, "traditional/rsa-encrypted/ca-rsa2048-encrypted.key"), pem.KindPrivateKey, 1, "", "", "encrypted"},
+		{"csr", readTestFile(t, "csr/server-ecdsa.der"), pem.KindCSR, 1, "CN=ecdsa.csr.test.local,O=TestServer,C=IT", "ECDSA 256", "signature valid"},
+		{"crl", readTestFile(t, "crl/ca.crl"), pem.KindCRL, 1, "CN=Test CRL CA,O=TestCRL,C=IT", "SHA256-RSA", "1 revoked"},
+		{"pkcs7", pkcs7(t, root.Bytes), pem.KindPKCS7, 1, "CN=Test Root CA,O=TestChain,C=IT", "", "1 certificates"},
+		{"pkcs12 without password", readTestFile(t, "p12-format/server-rsa2048.pfx"), pem.KindPKCS12, 1, "", "", "password"},
+		{"unknown", []byte("serial 01\n"), pem.KindUnknown, 0, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ScanData("file", tt.data, Options{})
+			assert.Equal(t, tt.kind, file.Kind)
+			require.Len(t, file.Objects, tt.objects)
+			if tt.objects == 0 {
+				return
+			}
+			obj := file.Objects[0]
+			assert.Equal(t, tt.subject, obj.Subject)
+			assert.Equal(t, tt.algorithm, obj.Algorithm)
+			assert.Contains(t, obj.Detail+obj.Error, tt.detail)
+		})
+	}
+}
+
+func TestScanDataPassword(t *testing.T) {
+	file := ScanData("server.pfx", readTestFile(t, "p12-format/server-rsa2048.pfx"), Options{Password: "testpass"})
+	require.Len(t, file.Objects, 1)
+	assert.Equal(t, "1 certificates, 1 keys", file.Objects[0].Detail)
+	assert.Equal(t, "CN=localhost,O=TestServer,C=IT", file.Objects[0].Subject)
+
+	file = ScanData("ca.key", readTestFile(t, "traditional/rsa-encrypted/ca-rsa2048-encrypted.key"), Options{Password: "testpass"})
+	require.Len(t, file.Objects, 1)
+	assert.Equal(t, "RSA 2048", file.Objects[0].Algorithm)
+	assert.Equal(t, "encrypted", file.Objects[0].Detail)
+}
+
+func TestScanDataPublicKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	spki, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	file := ScanData("ec.pub", encpem.EncodeToMemory(&encpem.Block{Type: "PUBLIC KEY", Bytes: spki}), Options{})
+	assert.Equal(t, pem.KindPublicKey, file.Kind)
+	assert.Equal(t, "ECDSA 256", file.Objects[0].Algorithm)
+
+	mldsa, ok := pqc.ByName("ML-DSA-65")
+	require.True(t, ok)
+	spki, err = pqc.MarshalPublicKey(mldsa.OID, make([]byte, 1952))
+	require.NoError(t, err)
+
+	file = ScanData("mldsa.der", spki, Options{})
+	assert.Equal(t, pem.KindPublicKey, file.Kind)
+	assert.Equal(t, "DER", file.Objects[0].Encoding)
+	assert.Equal(t, "ML-DSA-65", file.Objects[0].Algorithm)
+}
+
+func TestScanDataSSH(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	file := ScanData("id_ed25519.pub", ssh.MarshalAuthorizedKey(sshPub), Options{})
+	assert.Equal(t, pem.KindSSHKey, file.Kind)
+	assert.Equal(t, Object{Kind: pem.KindSSHKey, Encoding: "OpenSSH", Algorithm: "ssh-ed25519", Detail: "public key"}, file.Objects[0])
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte("secret"))
+	require.NoError(t, err)
+	file = ScanData("id_ed25519", encpem.EncodeToMemory(block), Options{})
+	assert.Equal(t, pem.KindSSHKey, file.Kind)
+	assert.Equal(t, "PEM", file.Objects[0].Encoding)
+	assert.Equal(t, "ssh-ed25519", file.Objects[0].Algorithm)
+	assert.Equal(t, "encrypted private key", file.Objects[0].Detail)
+}
+
+func TestScanDataJKS(t *testing.T) {
+	file := ScanData("keystore.jks", []byte{0xfe, 0xed, 0xfe, 0xed, 0, 0, 0, 2, 0, 0, 0, 4}, Options{})
+	assert.Equal(t, pem.KindJKS, file.Kind)
+	assert.Equal(t, "JKS keystore, 4 entries", file.Objects[0].Detail)
+}
+
+func TestScan(t *testing.T) {
+	inv, err := Scan(getTestPath("crl"), Options{})
+	require.NoError(t, err)
+	counts := inv.Counts()
+	assert.Equal(t, 4, counts[pem.KindCRL])
+	assert.Equal(t, 3, counts[pem.KindCertificate])
+	assert.Equal(t, 3, counts[pem.KindPrivateKey])
+	assert.Equal(t, 2, counts[pem.KindUnknown], "OCSP responses are not classified")
+	assert.Equal(t, 10, inv.Objects())
+
+	flat, err := Scan(getTestPath(""), Options{})
+	require.NoError(t, err)
+	deep, err := Scan(getTestPath(""), Options{Options: walk.Options{Recursive: true}})
+	require.NoError(t, err)
+	assert.Greater(t, len(deep.Files), len(flat.Files))
+
+	inv, err = Scan(getTestPath("chain/fullchain.crt"), Options{})
+	require.NoError(t, err)
+	require.Len(t, inv.Files, 1)
+	assert.Equal(t, "fullchain.crt", inv.Files[0].Filename)
+	assert.Equal(t, pem.KindBundle, inv.Files[0].Kind)
+
+	_, err = Scan("/nonexistent/path", Options{})
+	assert.Error(t, err)
+}
+
+func TestScanDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.crt"), []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "dangling.crt")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0644))
+
+	inv, err := Scan(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, inv.Diagnostics, 2)
+	assert.Equal(t, "broken.crt", inv.Diagnostics[0].Path)
+	assert.Equal(t, pem.KindCertificate, inv.Diagnostics[0].Type)
+	assert.Equal(t, diag.Corrupt, inv.Diagnostics[0].Category)
+	assert.Equal(t, "dangling.crt", inv.Diagnostics[1].Path)
+	assert.Equal(t, diag.Unreadable, inv.Diagnostics[1].Category)
+}
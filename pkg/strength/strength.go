@@ -0,0 +1,200 @@
+// Package strength normalizes key and signature sizes to a comparable
+// security strength: classical bits of security per NIST SP 800-57 Part 1,
+// plus the NIST security category of post-quantum algorithms.
+package strength
+
+import (
+	"crypto/dsa"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"math"
+
+	"github.com/marco-introini/certinfo/pkg/pqc"
+)
+
+// Minimum is the lowest strength SP 800-57 and SP 800-131A accept for
+// protecting data today; anything below it is weak.
+const Minimum = 112
+
+type Strength struct {
+	// Bits is the classical security strength. Zero means unknown.
+	Bits int
+	// Category is the NIST PQC security category (1-5), zero for classical
+	// algorithms.
+	Category int
+	// LimitedBy names what caps Bits below the key's own strength, such as
+	// a SHA-1 signature.
+	LimitedBy string `json:",omitempty"`
+}
+
+func (s Strength) Known() bool {
+	return s.Bits > 0
+}
+
+func (s Strength) IsWeak() bool {
+	return s.Below(Minimum)
+}
+
+// Below reports whether s is known and lower than bits.
+func (s Strength) Below(bits int) bool {
+	return s.Known() && s.Bits < bits
+}
+
+func (s Strength) String() string {
+	if !s.Known() {
+		return "unknown"
+	}
+	text := fmt.Sprintf("%d bits", s.Bits)
+	if s.Category > 0 {
+		text += fmt.Sprintf(", NIST category %d", s.Category)
+	}
+	if s.LimitedBy != "" {
+		text += ", limited by " + s.LimitedBy
+	}
+	return text
+}
+
+// Short is the compact form used in directory tables.
+func (s Strength) Short() string {
+	switch {
+	case !s.Known():
+		return "-"
+	case s.LimitedBy != "":
+		return fmt.Sprintf("%d (%s)", s.Bits, s.LimitedBy)
+	case s.Category > 0:
+		return fmt.Sprintf("%d (cat %d)", s.Bits, s.Category)
+	}
+	return fmt.Sprintf("%d", s.Bits)
+}
+
+// categoryBits are the classical strengths the NIST PQC categories are
+// defined against: AES-128, SHA-256, AES-192, SHA-384 and AES-256.
+var categoryBits = map[int]int{1: 128, 2: 128, 3: 192, 4: 192, 5: 256}
+
+// ForAlgorithm returns the strength of a post-quantum or composite
+// algorithm from its NIST category.
+func ForAlgorithm(alg pqc.Algorithm) Strength {
+	return Strength{Bits: categoryBits[alg.Category], Category: alg.Category}
+}
+
+// ForPublicKey returns the strength of a classical public key, or an unknown
+// strength for key types it does not recognise.
+func ForPublicKey(pub any) Strength {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return Strength{Bits: factoringBits(key.N.BitLen())}
+	case *dsa.PublicKey:
+		return Strength{Bits: min(factoringBits(key.P.BitLen()), key.Q.BitLen()/2)}
+	case *ecdsa.PublicKey:
+		return Strength{Bits: curveBits(key.Curve.Params().BitSize)}
+	case *ecdh.PublicKey:
+		switch key.Curve() {
+		case ecdh.X25519(), ecdh.P256():
+			return Strength{Bits: 128}
+		case ecdh.P384():
+			return Strength{Bits: 192}
+		case ecdh.P521():
+			return Strength{Bits: 256}
+		}
+	case ed25519.PublicKey:
+		return Strength{Bits: 128}
+	}
+	return Strength{}
+}
+
+// factoringBits follows SP 800-57 Table 2 for RSA, DSA and DH moduli. Sizes
+// below 1024 bits, which the table does not cover, use the general number
+// field sieve estimate of FIPS 140 Implementation Guidance.
+func factoringBits(n int) int {
+	switch {
+	case n >= 15360:
+		return 256
+	case n >= 7680:
+		return 192
+	case n >= 3072:
+		return 128
+	case n >= 2048:
+		return 112
+	case n >= 1024:
+		return 80
+	case n <= 0:
+		return 0
+	}
+	x := float64(n) * math.Ln2
+	return int((1.923*math.Cbrt(x)*math.Pow(math.Log(x), 2.0/3) - 4.69) / math.Ln2)
+}
+
+// curveBits follows SP 800-57 Table 2 for elliptic curve field sizes.
+func curveBits(f int) int {
+	switch {
+	case f >= 512:
+		return 256
+	case f >= 384:
+		return 192
+	case f >= 256:
+		return 128
+	case f >= 224:
+		return 112
+	case f >= 160:
+		return 80
+	}
+	return f / 2
+}
+
+// hashBits are the collision resistance strengths of signature hashes per
+// SP 800-57 Table 3. SP 800-57 only says "< 80" for SHA-1; the values for
+// SHA-1, MD5 and MD2 are the costs of the best published collision attacks.
+var hashBits = map[x509.SignatureAlgorithm]struct {
+	hash string
+	bits int
+}{
+	x509.MD2WithRSA:       {"MD2", 63},
+	x509.MD5WithRSA:       {"MD5", 18},
+	x509.SHA1WithRSA:      {"SHA-1", 63},
+	x509.DSAWithSHA1:      {"SHA-1", 63},
+	x509.ECDSAWithSHA1:    {"SHA-1", 63},
+	x509.SHA256WithRSA:    {"SHA-256", 128},
+	x509.SHA256WithRSAPSS: {"SHA-256", 128},
+	x509.DSAWithSHA256:    {"SHA-256", 128},
+	x509.ECDSAWithSHA256:  {"SHA-256", 128},
+	x509.SHA384WithRSA:    {"SHA-384", 192},
+	x509.SHA384WithRSAPSS: {"SHA-384", 192},
+	x509.ECDSAWithSHA384:  {"SHA-384", 192},
+	x509.SHA512WithRSA:    {"SHA-512", 256},
+	x509.SHA512WithRSAPSS: {"SHA-512", 256},
+	x509.ECDSAWithSHA512:  {"SHA-512", 256},
+}
+
+// Limit caps key at the strength of the hash in a classical signature
+// algorithm. Signatures without a separate hash, such as Ed25519, leave it
+// unchanged. A weak hash makes even a key of unknown strength weak.
+func Limit(key Strength, sig x509.SignatureAlgorithm) Strength {
+	h, ok := hashBits[sig]
+	if !ok || (!key.Known() && h.bits >= Minimum) {
+		return key
+	}
+	return limit(key, h.bits, h.hash+" signature")
+}
+
+// LimitAlgorithm caps key at the strength of a post-quantum or composite
+// signature algorithm, such as ML-DSA-44 on a P-384 key.
+func LimitAlgorithm(key Strength, sig pqc.Algorithm) Strength {
+	bits, ok := categoryBits[sig.Category]
+	if !ok || !key.Known() {
+		return key
+	}
+	return limit(key, bits, sig.Name+" signature")
+}
+
+func limit(key Strength, bits int, by string) Strength {
+	if key.Known() && key.Bits <= bits {
+		return key
+	}
+	key.Bits = bits
+	key.LimitedBy = by
+	return key
+}
@@ -0,0 +1,151 @@
+package strength
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
+	"testing"
+
+	"github.com/marco-introini/certinfo/pkg/pqc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaKey(bits int) *rsa.PublicKey {
+	n := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	return &rsa.PublicKey{N: n, E: 65537}
+}
+
+func TestForPublicKey(t *testing.T) {
+	tests := []struct {
+		name string
+		pub  any
+		bits int
+	}{
+		{"RSA-512", rsaKey(512), 57},
+		{"RSA-1024", rsaKey(1024), 80},
+		{"RSA-2048", rsaKey(2048), 112},
+		{"RSA-3072", rsaKey(3072), 128},
+		{"RSA-4096", rsaKey(4096), 128},
+		{"RSA-7680", rsaKey(7680), 192},
+		{"RSA-15360", rsaKey(15360), 256},
+		{"P-224", &ecdsa.PublicKey{Curve: elliptic.P224()}, 112},
+		{"P-256", &ecdsa.PublicKey{Curve: elliptic.P256()}, 128},
+		{"P-384", &ecdsa.PublicKey{Curve: elliptic.P384()}, 192},
+		{"P-521", &ecdsa.PublicKey{Curve: elliptic.P521()}, 256},
+		{"Ed25519", ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)), 128},
+		{"unknown", "not a key", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ForPublicKey(tt.pub)
+			assert.Equal(t, tt.bits, s.Bits)
+			assert.Zero(t, s.Category)
+		})
+	}
+}
+
+func TestForPublicKeyECDH(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, 128, ForPublicKey(priv.PublicKey()).Bits)
+
+	priv, err = ecdh.P384().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, 192, ForPublicKey(priv.PublicKey()).Bits)
+}
+
+func TestForAlgorithm(t *testing.T) {
+	tests := []struct {
+		name     string
+		bits     int
+		category int
+	}{
+		{"ML-KEM-512", 128, 1},
+		{"ML-DSA-44", 128, 2},
+		{"ML-DSA-65", 192, 3},
+		{"ML-DSA-87", 256, 5},
+		{"SLH-DSA-SHAKE-256F", 256, 5},
+		{"MLDSA65-ECDSA-P256-SHA512", 192, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alg, ok := pqc.ByName(tt.name)
+			require.True(t, ok)
+			s := ForAlgorithm(alg)
+			assert.Equal(t, tt.bits, s.Bits)
+			assert.Equal(t, tt.category, s.Category)
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	rsa4096 := ForPublicKey(rsaKey(4096))
+
+	s := Limit(rsa4096, x509.SHA1WithRSA)
+	assert.Equal(t, 63, s.Bits)
+	assert.Equal(t, "SHA-1 signature", s.LimitedBy)
+	assert.True(t, s.IsWeak())
+
+	s = Limit(rsa4096, x509.SHA512WithRSA)
+	assert.Equal(t, rsa4096, s)
+	assert.False(t, s.IsWeak())
+
+	p384 := ForPublicKey(&ecdsa.PublicKey{Curve: elliptic.P384()})
+	s = Limit(p384, x509.ECDSAWithSHA256)
+	assert.Equal(t, 128, s.Bits)
+	assert.Equal(t, "SHA-256 signature", s.LimitedBy)
+
+	assert.Equal(t, p384, Limit(p384, x509.PureEd25519))
+
+	// A weak hash flags a key of unknown strength; a strong one does not.
+	assert.Equal(t, 18, Limit(Strength{}, x509.MD5WithRSA).Bits)
+	assert.False(t, Limit(Strength{}, x509.SHA256WithRSA).Known())
+}
+
+func TestLimitAlgorithm(t *testing.T) {
+	mldsa44, _ := pqc.ByName("ML-DSA-44")
+	p384 := ForPublicKey(&ecdsa.PublicKey{Curve: elliptic.P384()})
+
+	s := LimitAlgorithm(p384, mldsa44)
+	assert.Equal(t, 128, s.Bits)
+	assert.Equal(t, "ML-DSA-44 signature", s.LimitedBy)
+
+	mldsa87, _ := pqc.ByName("ML-DSA-87")
+	assert.Equal(t, p384, LimitAlgorithm(p384, mldsa87))
+}
+
+func TestBelow(t *testing.T) {
+	assert.True(t, Strength{Bits: 80}.Below(112))
+	assert.False(t, Strength{Bits: 112}.Below(112))
+	assert.False(t, Strength{}.Below(112))
+	assert.True(t, Strength{Bits: 80}.IsWeak())
+	assert.False(t, Strength{Bits: 128}.IsWeak())
+}
+
+func TestStrengthString(t *testing.T) {
+	tests := []struct {
+		s     Strength
+		long  string
+		short string
+	}{
+		{Strength{}, "unknown", "-"},
+		{Strength{Bits: 112}, "112 bits", "112"},
+		{Strength{Bits: 192, Category: 3}, "192 bits, NIST category 3", "192 (cat 3)"},
+		{Strength{Bits: 63, LimitedBy: "SHA-1 signature"}, "63 bits, limited by SHA-1 signature", "63 (SHA-1 signature)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.long, func(t *testing.T) {
+			assert.Equal(t, tt.long, tt.s.String())
+			assert.Equal(t, tt.short, tt.s.Short())
+		})
+	}
+}
@@ -8,9 +8,22 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/marco-introini/certinfo/pkg/cache"
 	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/chain"
+	"github.com/marco-introini/certinfo/pkg/crl"
+	"github.com/marco-introini/certinfo/pkg/csr"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/marco-introini/certinfo/pkg/image"
+	"github.com/marco-introini/certinfo/pkg/match"
+	"github.com/marco-introini/certinfo/pkg/ocsp"
 	"github.com/marco-introini/certinfo/pkg/pkcs12"
 	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/remote"
+	"github.com/marco-introini/certinfo/pkg/report"
+	"github.com/marco-introini/certinfo/pkg/scan"
+	"github.com/marco-introini/certinfo/pkg/strength"
 )
 
 type OutputFormat string
@@ -55,19 +68,229 @@ func PrintCertificateInfo(cert *certificate.CertificateInfo, format OutputFormat
 	fmt.Fprintf(w, "Not After:\t%s\n", formatDate(cert.NotAfter))
 	fmt.Fprintf(w, "Algorithm:\t%s\n", cert.Algorithm)
 	fmt.Fprintf(w, "Bits:\t%d\n", cert.Bits)
+	fmt.Fprintf(w, "Security Strength:\t%s\n", strengthColor(cert.Strength, cert.Strength.String()))
 	fmt.Fprintf(w, "Serial Number:\t%s\n", cert.SerialNumber)
+	fmt.Fprintf(w, "SHA-1 Fingerprint:\t%s\n", cert.SHA1Fingerprint)
+	fmt.Fprintf(w, "SHA-256 Fingerprint:\t%s\n", cert.SHA256Fingerprint)
+	fmt.Fprintf(w, "SPKI SHA-256 (base64):\t%s\n", cert.SPKISHA256)
+	fmt.Fprintf(w, "SPKI SHA-256 (hex):\t%s\n", cert.SPKISHA256Hex)
 	fmt.Fprintf(w, "Is CA:\t%v\n", cert.IsCA)
-	fmt.Fprintf(w, "Quantum Safe:\t%v\n", cert.IsQuantumSafe)
+	fmt.Fprintf(w, "Quantum Safe:\t%s\n", cert.QuantumSafety)
 	if len(cert.PQCTypes) > 0 {
 		fmt.Fprintf(w, "PQC Types:\t%v\n", cert.PQCTypes)
 	}
+	for i, c := range cert.KeyComponents {
+		fmt.Fprintf(w, "Key Component %d:\t%s\n", i+1, formatComponent(c))
+	}
+	for i, c := range cert.SignatureComponents {
+		fmt.Fprintf(w, "Signature Component %d:\t%s\n", i+1, formatComponent(c))
+	}
+	if cert.AltPublicKey != nil {
+		fmt.Fprintf(w, "Alt Public Key:\t%s\n", formatComponent(*cert.AltPublicKey))
+	}
+	if cert.AltSignature != nil {
+		fmt.Fprintf(w, "Alt Signature:\t%s\n", formatComponent(*cert.AltSignature))
+	}
 
 	if len(cert.SANs) > 0 {
-		fmt.Fprintf(w, "SANs:\t%v\n", cert.SANs)
+		fmt.Fprintf(w, "DNS SANs:\t%v\n", cert.SANs)
+	}
+	if len(cert.IPSANs) > 0 {
+		fmt.Fprintf(w, "IP SANs:\t%v\n", cert.IPSANs)
+	}
+	if len(cert.EmailSANs) > 0 {
+		fmt.Fprintf(w, "Email SANs:\t%v\n", cert.EmailSANs)
+	}
+	if len(cert.URISANs) > 0 {
+		fmt.Fprintf(w, "URI SANs:\t%v\n", cert.URISANs)
+	}
+	for _, on := range cert.OtherNameSANs {
+		fmt.Fprintf(w, "Other SAN:\t%s=%s\n", on.Type, on.Value)
 	}
 	if len(cert.ExtKeyUsageStrings) > 0 {
 		fmt.Fprintf(w, "Ext Key Usage:\t%v\n", cert.ExtKeyUsageStrings)
 	}
+	if len(cert.KeyUsage) > 0 {
+		fmt.Fprintf(w, "Key Usage:\t[%s]\n", strings.Join(cert.KeyUsage, ", "))
+	}
+	if bc := cert.BasicConstraints; bc != nil {
+		if bc.PathLen != nil {
+			fmt.Fprintf(w, "Basic Constraints:\tCA:%v, pathLen:%d\n", bc.IsCA, *bc.PathLen)
+		} else {
+			fmt.Fprintf(w, "Basic Constraints:\tCA:%v\n", bc.IsCA)
+		}
+	}
+	if cert.SubjectKeyID != "" {
+		fmt.Fprintf(w, "Subject Key ID:\t%s\n", cert.SubjectKeyID)
+	}
+	if cert.AuthorityKeyID != "" {
+		fmt.Fprintf(w, "Authority Key ID:\t%s\n", cert.AuthorityKeyID)
+	}
+	if len(cert.CRLDistributionPoints) > 0 {
+		fmt.Fprintf(w, "CRL Distribution:\t%v\n", cert.CRLDistributionPoints)
+	}
+	if len(cert.OCSPServers) > 0 {
+		fmt.Fprintf(w, "OCSP Servers:\t%v\n", cert.OCSPServers)
+	}
+	if len(cert.IssuingCertificateURLs) > 0 {
+		fmt.Fprintf(w, "CA Issuers:\t%v\n", cert.IssuingCertificateURLs)
+	}
+	if len(cert.Policies) > 0 {
+		fmt.Fprintf(w, "Policies:\t[%s]\n", strings.Join(cert.Policies, ", "))
+	}
+	if nc := cert.NameConstraints; nc != nil {
+		fmt.Fprintf(w, "Name Constraints:\tcritical:%v\n", nc.Critical)
+		printConstraint := func(label string, values []string) {
+			if len(values) > 0 {
+				fmt.Fprintf(w, "  %s:\t%v\n", label, values)
+			}
+		}
+		printConstraint("Permitted DNS", nc.PermittedDNS)
+		printConstraint("Excluded DNS", nc.ExcludedDNS)
+		printConstraint("Permitted IPs", nc.PermittedIPs)
+		printConstraint("Excluded IPs", nc.ExcludedIPs)
+		printConstraint("Permitted Emails", nc.PermittedEmails)
+		printConstraint("Excluded Emails", nc.ExcludedEmails)
+		printConstraint("Permitted URIs", nc.PermittedURIs)
+		printConstraint("Excluded URIs", nc.ExcludedURIs)
+	}
+	if cert.MustStaple {
+		fmt.Fprintf(w, "Must Staple:\tYes\n")
+	}
+	for _, ext := range cert.OtherExtensions {
+		if ext.Critical {
+			fmt.Fprintf(w, "Extension:\t%s (critical)\n", ext.OID)
+		} else {
+			fmt.Fprintf(w, "Extension:\t%s\n", ext.OID)
+		}
+	}
+	if r := cert.Revocation; r != nil {
+		unverified := ""
+		if !r.Verified {
+			unverified = Color(" (CRL signature not verified)", ColorYellow)
+		}
+		switch r.Status {
+		case certificate.RevocationRevoked:
+			fmt.Fprintf(w, "Revocation:\t%s (%s) at %s [%s]%s\n", Color("revoked", ColorRed), r.Reason, formatDate(r.RevocationTime), r.Source, unverified)
+		case certificate.RevocationGood:
+			fmt.Fprintf(w, "Revocation:\t%s [%s]%s\n", Color("not revoked", ColorGreen), r.Source, unverified)
+		default:
+			fmt.Fprintf(w, "Revocation:\t%s\n", Color("unknown (no CRL from this issuer)", ColorYellow))
+		}
+	}
+	if s := cert.Signature; s != nil {
+		switch s.Status {
+		case certificate.SignatureValid:
+			fmt.Fprintf(w, "Signature:\t%s [%s]\n", Color("valid", ColorGreen), s.Issuer)
+		case certificate.SignatureInvalid:
+			fmt.Fprintf(w, "Signature:\t%s (%s) [%s]\n", Color("invalid", ColorRed), s.Error, s.Issuer)
+		default:
+			fmt.Fprintf(w, "Signature:\t%s (%s)\n", Color("not verified", ColorYellow), s.Error)
+		}
+	}
+}
+
+func formatComponent(c certificate.Component) string {
+	switch {
+	case c.PQC:
+		return fmt.Sprintf("%s (NIST category %d, %d bytes)", c.Algorithm, c.Category, c.Size)
+	case c.Bits > 0:
+		return fmt.Sprintf("%s (%d bits, %d bytes)", c.Algorithm, c.Bits, c.Size)
+	default:
+		return fmt.Sprintf("%s (%d bytes)", c.Algorithm, c.Size)
+	}
+}
+
+func quantumSafetyColor(class string) string {
+	switch class {
+	case certificate.QuantumPQC:
+		return Color(class, ColorGreen)
+	case certificate.QuantumHybrid:
+		return Color(class, ColorCyan)
+	default:
+		return Color(class, ColorYellow)
+	}
+}
+
+func PrintCertificateInfos(certs []*certificate.CertificateInfo, format OutputFormat) {
+	if len(certs) == 1 && !(format == FormatJSON && jsonArrays) {
+		PrintCertificateInfo(certs[0], format)
+		return
+	}
+
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(certs, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	// Certificates read from several files are headed by their file name.
+	severalFiles := false
+	for _, cert := range certs {
+		if cert.Filename != certs[0].Filename {
+			severalFiles = true
+		}
+	}
+
+	for i, cert := range certs {
+		if i > 0 {
+			fmt.Println()
+		}
+		if severalFiles {
+			fmt.Printf("--- %s: Certificate %d ---\n", cert.Filename, cert.Index+1)
+		} else {
+			fmt.Printf("--- Certificate %d ---\n", cert.Index+1)
+		}
+		PrintCertificateInfo(cert, format)
+	}
+}
+
+// jsonArrays is set by ForceJSONArrays.
+var jsonArrays bool
+
+// ForceJSONArrays makes the printers of several items print a JSON array
+// even for a single item, for commands given several inputs or a glob, so
+// that the shape of their output does not depend on how many files matched.
+func ForceJSONArrays() {
+	jsonArrays = true
+}
+
+// printEach prints a single item with print, and several as one JSON array
+// or as consecutive blocks under numbered headings.
+func printEach[T any](items []T, format OutputFormat, heading string, print func(T, OutputFormat)) {
+	if len(items) == 1 && !(format == FormatJSON && jsonArrays) {
+		print(items[0], format)
+		return
+	}
+
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	for i, item := range items {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("--- %s %d ---\n", heading, i+1)
+		print(item, format)
+	}
+}
+
+func compactFingerprint(fp string) string {
+	if fp == "" {
+		return "-"
+	}
+	return strings.ToLower(strings.ReplaceAll(fp, ":", ""))
 }
 
 func padRight(s string, width int) string {
@@ -89,21 +312,29 @@ func PrintCertificateSummaries(summaries []certificate.CertificateSummary, forma
 		return
 	}
 
-	headers := []string{"FILENAME", "ENCODING", "CN", "ISSUER", "STATUS", "QUANTUM SAFE", "PQC TYPES"}
+	headers := []string{"FILENAME", "ENCODING", "CN", "ISSUER", "STATUS", "STRENGTH", "QUANTUM SAFE", "PQC TYPES", "SHA-256"}
 	colWidths := make([]int, len(headers))
 	for i, h := range headers {
 		colWidths[i] = len(h)
 	}
 
+	certsPerFile := make(map[string]int)
+	for _, s := range summaries {
+		certsPerFile[s.Filename]++
+	}
+	filename := func(s certificate.CertificateSummary) string {
+		if certsPerFile[s.Filename] > 1 {
+			return fmt.Sprintf("%s[%d]", s.Filename, s.Index+1)
+		}
+		return s.Filename
+	}
+
 	for _, s := range summaries {
 		pqcTypes := "-"
 		if len(s.PQCTypes) > 0 {
 			pqcTypes = strings.Join(s.PQCTypes, ", ")
 		}
-		data := []string{s.Filename, s.Encoding, s.CommonName, s.Issuer, s.Status, "Yes", pqcTypes}
-		if !s.IsQuantumSafe {
-			data[5] = "No"
-		}
+		data := []string{filename(s), s.Encoding, s.CommonName, s.Issuer, s.Status, s.Strength.Short(), s.QuantumSafety, pqcTypes, compactFingerprint(s.SHA256)}
 		for i, d := range data {
 			if len(d) > colWidths[i] {
 				colWidths[i] = len(d)
@@ -132,35 +363,29 @@ func PrintCertificateSummaries(summaries []certificate.CertificateSummary, forma
 		}
 
 		status := s.Status
-		qs := "No"
-		if s.IsQuantumSafe {
-			qs = "Yes"
-		}
+		qs := quantumSafetyColor(s.QuantumSafety)
 
 		if ColorsEnabled {
 			switch s.Status {
 			case "valid":
 				status = Color(s.Status, ColorGreen)
-			case "expired":
+			case "expired", certificate.RevocationRevoked:
 				status = Color(s.Status, ColorRed)
 			case "expiring":
 				status = Color(s.Status, ColorYellow)
 			}
-			if s.IsQuantumSafe {
-				qs = Color(qs, ColorGreen)
-			} else {
-				qs = Color(qs, ColorYellow)
-			}
 		}
 
 		row := []string{
-			padRight(s.Filename, colWidths[0]),
+			padRight(filename(s), colWidths[0]),
 			padRight(s.Encoding, colWidths[1]),
 			padRight(s.CommonName, colWidths[2]),
 			padRight(s.Issuer, colWidths[3]),
 			padRight(status, colWidths[4]),
-			padRight(qs, colWidths[5]),
-			padRight(pqcTypes, colWidths[6]),
+			padRight(strengthColor(s.Strength, s.Strength.Short()), colWidths[5]),
+			padRight(qs, colWidths[6]),
+			padRight(pqcTypes, colWidths[7]),
+			padRight(compactFingerprint(s.SHA256), colWidths[8]),
 		}
 		for i, cell := range row {
 			fmt.Print(cell)
@@ -172,6 +397,10 @@ func PrintCertificateSummaries(summaries []certificate.CertificateSummary, forma
 	}
 }
 
+func PrintKeyInfos(keys []*privatekey.KeyInfo, format OutputFormat) {
+	printEach(keys, format, "Private Key", PrintKeyInfo)
+}
+
 func PrintKeyInfo(key *privatekey.KeyInfo, format OutputFormat) {
 	if format == FormatJSON {
 		jsonBytes, err := json.MarshalIndent(key, "", "  ")
@@ -191,10 +420,57 @@ func PrintKeyInfo(key *privatekey.KeyInfo, format OutputFormat) {
 	fmt.Fprintf(w, "Key Type:\t%s\n", key.KeyType)
 	fmt.Fprintf(w, "Algorithm:\t%s\n", key.Algorithm)
 	fmt.Fprintf(w, "Bits:\t%d\n", key.Bits)
+	fmt.Fprintf(w, "Security Strength:\t%s\n", strengthColor(key.Strength, key.Strength.String()))
 	fmt.Fprintf(w, "Quantum Safe:\t%v\n", key.IsQuantumSafe)
+	if p := key.PQC; p != nil {
+		if p.Category > 0 {
+			fmt.Fprintf(w, "Parameter Set:\t%s (NIST category %d)\n", p.ParameterSet, p.Category)
+		} else {
+			fmt.Fprintf(w, "Parameter Set:\t%s\n", Color(p.ParameterSet, ColorYellow))
+		}
+		switch {
+		case p.Encoding != "":
+			fmt.Fprintf(w, "Key Encoding:\t%s\n", formatPQCKeyEncoding(p))
+			if p.PublicKeySize > 0 {
+				fmt.Fprintf(w, "Public Key Size:\t%d bytes\n", p.PublicKeySize)
+			}
+		case p.Error != "":
+			fmt.Fprintf(w, "Key Encoding:\t%s (%s)\n", Color("not decoded", ColorYellow), p.Error)
+		}
+		if p.SeedMatches != nil {
+			if *p.SeedMatches {
+				fmt.Fprintf(w, "Seed Check:\t%s\n", Color("expanded key matches seed", ColorGreen))
+			} else {
+				fmt.Fprintf(w, "Seed Check:\t%s\n", Color("expanded key does not match seed", ColorRed))
+			}
+		}
+	}
 	if key.Curve != "" {
 		fmt.Fprintf(w, "Curve:\t%s\n", key.Curve)
 	}
+	if key.SPKISHA256 != "" {
+		fmt.Fprintf(w, "SPKI SHA-256 (base64):\t%s\n", key.SPKISHA256)
+		fmt.Fprintf(w, "SPKI SHA-256 (hex):\t%s\n", key.SPKISHA256Hex)
+	}
+}
+
+func formatPQCKeyEncoding(p *privatekey.PQCKeyInfo) string {
+	switch {
+	case p.SeedSize > 0 && p.ExpandedSize > 0:
+		return fmt.Sprintf("%s (%d-byte seed, %d-byte expanded key)", p.Encoding, p.SeedSize, p.ExpandedSize)
+	case p.SeedSize > 0:
+		return fmt.Sprintf("%s (%d bytes)", p.Encoding, p.SeedSize)
+	default:
+		return fmt.Sprintf("%s (%d bytes)", p.Encoding, p.ExpandedSize)
+	}
+}
+
+// strengthColor marks text red when the strength it describes is weak.
+func strengthColor(s strength.Strength, text string) string {
+	if s.IsWeak() {
+		return Color(text, ColorRed)
+	}
+	return text
 }
 
 func PrintKeySummaries(summaries []privatekey.KeySummary, format OutputFormat) {
@@ -208,7 +484,7 @@ func PrintKeySummaries(summaries []privatekey.KeySummary, format OutputFormat) {
 		return
 	}
 
-	headers := []string{"FILENAME", "ENCODING", "TYPE", "BITS", "QUANTUM SAFE"}
+	headers := []string{"FILENAME", "ENCODING", "TYPE", "BITS", "STRENGTH", "QUANTUM SAFE"}
 	colWidths := make([]int, len(headers))
 	for i, h := range headers {
 		colWidths[i] = len(h)
@@ -220,7 +496,7 @@ func PrintKeySummaries(summaries []privatekey.KeySummary, format OutputFormat) {
 		if !s.IsQuantumSafe {
 			qs = "No"
 		}
-		data := []string{s.Filename, s.Encoding, s.KeyType, bitsStr, qs}
+		data := []string{s.Filename, s.Encoding, s.KeyType, bitsStr, s.Strength.Short(), qs}
 		for i, d := range data {
 			if len(d) > colWidths[i] {
 				colWidths[i] = len(d)
@@ -261,7 +537,8 @@ func PrintKeySummaries(summaries []privatekey.KeySummary, format OutputFormat) {
 			padRight(s.Encoding, colWidths[1]),
 			padRight(s.KeyType, colWidths[2]),
 			padRight(fmt.Sprintf("%d", s.Bits), colWidths[3]),
-			padRight(qs, colWidths[4]),
+			padRight(strengthColor(s.Strength, s.Strength.Short()), colWidths[4]),
+			padRight(qs, colWidths[5]),
 		}
 		for i, cell := range row {
 			fmt.Print(cell)
@@ -285,39 +562,49 @@ type p12Summary struct {
 	PQCTypes    []string
 }
 
-func PrintP12Info(p12 *pkcs12.P12Info, format OutputFormat) {
-	if format == FormatJSON {
-		type jsonOutput struct {
-			Filename                string                        `json:"filename"`
-			Encoding                string                        `json:"encoding"`
-			MacAlgorithm            string                        `json:"macAlgorithm"`
-			MacIterations           int                           `json:"macIterations"`
-			KdfAlgorithm            string                        `json:"kdfAlgorithm"`
-			KeyEncryptionAlgorithm  string                        `json:"keyEncryptionAlgorithm"`
-			CertEncryptionAlgorithm string                        `json:"certEncryptionAlgorithm"`
-			CertificateCount        int                           `json:"certificateCount"`
-			PrivateKeyCount         int                           `json:"privateKeyCount"`
-			Certificates            []certificate.CertificateInfo `json:"certificates"`
-			PrivateKeys             []privatekey.KeyInfo          `json:"privateKeys"`
-		}
-		out := jsonOutput{
-			Filename:                p12.Filename,
-			Encoding:                p12.Encoding,
-			MacAlgorithm:            p12.MacAlgorithm,
-			MacIterations:           p12.MacIterations,
-			KdfAlgorithm:            p12.KdfAlgorithm,
-			KeyEncryptionAlgorithm:  p12.KeyEncryptionAlgorithm,
-			CertEncryptionAlgorithm: p12.CertEncryptionAlgorithm,
-			CertificateCount:        p12.CertificateCount,
-			PrivateKeyCount:         p12.PrivateKeyCount,
-			Certificates:            []certificate.CertificateInfo{},
-			PrivateKeys:             []privatekey.KeyInfo{},
-		}
-		for _, c := range p12.Certificates {
-			out.Certificates = append(out.Certificates, *c.Cert)
-		}
-		for _, k := range p12.PrivateKeys {
-			out.PrivateKeys = append(out.PrivateKeys, *k.Key)
+// p12JSON is the JSON form of a PKCS#12 file.
+type p12JSON struct {
+	Filename                string                        `json:"filename"`
+	Encoding                string                        `json:"encoding"`
+	MacAlgorithm            string                        `json:"macAlgorithm"`
+	MacIterations           int                           `json:"macIterations"`
+	KdfAlgorithm            string                        `json:"kdfAlgorithm"`
+	KeyEncryptionAlgorithm  string                        `json:"keyEncryptionAlgorithm"`
+	CertEncryptionAlgorithm string                        `json:"certEncryptionAlgorithm"`
+	CertificateCount        int                           `json:"certificateCount"`
+	PrivateKeyCount         int                           `json:"privateKeyCount"`
+	Certificates            []certificate.CertificateInfo `json:"certificates"`
+	PrivateKeys             []privatekey.KeyInfo          `json:"privateKeys"`
+}
+
+func newP12JSON(p12 *pkcs12.P12Info) p12JSON {
+	out := p12JSON{
+		Filename:                p12.Filename,
+		Encoding:                p12.Encoding,
+		MacAlgorithm:            p12.MacAlgorithm,
+		MacIterations:           p12.MacIterations,
+		KdfAlgorithm:            p12.KdfAlgorithm,
+		KeyEncryptionAlgorithm:  p12.KeyEncryptionAlgorithm,
+		CertEncryptionAlgorithm: p12.CertEncryptionAlgorithm,
+		CertificateCount:        p12.CertificateCount,
+		PrivateKeyCount:         p12.PrivateKeyCount,
+		Certificates:            []certificate.CertificateInfo{},
+		PrivateKeys:             []privatekey.KeyInfo{},
+	}
+	for _, c := range p12.Certificates {
+		out.Certificates = append(out.Certificates, *c.Cert)
+	}
+	for _, k := range p12.PrivateKeys {
+		out.PrivateKeys = append(out.PrivateKeys, *k.Key)
+	}
+	return out
+}
+
+func PrintP12Infos(p12s []*pkcs12.P12Info, format OutputFormat) {
+	if format == FormatJSON && len(p12s) > 1 {
+		out := make([]p12JSON, 0, len(p12s))
+		for _, p12 := range p12s {
+			out = append(out, newP12JSON(p12))
 		}
 		jsonBytes, err := json.MarshalIndent(out, "", "  ")
 		if err != nil {
@@ -327,6 +614,19 @@ func PrintP12Info(p12 *pkcs12.P12Info, format OutputFormat) {
 		fmt.Println(string(jsonBytes))
 		return
 	}
+	printEach(p12s, format, "PKCS#12 File", PrintP12Info)
+}
+
+func PrintP12Info(p12 *pkcs12.P12Info, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(newP12JSON(p12), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
 	defer w.Flush()
@@ -368,3 +668,636 @@ func PrintP12Info(p12 *pkcs12.P12Info, format OutputFormat) {
 		fmt.Fprintf(w, "\n")
 	}
 }
+
+func PrintVerifyResults(results []*chain.VerifyResult, format OutputFormat) {
+	printEach(results, format, "Certificate", PrintVerifyResult)
+}
+
+func PrintVerifyResult(result *chain.VerifyResult, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	status := "INVALID"
+	statusColor := ColorRed
+	if result.Valid {
+		status = "VALID"
+		statusColor = ColorGreen
+	}
+
+	fmt.Fprintf(w, "Filename:\t%s\n", result.Filename)
+	fmt.Fprintf(w, "Common Name:\t%s\n", result.CommonName)
+	fmt.Fprintf(w, "Verified At:\t%s\n", formatDate(result.VerifiedAt))
+	if result.DNSName != "" {
+		fmt.Fprintf(w, "Host:\t%s\n", result.DNSName)
+	}
+	if len(result.KeyUsages) > 0 {
+		fmt.Fprintf(w, "Key Usages:\t%v\n", result.KeyUsages)
+	}
+	fmt.Fprintf(w, "Result:\t%s\n", Color(status, statusColor))
+	fmt.Fprintf(w, "Paths:\t%d\n", len(result.Paths))
+
+	for i, p := range result.Paths {
+		pathStatus := Color("valid", ColorGreen)
+		if !p.Valid {
+			pathStatus = Color("invalid", ColorRed)
+		}
+		fmt.Fprintf(w, "\n--- Path %d (%s) ---\n", i+1, pathStatus)
+		for j, hop := range p.Hops {
+			fmt.Fprintf(w, "[%d] %s:\t%s\n", j, hop.Source, hop.CommonName)
+			fmt.Fprintf(w, "    Subject:\t%s\n", hop.Subject)
+			fmt.Fprintf(w, "    Issuer:\t%s\n", hop.Issuer)
+			fmt.Fprintf(w, "    Validity:\t%s - %s\n", formatDate(hop.NotBefore), formatDate(hop.NotAfter))
+			for _, problem := range hop.Problems {
+				fmt.Fprintf(w, "    Problem:\t%s: %s\n", Color(string(problem.Kind), ColorRed), problem.Detail)
+			}
+		}
+		for _, problem := range p.Problems {
+			fmt.Fprintf(w, "Problem:\t%s: %s\n", Color(string(problem.Kind), ColorRed), problem.Detail)
+		}
+	}
+}
+
+func PrintFingerprints(fps []fingerprint.Fingerprint, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(fps, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	for i, fp := range fps {
+		if i > 0 {
+			fmt.Fprintf(w, "\n")
+		}
+		fmt.Fprintf(w, "Filename:\t%s\n", fp.Filename)
+		fmt.Fprintf(w, "Type:\t%s\n", fp.Type)
+		if fp.Subject != "" {
+			fmt.Fprintf(w, "Subject:\t%s\n", fp.Subject)
+		}
+		if fp.SHA1 != "" {
+			fmt.Fprintf(w, "SHA-1 Fingerprint:\t%s\n", fp.SHA1)
+			fmt.Fprintf(w, "SHA-256 Fingerprint:\t%s\n", fp.SHA256)
+		}
+		if fp.SPKISHA256 != "" {
+			fmt.Fprintf(w, "SPKI SHA-256 (base64):\t%s\n", fp.SPKISHA256)
+			fmt.Fprintf(w, "SPKI SHA-256 (hex):\t%s\n", fp.SPKISHA256Hex)
+		}
+	}
+}
+
+func matchStatus(ok bool) string {
+	if ok {
+		return Color("MATCH", ColorGreen)
+	}
+	return Color("MISMATCH", ColorRed)
+}
+
+func PrintMatchResult(result *match.Result, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Certificate:\t%s\n", result.CertFile)
+	fmt.Fprintf(w, "Private Key:\t%s\n", result.KeyFile)
+	fmt.Fprintf(w, "Common Name:\t%s\n", result.CommonName)
+	fmt.Fprintf(w, "Key Type:\t%s\n", result.KeyType)
+	fmt.Fprintf(w, "Certificate SPKI:\t%s\n", result.CertSPKI)
+	fmt.Fprintf(w, "Private Key SPKI:\t%s\n", result.KeySPKI)
+	fmt.Fprintf(w, "Result:\t%s\n", matchStatus(result.Match))
+}
+
+func PrintDirectoryMatch(result *match.DirectoryResult, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	if len(result.Pairs) > 0 {
+		fmt.Fprintf(w, "CERTIFICATE\tPRIVATE KEY\tCN\tKEY TYPE\tRESULT\n")
+		for _, p := range result.Pairs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.CertFile, p.KeyFile, p.CommonName, p.KeyType, matchStatus(p.Match))
+		}
+	}
+	w.Flush()
+
+	printList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, item := range items {
+			fmt.Printf("  %s\n", item)
+		}
+	}
+	printList("Orphaned keys", result.OrphanKeys)
+	printList("Certificates without key", result.CertsWithoutKey)
+	printList("Keys not checked (public key cannot be derived)", result.UncheckedKeys)
+
+	fmt.Printf("\n%d pairs, %d mismatched, %d orphaned keys, %d certificates without key\n",
+		len(result.Pairs), result.Mismatches(), len(result.OrphanKeys), len(result.CertsWithoutKey))
+}
+
+func PrintCSRInfos(reqs []*csr.CSRInfo, format OutputFormat) {
+	printEach(reqs, format, "Certificate Request", PrintCSRInfo)
+}
+
+func PrintCSRInfo(req *csr.CSRInfo, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(req, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "Filename:\t%s\n", req.Filename)
+	fmt.Fprintf(w, "Encoding:\t%s\n", req.Encoding)
+	fmt.Fprintf(w, "Common Name:\t%s\n", req.CommonName)
+	fmt.Fprintf(w, "Subject:\t%s\n", req.Subject)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", req.Algorithm)
+	fmt.Fprintf(w, "Key Type:\t%s\n", req.KeyType)
+	fmt.Fprintf(w, "Bits:\t%d\n", req.Bits)
+	fmt.Fprintf(w, "SPKI SHA-256 (base64):\t%s\n", req.SPKISHA256)
+	fmt.Fprintf(w, "Quantum Safe:\t%v\n", req.IsQuantumSafe)
+	if len(req.PQCTypes) > 0 {
+		fmt.Fprintf(w, "PQC Types:\t%v\n", req.PQCTypes)
+	}
+	if req.SignatureValid {
+		fmt.Fprintf(w, "Signature:\t%s\n", Color("valid", ColorGreen))
+	} else {
+		fmt.Fprintf(w, "Signature:\t%s (%s)\n", Color("invalid", ColorRed), req.SignatureError)
+	}
+
+	if len(req.SANs) > 0 {
+		fmt.Fprintf(w, "DNS SANs:\t%v\n", req.SANs)
+	}
+	if len(req.IPSANs) > 0 {
+		fmt.Fprintf(w, "IP SANs:\t%v\n", req.IPSANs)
+	}
+	if len(req.EmailSANs) > 0 {
+		fmt.Fprintf(w, "Email SANs:\t%v\n", req.EmailSANs)
+	}
+	if len(req.URISANs) > 0 {
+		fmt.Fprintf(w, "URI SANs:\t%v\n", req.URISANs)
+	}
+	if len(req.KeyUsage) > 0 {
+		fmt.Fprintf(w, "Key Usage:\t[%s]\n", strings.Join(req.KeyUsage, ", "))
+	}
+	if len(req.ExtKeyUsageStrings) > 0 {
+		fmt.Fprintf(w, "Ext Key Usage:\t%v\n", req.ExtKeyUsageStrings)
+	}
+	if bc := req.BasicConstraints; bc != nil {
+		if bc.PathLen != nil {
+			fmt.Fprintf(w, "Basic Constraints:\tCA:%v, pathLen:%d\n", bc.IsCA, *bc.PathLen)
+		} else {
+			fmt.Fprintf(w, "Basic Constraints:\tCA:%v\n", bc.IsCA)
+		}
+	}
+	for _, ext := range req.Extensions {
+		if ext.Critical {
+			fmt.Fprintf(w, "Requested Extension:\t%s (critical)\n", ext.Name)
+		} else {
+			fmt.Fprintf(w, "Requested Extension:\t%s\n", ext.Name)
+		}
+	}
+}
+
+func PrintCRLInfos(lists []*crl.CRLInfo, format OutputFormat) {
+	printEach(lists, format, "CRL", PrintCRLInfo)
+}
+
+func PrintCRLInfo(list *crl.CRLInfo, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	status := Color("current", ColorGreen)
+	if list.Stale {
+		status = Color("stale", ColorRed)
+	}
+
+	fmt.Fprintf(w, "Filename:\t%s\n", list.Filename)
+	fmt.Fprintf(w, "Encoding:\t%s\n", list.Encoding)
+	fmt.Fprintf(w, "Issuer:\t%s\n", list.IssuerDN)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", list.Algorithm)
+	fmt.Fprintf(w, "This Update:\t%s\n", formatDate(list.ThisUpdate))
+	if !list.NextUpdate.IsZero() {
+		fmt.Fprintf(w, "Next Update:\t%s\n", formatDate(list.NextUpdate))
+	}
+	fmt.Fprintf(w, "Status:\t%s\n", status)
+	if list.Number != "" {
+		fmt.Fprintf(w, "CRL Number:\t%s\n", list.Number)
+	}
+	if list.IsDelta {
+		fmt.Fprintf(w, "Delta CRL:\tYes (base CRL %s)\n", list.BaseCRLNumber)
+	}
+	if list.AuthorityKeyID != "" {
+		fmt.Fprintf(w, "Authority Key ID:\t%s\n", list.AuthorityKeyID)
+	}
+	fmt.Fprintf(w, "Revoked:\t%d\n", len(list.Revoked))
+
+	if len(list.Revoked) > 0 {
+		fmt.Fprintf(w, "\nSERIAL\tREVOKED AT\tREASON\n")
+		for _, entry := range list.Revoked {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.SerialNumber, formatDate(entry.RevocationTime), entry.Reason)
+		}
+	}
+}
+
+func PrintOCSPResponses(resps []*ocsp.ResponseInfo, format OutputFormat) {
+	printEach(resps, format, "OCSP Response", PrintOCSPResponse)
+}
+
+func PrintOCSPResponse(resp *ocsp.ResponseInfo, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	status := resp.Status
+	switch resp.Status {
+	case certificate.RevocationGood:
+		status = Color(status, ColorGreen)
+	case certificate.RevocationRevoked:
+		status = Color(status, ColorRed)
+	default:
+		status = Color(status, ColorYellow)
+	}
+
+	fmt.Fprintf(w, "Source:\t%s\n", resp.Source)
+	if resp.CommonName != "" {
+		fmt.Fprintf(w, "Common Name:\t%s\n", resp.CommonName)
+	}
+	fmt.Fprintf(w, "Serial Number:\t%s\n", resp.SerialNumber)
+	fmt.Fprintf(w, "Cert Status:\t%s\n", status)
+	if resp.Status == certificate.RevocationRevoked {
+		fmt.Fprintf(w, "Revoked At:\t%s\n", formatDate(resp.RevokedAt))
+		fmt.Fprintf(w, "Revocation Reason:\t%s\n", resp.RevocationReason)
+	}
+	fmt.Fprintf(w, "Produced At:\t%s\n", formatDate(resp.ProducedAt))
+	fmt.Fprintf(w, "This Update:\t%s\n", formatDate(resp.ThisUpdate))
+	if !resp.NextUpdate.IsZero() {
+		fmt.Fprintf(w, "Next Update:\t%s\n", formatDate(resp.NextUpdate))
+	}
+	fmt.Fprintf(w, "Responder ID:\t%s\n", resp.ResponderID)
+	fmt.Fprintf(w, "Algorithm:\t%s\n", resp.SignatureAlgorithm)
+	switch {
+	case resp.SignatureValid:
+		fmt.Fprintf(w, "Signature:\t%s\n", Color("valid", ColorGreen))
+	case resp.SignatureChecked:
+		fmt.Fprintf(w, "Signature:\t%s (%s)\n", Color("invalid", ColorRed), resp.SignatureError)
+	default:
+		fmt.Fprintf(w, "Signature:\t%s (%s)\n", Color("not verified", ColorYellow), resp.SignatureError)
+	}
+}
+
+func PrintRemoteResults(results []*remote.Result, format OutputFormat) {
+	printEach(results, format, "Endpoint", PrintRemoteResult)
+}
+
+func PrintRemoteResult(result *remote.Result, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Address:\t%s\n", result.Address)
+	if result.Protocol != "" {
+		fmt.Fprintf(w, "Protocol:\t%s (STARTTLS)\n", result.Protocol)
+	}
+	fmt.Fprintf(w, "TLS Version:\t%s\n", result.TLSVersion)
+	fmt.Fprintf(w, "Cipher Suite:\t%s\n", result.CipherSuite)
+	if result.ALPN != "" {
+		fmt.Fprintf(w, "ALPN:\t%s\n", result.ALPN)
+	} else {
+		fmt.Fprintf(w, "ALPN:\t-\n")
+	}
+	fmt.Fprintf(w, "Key Exchange:\t%s\n", result.KeyExchange)
+
+	sni := result.SNI
+	if sni.ServerName != "" {
+		covers := Color("covered by certificate", ColorGreen)
+		if !sni.CoversServerName {
+			covers = Color("not covered by certificate", ColorRed)
+		}
+		fmt.Fprintf(w, "SNI:\t%s (%s)\n", sni.ServerName, covers)
+		switch {
+		case sni.NoSNIError != "":
+			fmt.Fprintf(w, "Without SNI:\thandshake failed (%s)\n", sni.NoSNIError)
+		case sni.NoSNISameCertificate:
+			fmt.Fprintf(w, "Without SNI:\tsame certificate\n")
+		default:
+			fmt.Fprintf(w, "Without SNI:\tdifferent certificate (%s)\n", sni.NoSNICommonName)
+		}
+	} else {
+		fmt.Fprintf(w, "SNI:\tnot sent\n")
+	}
+
+	if result.Verified {
+		fmt.Fprintf(w, "Verified:\t%s\n", Color("yes", ColorGreen))
+	} else {
+		fmt.Fprintf(w, "Verified:\t%s (%s)\n", Color("no", ColorRed), result.VerifyError)
+	}
+
+	if staple := result.OCSPStaple; staple != nil {
+		fmt.Fprintf(w, "OCSP Staple:\t%s (this update %s)\n", staple.Status, formatDate(staple.ThisUpdate))
+	} else if result.OCSPStapleError != "" {
+		fmt.Fprintf(w, "OCSP Staple:\t%s (%s)\n", Color("invalid", ColorRed), result.OCSPStapleError)
+	} else {
+		fmt.Fprintf(w, "OCSP Staple:\tnone\n")
+	}
+	fmt.Fprintf(w, "Chain Length:\t%d\n", len(result.Chain))
+	w.Flush()
+
+	for _, cert := range result.Chain {
+		fmt.Println()
+		fmt.Printf("--- Certificate %d ---\n", cert.Index+1)
+		PrintCertificateInfo(cert, format)
+	}
+}
+
+func PrintPQCReport(r *report.Report, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	s := r.Summary
+	percent := 0
+	if s.Total > 0 {
+		percent = s.Ready() * 100 / s.Total
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Directory:\t%s\n", r.Directory)
+	fmt.Fprintf(w, "Items:\t%d (%d certificates, %d keys)\n", s.Total, s.Certificates, s.Keys)
+	fmt.Fprintf(w, "Classical:\t%d\n", s.Classical)
+	fmt.Fprintf(w, "Hybrid:\t%d\n", s.Hybrid)
+	fmt.Fprintf(w, "PQC:\t%d\n", s.PQC)
+	fmt.Fprintf(w, "Quantum Ready:\t%d of %d (%d%%)\n", s.Ready(), s.Total, percent)
+	fmt.Fprintf(w, "HNDL Exposure:\t%s high, %d medium\n", Color(fmt.Sprint(s.ExposureHigh), ColorRed), s.ExposureMedium)
+	fmt.Fprintf(w, "NIST IR 8547:\t%s disallowed now, %d deprecated after 2030, %d disallowed after 2035\n",
+		Color(fmt.Sprint(s.DisallowedNow), ColorRed), s.Deprecated2030, s.Disallowed2035)
+	fmt.Fprintf(w, "CNSA 2.0 Compliant:\t%d\n", s.CNSACompliant)
+	w.Flush()
+
+	if len(r.Issuers) > 0 {
+		fmt.Println("\nBy issuer:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "ISSUER\tCERTIFICATES\tCLASSICAL\tHYBRID\tPQC\tHNDL EXPOSED\n")
+		for _, is := range r.Issuers {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", is.Issuer, is.Certificates, is.Classical, is.Hybrid, is.PQC, is.Exposed)
+		}
+		w.Flush()
+	}
+
+	if len(r.MigrateFirst) > 0 {
+		fmt.Println("\nMigrate first:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "#\tFILE\tKIND\tALGORITHM\tHNDL\tNIST IR 8547\tCNSA 2.0\tWHY\n")
+		for i, item := range r.MigrateFirst {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", i+1, item.Filename, item.Kind, item.Algorithm,
+				item.Exposure, item.NISTDates(), item.CNSA(), strings.Join(item.Reasons, "; "))
+		}
+		w.Flush()
+	}
+}
+
+func PrintInventory(inv *scan.Inventory, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(inv, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	dash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "FILE\tKIND\tENCODING\tSUBJECT\tALGORITHM\tDETAIL\n")
+	for _, f := range inv.Files {
+		if len(f.Objects) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\n", f.Filename, Color(string(f.Kind), ColorYellow))
+			continue
+		}
+		for i, o := range f.Objects {
+			name := f.Filename
+			if len(f.Objects) > 1 {
+				name = fmt.Sprintf("%s[%d]", f.Filename, i+1)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, o.Kind, o.Encoding, dash(o.Subject), dash(o.Algorithm), dash(o.Detail))
+		}
+	}
+	w.Flush()
+
+	counts := inv.Counts()
+	var parts []string
+	for _, kind := range scan.Kinds {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	fmt.Printf("\n%d files, %d objects: %s\n", len(inv.Files), inv.Objects(), strings.Join(parts, ", "))
+}
+
+func PrintImageResults(results []*image.Result, format OutputFormat) {
+	printEach(results, format, "Image", PrintImageResult)
+}
+
+func PrintImageResult(r *image.Result, format OutputFormat) {
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Image:\t%s\n", r.Image)
+	fmt.Fprintf(w, "Layers:\t%d\n", len(r.Layers))
+	fmt.Fprintf(w, "Files:\t%d\n", r.Files)
+	fmt.Fprintf(w, "Certificates:\t%d\n", len(r.Certificates))
+	fmt.Fprintf(w, "Private Keys:\t%d\n", len(r.Keys))
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "LAYER\tDIGEST\tFILES\tCREATED BY\n")
+	for _, l := range r.Layers {
+		digest := l.Digest
+		if alg, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+			digest = alg + ":" + hex[:12]
+		}
+		createdBy := strings.TrimSpace(l.CreatedBy)
+		if len(createdBy) > 60 {
+			createdBy = createdBy[:57] + "..."
+		}
+		if createdBy == "" {
+			createdBy = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", l.Number, digest, l.Files, createdBy)
+	}
+	w.Flush()
+
+	if len(r.Certificates) > 0 {
+		certsPerFile := make(map[string]int)
+		for _, c := range r.Certificates {
+			certsPerFile[c.Filename]++
+		}
+		fmt.Println("\nCertificates:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "LAYER\tFILENAME\tCN\tISSUER\tSTATUS\tSTRENGTH\tQUANTUM SAFE\n")
+		for _, c := range r.Certificates {
+			name := c.Filename
+			if certsPerFile[c.Filename] > 1 {
+				name = fmt.Sprintf("%s[%d]", c.Filename, c.Index+1)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Layer, name, c.CommonName, c.Issuer, c.Status, c.Strength.Short(), c.QuantumSafety)
+		}
+		w.Flush()
+	}
+
+	if len(r.Keys) > 0 {
+		fmt.Println("\nPrivate Keys:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "LAYER\tFILENAME\tTYPE\tBITS\tSTRENGTH\tQUANTUM SAFE\n")
+		for _, k := range r.Keys {
+			qs := "Yes"
+			if !k.IsQuantumSafe {
+				qs = "No"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n", k.Layer, k.Filename, k.KeyType, k.Bits, k.Strength.Short(), qs)
+		}
+		w.Flush()
+	}
+}
+
+// PrintDiagnostics reports the files a summary could not use on stderr, so
+// that stdout stays a valid table or JSON document. Unless showAll is set,
+// only a footer counting the warnings is printed.
+func PrintDiagnostics(diags []diag.Diagnostic, format OutputFormat, showAll bool) {
+	if !showAll {
+		warnings := diag.Warnings(diags)
+		if len(warnings) == 0 {
+			return
+		}
+		counts := diag.Count(warnings)
+		var parts []string
+		for _, c := range diag.Categories {
+			if counts[c] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[c], c))
+			}
+		}
+		fmt.Fprintf(os.Stderr, "\n%s %d files could not be read (%s), use --show-errors for details\n",
+			Color("Warning:", ColorYellow), len(warnings), strings.Join(parts, ", "))
+		return
+	}
+
+	if format == FormatJSON {
+		jsonBytes, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, string(jsonBytes))
+		return
+	}
+
+	if len(diags) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "\nSkipped files:")
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "PATH\tTYPE\tCATEGORY\tERROR\n")
+	for _, d := range diags {
+		errText := d.Error
+		if errText == "" {
+			errText = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Path, d.Type, d.Category, errText)
+	}
+	w.Flush()
+}
+
+// PrintCacheStats writes to stderr how many files were found in the scan
+// cache.
+func PrintCacheStats(stats cache.Stats) {
+	fmt.Fprintf(os.Stderr, "%s %d hits, %d misses\n", Color("Cache:", ColorCyan), stats.Hits, stats.Misses)
+}
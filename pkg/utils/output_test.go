@@ -5,11 +5,24 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/marco-introini/certinfo/pkg/cache"
 	"github.com/marco-introini/certinfo/pkg/certificate"
+	"github.com/marco-introini/certinfo/pkg/crl"
+	"github.com/marco-introini/certinfo/pkg/csr"
+	"github.com/marco-introini/certinfo/pkg/diag"
+	"github.com/marco-introini/certinfo/pkg/fingerprint"
+	"github.com/marco-introini/certinfo/pkg/match"
+	"github.com/marco-introini/certinfo/pkg/ocsp"
+	"github.com/marco-introini/certinfo/pkg/pem"
 	"github.com/marco-introini/certinfo/pkg/privatekey"
+	"github.com/marco-introini/certinfo/pkg/remote"
+	"github.com/marco-introini/certinfo/pkg/report"
+	"github.com/marco-introini/certinfo/pkg/scan"
+	"github.com/marco-introini/certinfo/pkg/strength"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -446,3 +459,461 @@ func TestPrintKeySummariesMultiple(t *testing.T) {
 	assert.Contains(t, output, "EC")
 	assert.Contains(t, output, "Ed25519")
 }
+
+func TestPrintCertificateSummariesBundle(t *testing.T) {
+	summaries := []certificate.CertificateSummary{
+		{Filename: "fullchain.pem", Index: 0, CommonName: "leaf", Status: "valid"},
+		{Filename: "fullchain.pem", Index: 1, CommonName: "intermediate", Status: "valid"},
+		{Filename: "single.pem", Index: 0, CommonName: "other", Status: "valid"},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintCertificateSummaries(summaries, FormatTable)
+	})
+	assert.Contains(t, output, "fullchain.pem[1]")
+	assert.Contains(t, output, "fullchain.pem[2]")
+	assert.NotContains(t, output, "single.pem[")
+}
+
+func TestPrintCertificateInfos(t *testing.T) {
+	certs, err := certificate.ParseCertificates(getTestCertPath("chain/fullchain.crt"))
+	require.NoError(t, err)
+
+	output, _ := captureOutput(func() {
+		PrintCertificateInfos(certs, FormatTable)
+	})
+	assert.Contains(t, output, "--- Certificate 1 ---")
+	assert.Contains(t, output, "--- Certificate 2 ---")
+
+	output, _ = captureOutput(func() {
+		PrintCertificateInfos(certs, FormatJSON)
+	})
+	var parsed []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &parsed))
+	assert.Len(t, parsed, 2)
+
+	output, _ = captureOutput(func() {
+		PrintCertificateInfos(certs[:1], FormatJSON)
+	})
+	var single map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &single))
+	assert.Equal(t, "localhost", single["CommonName"])
+
+	ForceJSONArrays()
+	defer func() { jsonArrays = false }()
+	output, _ = captureOutput(func() {
+		PrintCertificateInfos(certs[:1], FormatJSON)
+	})
+	require.NoError(t, json.Unmarshal([]byte(output), &parsed), "a single certificate is an array when several inputs were given")
+	assert.Len(t, parsed, 1)
+}
+
+func TestPrintCertificateInfoExtensions(t *testing.T) {
+	cert, err := certificate.ParseCertificate(getTestCertPath("extensions/extensions.crt"))
+	require.NoError(t, err)
+
+	output, _ := captureOutput(func() {
+		PrintCertificateInfo(cert, FormatTable)
+	})
+	assert.Contains(t, output, "Key Usage:")
+	assert.Contains(t, output, "Certificate Sign")
+	assert.Contains(t, output, "CA:true, pathLen:1")
+	assert.Contains(t, output, "Subject Key ID:")
+	assert.Contains(t, output, "http://crl.test.local/ca.crl")
+	assert.Contains(t, output, "http://ocsp.test.local")
+	assert.Contains(t, output, "http://ca.test.local/ca.crt")
+	assert.Contains(t, output, "Domain Validated")
+	assert.Contains(t, output, "Permitted DNS:")
+	assert.Contains(t, output, "Must Staple:")
+	assert.Contains(t, output, "1.3.6.1.4.1.99999.2")
+
+	output, _ = captureOutput(func() {
+		PrintCertificateInfo(cert, FormatJSON)
+	})
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &parsed))
+	assert.Equal(t, true, parsed["MustStaple"])
+	assert.NotNil(t, parsed["NameConstraints"])
+	assert.NotNil(t, parsed["OtherExtensions"])
+}
+
+func TestPrintCertificateInfoComposite(t *testing.T) {
+	cert := &certificate.CertificateInfo{
+		CommonName:    "composite",
+		QuantumSafety: certificate.QuantumHybrid,
+		KeyComponents: []certificate.Component{
+			{Algorithm: "ML-DSA-65", KeyType: "ML-DSA", Bits: 65, Category: 3, PQC: true, Size: 1952},
+			{Algorithm: "ECDSA P-256", KeyType: "ECDSA", Bits: 256, Size: 65},
+		},
+		AltSignature: &certificate.Component{Algorithm: "ML-DSA-44", Category: 2, PQC: true, Size: 2420},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintCertificateInfo(cert, FormatTable)
+	})
+	assert.Contains(t, output, "hybrid")
+	assert.Contains(t, output, "ML-DSA-65 (NIST category 3, 1952 bytes)")
+	assert.Contains(t, output, "ECDSA P-256 (256 bits, 65 bytes)")
+	assert.Contains(t, output, "Alt Signature:")
+	assert.NotContains(t, output, "Alt Public Key:")
+
+	summaries := []certificate.CertificateSummary{
+		{Filename: "classic.crt", QuantumSafety: certificate.QuantumClassical},
+		{Filename: "composite.crt", QuantumSafety: certificate.QuantumHybrid},
+	}
+	output, _ = captureOutput(func() {
+		PrintCertificateSummaries(summaries, FormatTable)
+	})
+	assert.Contains(t, output, "classical")
+	assert.Contains(t, output, "hybrid")
+}
+
+func TestPrintCertificateInfoSignature(t *testing.T) {
+	DisableColors()
+	tests := []struct {
+		check    certificate.SignatureCheck
+		expected string
+	}{
+		{certificate.SignatureCheck{Status: certificate.SignatureValid, Issuer: "CN=Root"}, "valid [CN=Root]"},
+		{certificate.SignatureCheck{Status: certificate.SignatureInvalid, Issuer: "CN=Root", Error: "invalid signature: ML-DSA-65"}, "invalid (invalid signature: ML-DSA-65) [CN=Root]"},
+		{certificate.SignatureCheck{Status: certificate.SignatureUnsupported, Error: "signature verification not supported: 1.3.9999.3.11"}, "not verified (signature verification not supported: 1.3.9999.3.11)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.check.Status, func(t *testing.T) {
+			check := tt.check
+			output, _ := captureOutput(func() {
+				PrintCertificateInfo(&certificate.CertificateInfo{CommonName: "leaf", Signature: &check}, FormatTable)
+			})
+			assert.Contains(t, output, "Signature:")
+			assert.Contains(t, output, tt.expected)
+		})
+	}
+}
+
+func TestPrintFingerprints(t *testing.T) {
+	fps := []fingerprint.Fingerprint{
+		{Filename: "server.crt", Type: "certificate", Subject: "CN=localhost", SHA1: "AA:BB", SHA256: "CC:DD", SPKISHA256: "pin=", SPKISHA256Hex: "a5"},
+		{Filename: "server.key", Type: "private key", SPKISHA256: "pin=", SPKISHA256Hex: "a5"},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintFingerprints(fps, FormatTable)
+	})
+	assert.Contains(t, output, "SHA-256 Fingerprint:")
+	assert.Contains(t, output, "CC:DD")
+	assert.Contains(t, output, "SPKI SHA-256 (base64):")
+	assert.Equal(t, 1, strings.Count(output, "SHA-1 Fingerprint:"))
+
+	output, _ = captureOutput(func() {
+		PrintFingerprints(fps, FormatJSON)
+	})
+	var parsed []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &parsed))
+	assert.Len(t, parsed, 2)
+}
+
+func TestPrintDirectoryMatch(t *testing.T) {
+	result := &match.DirectoryResult{
+		Directory:       "certs",
+		Pairs:           []match.Pair{{CertFile: "server.crt", KeyFile: "server.key", CommonName: "localhost", KeyType: "RSA", Match: true}},
+		OrphanKeys:      []string{"old.key"},
+		CertsWithoutKey: []string{"ca.crt"},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintDirectoryMatch(result, FormatTable)
+	})
+	assert.Contains(t, output, "server.key")
+	assert.Contains(t, output, "Orphaned keys:")
+	assert.Contains(t, output, "old.key")
+	assert.Contains(t, output, "Certificates without key:")
+	assert.Contains(t, output, "1 pairs, 0 mismatched, 1 orphaned keys, 1 certificates without key")
+}
+
+func TestPrintCSRInfo(t *testing.T) {
+	req := &csr.CSRInfo{
+		Filename:       "req.csr",
+		Encoding:       "PEM",
+		CommonName:     "csr.test.local",
+		KeyType:        "RSA",
+		Bits:           2048,
+		SANs:           []string{"csr.test.local"},
+		SignatureValid: false,
+		SignatureError: "crypto/rsa: verification error",
+		Extensions:     []csr.RequestedExtension{{OID: "2.5.29.15", Name: "Key Usage", Critical: true}},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintCSRInfo(req, FormatTable)
+	})
+	assert.Contains(t, output, "invalid")
+	assert.Contains(t, output, "(crypto/rsa: verification error)")
+	assert.Contains(t, output, "Key Usage (critical)")
+	assert.Contains(t, output, "DNS SANs:")
+}
+
+func TestPrintCRLInfo(t *testing.T) {
+	list := &crl.CRLInfo{
+		Filename:      "ca.crl",
+		IssuerDN:      "CN=Test CRL CA",
+		Number:        "3",
+		IsDelta:       true,
+		BaseCRLNumber: "1",
+		Stale:         true,
+		Revoked:       []crl.RevokedEntry{{SerialNumber: "4097", Reason: "keyCompromise"}},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintCRLInfo(list, FormatTable)
+	})
+	assert.Contains(t, output, "stale")
+	assert.Contains(t, output, "Yes (base CRL 1)")
+	assert.Contains(t, output, "4097")
+	assert.Contains(t, output, "keyCompromise")
+}
+
+func TestPrintOCSPResponse(t *testing.T) {
+	resp := &ocsp.ResponseInfo{
+		Source:           "http://ocsp.test.local",
+		SerialNumber:     "4097",
+		Status:           certificate.RevocationRevoked,
+		RevokedAt:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		RevocationReason: "keyCompromise",
+		ResponderID:      "CN=Test CRL CA",
+		SignatureChecked: true,
+		SignatureError:   "bad OCSP signature",
+	}
+
+	output, _ := captureOutput(func() {
+		PrintOCSPResponse(resp, FormatTable)
+	})
+	assert.Contains(t, output, "Revoked At:")
+	assert.Contains(t, output, "keyCompromise")
+	assert.Contains(t, output, "CN=Test CRL CA")
+	assert.Contains(t, output, "(bad OCSP signature)")
+}
+
+func TestPrintRemoteResult(t *testing.T) {
+	cert, err := certificate.ParseCertificate(getTestCertPath("chain/server.crt"))
+	require.NoError(t, err)
+
+	result := &remote.Result{
+		Address:     "localhost:443",
+		TLSVersion:  "TLS 1.3",
+		CipherSuite: "TLS_AES_128_GCM_SHA256",
+		ALPN:        "h2",
+		KeyExchange: "X25519MLKEM768",
+		SNI:         remote.SNIInfo{ServerName: "localhost", CoversServerName: true, NoSNISameCertificate: true},
+		Verified:    true,
+		Chain:       []*certificate.CertificateInfo{cert},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintRemoteResult(result, FormatTable)
+	})
+	assert.Contains(t, output, "X25519MLKEM768")
+	assert.Contains(t, output, "same certificate")
+	assert.Contains(t, output, "OCSP Staple:")
+	assert.Contains(t, output, "--- Certificate 1 ---")
+
+	result.OCSPStapleError = "asn1: structure error"
+	output, _ = captureOutput(func() {
+		PrintRemoteResult(result, FormatTable)
+	})
+	assert.Contains(t, output, "invalid (asn1: structure error)")
+	assert.Contains(t, output, "TLS_AES_128_GCM_SHA256")
+}
+
+func TestPrintRemoteResultSTARTTLS(t *testing.T) {
+	result := &remote.Result{
+		Address:    "mail.test.local:25",
+		Protocol:   "smtp",
+		TLSVersion: "TLS 1.3",
+	}
+
+	output, _ := captureOutput(func() {
+		PrintRemoteResult(result, FormatTable)
+	})
+	assert.Contains(t, output, "smtp (STARTTLS)")
+	assert.Contains(t, output, "ALPN:")
+}
+
+func TestPrintKeyInfoPQC(t *testing.T) {
+	DisableColors()
+	mismatch := false
+	tests := []struct {
+		name     string
+		pqc      privatekey.PQCKeyInfo
+		expected []string
+	}{
+		{
+			"seed",
+			privatekey.PQCKeyInfo{ParameterSet: "ML-DSA-44", Category: 2, Encoding: "seed", SeedSize: 32, PublicKeySize: 1312},
+			[]string{"ML-DSA-44 (NIST category 2)", "seed (32 bytes)", "1312 bytes"},
+		},
+		{
+			"both",
+			privatekey.PQCKeyInfo{ParameterSet: "ML-KEM-768", Category: 3, Encoding: "both", SeedSize: 64, ExpandedSize: 2400, PublicKeySize: 1184, SeedMatches: &mismatch},
+			[]string{"both (64-byte seed, 2400-byte expanded key)", "expanded key does not match seed"},
+		},
+		{
+			"error",
+			privatekey.PQCKeyInfo{ParameterSet: "ML-KEM-768", Category: 3, Error: "invalid ML-KEM-768 private key"},
+			[]string{"not decoded (invalid ML-KEM-768 private key)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pqc := tt.pqc
+			output, _ := captureOutput(func() {
+				PrintKeyInfo(&privatekey.KeyInfo{Filename: "pqc.key", IsQuantumSafe: true, PQC: &pqc}, FormatTable)
+			})
+			for _, s := range tt.expected {
+				assert.Contains(t, output, s)
+			}
+		})
+	}
+}
+
+func TestPrintStrength(t *testing.T) {
+	DisableColors()
+	weak := strength.Strength{Bits: 63, LimitedBy: "SHA-1 signature"}
+
+	output, _ := captureOutput(func() {
+		PrintCertificateInfo(&certificate.CertificateInfo{CommonName: "sha1", Strength: weak}, FormatTable)
+	})
+	assert.Contains(t, output, "Security Strength:")
+	assert.Contains(t, output, "63 bits, limited by SHA-1 signature")
+
+	output, _ = captureOutput(func() {
+		PrintCertificateSummaries([]certificate.CertificateSummary{{Filename: "sha1.crt", Strength: weak}}, FormatTable)
+	})
+	assert.Contains(t, output, "STRENGTH")
+	assert.Contains(t, output, "63 (SHA-1 signature)")
+
+	output, _ = captureOutput(func() {
+		PrintKeySummaries([]privatekey.KeySummary{{Filename: "mlkem.key", Strength: strength.Strength{Bits: 192, Category: 3}}}, FormatTable)
+	})
+	assert.Contains(t, output, "192 (cat 3)")
+
+	output, _ = captureOutput(func() {
+		PrintKeyInfo(&privatekey.KeyInfo{Filename: "rsa.key", Strength: strength.Strength{Bits: 112}}, FormatTable)
+	})
+	assert.Contains(t, output, "112 bits")
+}
+
+func TestPrintPQCReport(t *testing.T) {
+	DisableColors()
+	r := &report.Report{
+		Directory: "certs",
+		Summary:   report.Summary{Total: 4, Certificates: 3, Keys: 1, Classical: 3, PQC: 1, ExposureHigh: 1, Deprecated2030: 2},
+		Issuers:   []report.IssuerSummary{{Issuer: "RSA CA", Certificates: 2, Classical: 2, Exposed: 1}},
+		MigrateFirst: []report.Item{{
+			Filename:      "tls.crt",
+			Kind:          report.KindCertificate,
+			Algorithm:     "RSA-2048",
+			Exposure:      report.ExposureHigh,
+			Deprecated:    2030,
+			Disallowed:    2035,
+			CNSA2:         report.CNSAReplace,
+			CNSA2Deadline: 2033,
+			Reasons:       []string{"deprecated after 2030", "harvest-now-decrypt-later: used for key exchange"},
+		}},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintPQCReport(r, FormatTable)
+	})
+	assert.Contains(t, output, "Quantum Ready:")
+	assert.Contains(t, output, "1 of 4 (25%)")
+	assert.Contains(t, output, "By issuer:")
+	assert.Contains(t, output, "RSA CA")
+	assert.Contains(t, output, "Migrate first:")
+	assert.Contains(t, output, "deprecated 2030, disallowed 2035")
+	assert.Contains(t, output, "deprecated after 2030; harvest-now-decrypt-later")
+
+	output, _ = captureOutput(func() {
+		PrintPQCReport(r, FormatJSON)
+	})
+	var decoded report.Report
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, r.Summary, decoded.Summary)
+}
+
+func TestPrintInventory(t *testing.T) {
+	DisableColors()
+	inv := &scan.Inventory{
+		Path: "certs",
+		Files: []scan.File{
+			{Filename: "fullchain.crt", Kind: pem.KindBundle, Objects: []scan.Object{
+				{Kind: pem.KindCertificate, Encoding: "PEM", Subject: "CN=localhost", Algorithm: "RSA 2048", Detail: "expires 2027-10-17"},
+				{Kind: pem.KindCertificate, Encoding: "PEM", Subject: "CN=Test CA", Algorithm: "RSA 4096", Detail: "expires 2028-10-16"},
+			}},
+			{Filename: "server.key", Kind: pem.KindPrivateKey, Objects: []scan.Object{
+				{Kind: pem.KindPrivateKey, Encoding: "PEM", Algorithm: "RSA 2048"},
+			}},
+			{Filename: "notes.txt", Kind: pem.KindUnknown},
+		},
+	}
+
+	output, _ := captureOutput(func() {
+		PrintInventory(inv, FormatTable)
+	})
+	assert.Contains(t, output, "FILE")
+	assert.Contains(t, output, "fullchain.crt[2]")
+	assert.Contains(t, output, "CN=Test CA")
+	assert.Contains(t, output, "server.key")
+	assert.Contains(t, output, "notes.txt")
+	assert.Contains(t, output, "3 files, 3 objects: 1 bundle, 1 private key, 1 unknown")
+
+	output, _ = captureOutput(func() {
+		PrintInventory(inv, FormatJSON)
+	})
+	var decoded scan.Inventory
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, *inv, decoded)
+}
+
+func TestPrintDiagnostics(t *testing.T) {
+	DisableColors()
+	diags := []diag.Diagnostic{
+		{Path: "broken.crt", Type: pem.KindCertificate, Category: diag.Corrupt, Error: "x509: malformed certificate"},
+		{Path: "ca.key", Type: pem.KindPrivateKey, Category: diag.Encrypted, Error: "password required"},
+		{Path: "notes.txt", Type: pem.KindUnknown, Category: diag.Unrecognized},
+	}
+
+	output := captureStderr(func() {
+		PrintDiagnostics(diags, FormatTable, false)
+	})
+	assert.Contains(t, output, "Warning: 2 files could not be read (1 corrupt, 1 encrypted), use --show-errors for details")
+
+	output = captureStderr(func() {
+		PrintDiagnostics(diags[2:], FormatTable, false)
+	})
+	assert.Empty(t, output, "files of other types are not warnings")
+
+	output = captureStderr(func() {
+		PrintDiagnostics(diags, FormatTable, true)
+	})
+	assert.Contains(t, output, "Skipped files:")
+	assert.Contains(t, output, "CATEGORY")
+	assert.Contains(t, output, "x509: malformed certificate")
+	assert.Contains(t, output, "notes.txt")
+
+	output = captureStderr(func() {
+		PrintDiagnostics(diags, FormatJSON, true)
+	})
+	var decoded []diag.Diagnostic
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, diags, decoded)
+}
+
+func TestPrintCacheStats(t *testing.T) {
+	DisableColors()
+	output := captureStderr(func() {
+		PrintCacheStats(cache.Stats{Hits: 950, Misses: 50})
+	})
+	assert.Equal(t, "Cache: 950 hits, 50 misses\n", output)
+}
@@ -0,0 +1,141 @@
+package walk
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+// IgnoreFile is the name of the files that list paths the walk skips, with
+// the syntax and semantics of .gitignore.
+const IgnoreFile = ".certinfoignore"
+
+// matchGlob reports whether the slash-separated name matches pattern. A **
+// segment matches any number of path segments; the other segments are
+// path.Match patterns.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range len(name) + 1 {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchPath matches a pattern the way .gitignore does: a pattern without a
+// slash matches the base name at any depth, any other pattern matches the
+// whole path.
+func matchPath(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		return matchGlob(pattern, path.Base(relPath))
+	}
+	return matchGlob(strings.TrimPrefix(pattern, "/"), relPath)
+}
+
+// matchAny reports whether relPath matches one of the patterns.
+func matchAny(patterns []string, relPath string) bool {
+	for _, p := range patterns {
+		if matchPath(p, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules are the rules of one ignore file. dir is the slash-separated
+// path of its directory relative to the walk root, empty for the root.
+type ignoreRules struct {
+	dir   string
+	rules []ignoreRule
+}
+
+// parseIgnoreFile reads the rules of the ignore file in dir, or returns nil
+// when there is none.
+func parseIgnoreFile(filePath, dir string) (*ignoreRules, error) {
+	f, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules := &ignoreRules{dir: dir}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r ignoreRule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		rules.rules = append(rules.rules, r)
+	}
+	return rules, scanner.Err()
+}
+
+// ignored reports whether the ignore files in effect for relPath, from the
+// root down, exclude it. As in .gitignore the last matching rule wins, and a
+// negated rule includes the path again.
+func ignored(chain []*ignoreRules, relPath string, isDir bool) bool {
+	ignore := false
+	for _, rules := range chain {
+		rel := relPath
+		if rules.dir != "" {
+			var ok bool
+			if rel, ok = strings.CutPrefix(relPath, rules.dir+"/"); !ok {
+				continue
+			}
+		}
+		for _, r := range rules.rules {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if matchPath(r.pattern, rel) {
+				ignore = !r.negate
+			}
+		}
+	}
+	return ignore
+}
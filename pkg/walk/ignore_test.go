@@ -0,0 +1,77 @@
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.pem", "server.pem", true},
+		{"*.pem", "certs/deep/server.pem", true},
+		{"*.pem", "server.key", false},
+		{"node_modules", "web/node_modules", true},
+		{"certs/*.pem", "certs/server.pem", true},
+		{"certs/*.pem", "old/certs/server.pem", false},
+		{"/certs", "certs", true},
+		{"**/fixtures", "a/b/fixtures", true},
+		{"**/fixtures", "fixtures", true},
+		{"vendor/**", "vendor/x/y.pem", true},
+		{"a/**/b.pem", "a/b.pem", true},
+		{"a/**/b.pem", "a/x/y/b.pem", true},
+		{"a/**/b.pem", "c/a/x/b.pem", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.match, matchPath(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	dir := t.TempDir()
+	content := "# test fixtures\n\n*.key\n!ca.key\nbuild/\n/top.pem\n\\#hash.pem\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, IgnoreFile), []byte(content), 0644))
+	root, err := parseIgnoreFile(filepath.Join(dir, IgnoreFile), "")
+	require.NoError(t, err)
+	require.Len(t, root.rules, 5)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub"+IgnoreFile), []byte("!other.key\nlocal.pem\n"), 0644))
+	sub, err := parseIgnoreFile(filepath.Join(dir, "sub"+IgnoreFile), "sub")
+	require.NoError(t, err)
+
+	chain := []*ignoreRules{root, sub}
+	tests := []struct {
+		path   string
+		isDir  bool
+		ignore bool
+	}{
+		{"server.key", false, true},
+		{"ca.key", false, false},
+		{"deep/ca.key", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"top.pem", false, true},
+		{"sub/top.pem", false, false},
+		{"#hash.pem", false, true},
+		{"sub/other.key", false, false},
+		{"sub/local.pem", false, true},
+		{"local.pem", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ignore, ignored(chain, tt.path, tt.isDir), tt.path)
+	}
+
+	missing, err := parseIgnoreFile(filepath.Join(dir, "missing"), "")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
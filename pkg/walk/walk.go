@@ -0,0 +1,363 @@
+// Package walk lists the files below a directory and parses them on a
+// bounded pool of workers, keeping the results in walk order.
+package walk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/marco-introini/certinfo/pkg/archive"
+	"github.com/marco-introini/certinfo/pkg/cache"
+)
+
+type Options struct {
+	Recursive bool
+	// MaxDepth limits how many directory levels are walked, counting the
+	// files of the root as level 1; it walks subdirectories without
+	// Recursive. 0 means no limit.
+	MaxDepth int
+	// Include, when set, keeps only the files that match one of these glob
+	// patterns. Exclude skips the files and directories that match one.
+	// As in .gitignore, a pattern without a slash matches the base name at
+	// any depth, any other one the path relative to the root, and **
+	// matches any number of directories.
+	Include []string
+	Exclude []string
+	// MaxFileSize skips the files larger than this many bytes; 0 means no
+	// limit.
+	MaxFileSize int64
+	// FollowSymlinks walks into symbolic links to directories. A link back
+	// into a directory that is already being walked is reported instead of
+	// followed.
+	FollowSymlinks bool
+	// FullPaths names the files by their path including the root, so that
+	// the files of several roots can be told apart.
+	FullPaths bool
+	// Archives walks the entries of zip, jar, tar and tar.gz files, and of
+	// the archives nested in them up to ArchiveDepth levels, instead of the
+	// archive files. Include and Exclude match the path inside the
+	// innermost archive.
+	Archives     bool
+	ArchiveDepth int
+	// Jobs is the number of files parsed at the same time; 0 uses one
+	// worker per CPU.
+	Jobs int
+	// Cache, when set, holds the results of earlier runs, so that the
+	// summarizers only parse the files that changed.
+	Cache *cache.Cache
+}
+
+func (o Options) jobs() int {
+	if o.Jobs > 0 {
+		return o.Jobs
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// maxDepth returns the deepest level walked, or 0 for no limit.
+func (o Options) maxDepth() int {
+	if o.MaxDepth > 0 {
+		return o.MaxDepth
+	}
+	if !o.Recursive {
+		return 1
+	}
+	return 0
+}
+
+// Selects reports whether Include and Exclude keep the file at the
+// slash-separated relPath, for the files that are not on disk: neither the
+// file nor a directory above it matches Exclude, and the file matches
+// Include.
+func (o Options) Selects(relPath string) bool {
+	for p := relPath; p != "." && p != "/"; p = path.Dir(p) {
+		if matchAny(o.Exclude, p) {
+			return false
+		}
+	}
+	return len(o.Include) == 0 || matchAny(o.Include, relPath)
+}
+
+var (
+	// ErrTooLarge is the error of files larger than Options.MaxFileSize.
+	ErrTooLarge = errors.New("file exceeds the size limit")
+	// ErrSymlinkLoop is the error of links back into a directory being
+	// walked.
+	ErrSymlinkLoop = errors.New("symbolic link loop")
+	// ErrSymlinkDir is the error of links to directories that are not
+	// followed.
+	ErrSymlinkDir = errors.New("symbolic link to a directory not followed")
+)
+
+// File is a file found by the walk, or a path the walk skipped or could not
+// enter.
+type File struct {
+	// Path is the path to read the file from.
+	Path string
+	// RelPath is the path relative to the walk root, or the base name when
+	// the root is a file. With Options.FullPaths it is Path.
+	RelPath string
+	// Err is why the walk could not list or enter the path, or skipped it.
+	Err error
+	// Data holds the contents of an archive entry, whose RelPath is the
+	// path of the archive and the path in it joined by archive.Separator,
+	// and whose Path is the path of the archive.
+	Data []byte
+}
+
+// ReadFile returns the contents of the file.
+func (f File) ReadFile() ([]byte, error) {
+	if f.Data != nil {
+		return f.Data, nil
+	}
+	return os.ReadFile(f.Path)
+}
+
+type walker struct {
+	ctx   context.Context
+	opts  Options
+	visit func(File) error
+}
+
+// Walk calls visit for every file below root in lexical order, and for every
+// path it skipped or could not enter. Files excluded by the options or by an
+// IgnoreFile are left out silently. An error is returned when root cannot be
+// read or ctx is done.
+func Walk(ctx context.Context, root string, opts Options, visit func(File) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		file := File{Path: root, RelPath: filepath.Base(root)}
+		if opts.FullPaths {
+			file.RelPath = root
+		}
+		w := &walker{ctx: ctx, opts: opts, visit: visit}
+		if opts.Archives && archive.Format(root) != "" {
+			return w.walkArchive(file)
+		}
+		return visit(file)
+	}
+
+	if opts.FullPaths {
+		relVisit := visit
+		visit = func(f File) error {
+			f.RelPath = filepath.Join(root, f.RelPath)
+			return relVisit(f)
+		}
+	}
+
+	w := &walker{ctx: ctx, opts: opts, visit: visit}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	return w.walkDir(root, "", entries, 1, nil, []os.FileInfo{info})
+}
+
+// walkDir walks the entries of the directory at dirPath. relDir is its
+// slash-separated path relative to the root, depth the level of its
+// entries, chain the ignore files in effect and ancestors the directories
+// being walked, for loop detection.
+func (w *walker) walkDir(dirPath, relDir string, entries []os.DirEntry, depth int, chain []*ignoreRules, ancestors []os.FileInfo) error {
+	rules, err := parseIgnoreFile(filepath.Join(dirPath, IgnoreFile), relDir)
+	if err != nil {
+		if err := w.visit(File{Path: filepath.Join(dirPath, IgnoreFile), RelPath: filepath.FromSlash(path.Join(relDir, IgnoreFile)), Err: err}); err != nil {
+			return err
+		}
+	}
+	if rules != nil {
+		chain = append(chain[:len(chain):len(chain)], rules)
+	}
+
+	for _, entry := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		if entry.Name() == IgnoreFile {
+			continue
+		}
+		if err := w.walkEntry(dirPath, relDir, entry, depth, chain, ancestors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkEntry(dirPath, relDir string, entry os.DirEntry, depth int, chain []*ignoreRules, ancestors []os.FileInfo) error {
+	filePath := filepath.Join(dirPath, entry.Name())
+	rel := path.Join(relDir, entry.Name())
+	file := File{Path: filePath, RelPath: filepath.FromSlash(rel)}
+
+	isDir := entry.IsDir()
+	var info os.FileInfo
+	if entry.Type()&fs.ModeSymlink != 0 {
+		// A dangling link is walked as a file, which fails to read.
+		if target, err := os.Stat(filePath); err == nil {
+			info = target
+			isDir = target.IsDir()
+		}
+	}
+
+	if matchAny(w.opts.Exclude, rel) || ignored(chain, rel, isDir) {
+		return nil
+	}
+
+	if !isDir {
+		isArchive := w.opts.Archives && archive.Format(entry.Name()) != ""
+		if !isArchive && len(w.opts.Include) > 0 && !matchAny(w.opts.Include, rel) {
+			return nil
+		}
+		if w.opts.MaxFileSize > 0 {
+			if info == nil {
+				var err error
+				if info, err = entry.Info(); err != nil {
+					file.Err = err
+					return w.visit(file)
+				}
+			}
+			if info.Size() > w.opts.MaxFileSize {
+				file.Err = fmt.Errorf("%w: %d bytes", ErrTooLarge, info.Size())
+				return w.visit(file)
+			}
+		}
+		if isArchive {
+			return w.walkArchive(file)
+		}
+		return w.visit(file)
+	}
+
+	if limit := w.opts.maxDepth(); limit > 0 && depth >= limit {
+		return nil
+	}
+
+	if info != nil {
+		if !w.opts.FollowSymlinks {
+			file.Err = ErrSymlinkDir
+			return w.visit(file)
+		}
+		for _, a := range ancestors {
+			if os.SameFile(a, info) {
+				file.Err = ErrSymlinkLoop
+				return w.visit(file)
+			}
+		}
+	} else {
+		var err error
+		if info, err = entry.Info(); err != nil {
+			file.Err = err
+			return w.visit(file)
+		}
+	}
+
+	entries, err := os.ReadDir(filePath)
+	if err != nil {
+		file.Err = err
+		return w.visit(file)
+	}
+	return w.walkDir(filePath, rel, entries, depth+1, chain, append(ancestors[:len(ancestors):len(ancestors)], info))
+}
+
+// walkArchive visits the entries of the archive file, or the file with the
+// error that kept it from being read.
+func (w *walker) walkArchive(file File) error {
+	opts := archive.Options{MaxDepth: w.opts.ArchiveDepth}
+	return archive.Walk(w.ctx, file.Path, opts, func(e archive.Entry) error {
+		f := File{Path: file.Path, RelPath: file.RelPath, Err: e.Err, Data: e.Data}
+		if e.Name == "" {
+			return w.visit(f)
+		}
+		f.RelPath += archive.Separator + e.Name
+
+		if e.Err == nil {
+			inner := e.Name
+			if i := strings.LastIndex(inner, archive.Separator); i >= 0 {
+				inner = inner[i+len(archive.Separator):]
+			}
+			if !w.opts.Selects(inner) {
+				return nil
+			}
+			if w.opts.MaxFileSize > 0 && int64(len(e.Data)) > w.opts.MaxFileSize {
+				f.Err, f.Data = fmt.Errorf("%w: %d bytes", ErrTooLarge, len(e.Data)), nil
+			}
+		}
+		return w.visit(f)
+	})
+}
+
+// Map walks root like Walk and calls parse for every file on opts.Jobs
+// workers. The results are returned in walk order, whatever order the
+// workers finish in. When ctx is done the walk stops, the files not yet
+// parsed are skipped and ctx.Err() is returned.
+func Map[T any](ctx context.Context, root string, opts Options, parse func(File) T) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		index int
+		file  File
+	}
+	type result struct {
+		index int
+		value T
+	}
+
+	jobs := make(chan job)
+	results := make(chan result)
+
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		index := 0
+		walkErr = Walk(ctx, root, opts, func(f File) error {
+			select {
+			case jobs <- job{index, f}:
+				index++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for range opts.jobs() {
+		wg.Go(func() {
+			for j := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				results <- result{j.index, parse(j.file)}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var values []T
+	for r := range results {
+		if r.index >= len(values) {
+			values = append(values, make([]T, r.index+1-len(values))...)
+		}
+		values[r.index] = r.value
+	}
+
+	if walkErr == nil {
+		walkErr = ctx.Err()
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return values, nil
+}
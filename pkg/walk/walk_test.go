@@ -0,0 +1,235 @@
+package walk
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTree creates the files at the given relative paths below a new
+// directory.
+func makeTree(t testing.TB, paths ...string) string {
+	root := t.TempDir()
+	for _, p := range paths {
+		path := filepath.Join(root, p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(p), 0644))
+	}
+	return root
+}
+
+func relPaths(t *testing.T, root string, opts Options) []string {
+	var paths []string
+	err := Walk(context.Background(), root, opts, func(f File) error {
+		require.NoError(t, f.Err)
+		paths = append(paths, f.RelPath)
+		return nil
+	})
+	require.NoError(t, err)
+	return paths
+}
+
+func TestWalk(t *testing.T) {
+	root := makeTree(t, "b.pem", "a.pem", "sub/c.pem", "sub/deep/d.pem")
+
+	assert.Equal(t, []string{"a.pem", "b.pem"}, relPaths(t, root, Options{}))
+	assert.Equal(t, []string{"a.pem", "b.pem", filepath.Join("sub", "c.pem"), filepath.Join("sub", "deep", "d.pem")},
+		relPaths(t, root, Options{Recursive: true}))
+
+	assert.Equal(t, []string{"a.pem"}, relPaths(t, filepath.Join(root, "a.pem"), Options{}))
+
+	assert.Equal(t, []string{filepath.Join(root, "a.pem"), filepath.Join(root, "b.pem")}, relPaths(t, root, Options{FullPaths: true}))
+	assert.Equal(t, []string{filepath.Join(root, "a.pem")}, relPaths(t, filepath.Join(root, "a.pem"), Options{FullPaths: true}))
+
+	link := filepath.Join(t.TempDir(), "link")
+	require.NoError(t, os.Symlink(root, link))
+	assert.Equal(t, []string{"a.pem", "b.pem"}, relPaths(t, link, Options{}))
+
+	err := Walk(context.Background(), "/nonexistent/path", Options{}, func(File) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestWalkFilters(t *testing.T) {
+	root := makeTree(t, "a.pem", "b.key", "certs/c.pem", "certs/deep/d.pem", "node_modules/x/e.pem", "vendor/f.pem")
+	join := filepath.Join
+
+	assert.Equal(t, []string{"a.pem", join("certs", "c.pem"), join("certs", "deep", "d.pem"), join("node_modules", "x", "e.pem"), join("vendor", "f.pem")},
+		relPaths(t, root, Options{Recursive: true, Include: []string{"*.pem"}}))
+	assert.Equal(t, []string{"a.pem", "b.key", join("certs", "c.pem"), join("certs", "deep", "d.pem")},
+		relPaths(t, root, Options{Recursive: true, Exclude: []string{"node_modules", "vendor/**"}}))
+	assert.Equal(t, []string{"a.pem", join("certs", "c.pem")},
+		relPaths(t, root, Options{Include: []string{"*.pem"}, Exclude: []string{"deep", "node_modules", "vendor"}, Recursive: true}))
+
+	assert.Equal(t, []string{"a.pem", "b.key", join("certs", "c.pem"), join("vendor", "f.pem")},
+		relPaths(t, root, Options{MaxDepth: 2}), "MaxDepth walks subdirectories without Recursive")
+	assert.Equal(t, []string{"a.pem", "b.key"}, relPaths(t, root, Options{Recursive: true, MaxDepth: 1}))
+}
+
+func TestWalkIgnoreFile(t *testing.T) {
+	root := makeTree(t, "a.pem", "b.key", "ca.key", "build/c.pem", "certs/d.pem", "certs/e.pem")
+	require.NoError(t, os.WriteFile(filepath.Join(root, IgnoreFile), []byte("*.key\n!ca.key\nbuild/\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "certs", IgnoreFile), []byte("e.pem\n"), 0644))
+
+	assert.Equal(t, []string{"a.pem", "ca.key", filepath.Join("certs", "d.pem")}, relPaths(t, root, Options{Recursive: true}))
+}
+
+func TestWalkMaxFileSize(t *testing.T) {
+	root := makeTree(t, "a.pem", "large.pem")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "large.pem"), make([]byte, 2048), 0644))
+
+	var files []File
+	err := Walk(context.Background(), root, Options{MaxFileSize: 1024}, func(f File) error {
+		files = append(files, f)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.NoError(t, files[0].Err)
+	assert.ErrorIs(t, files[1].Err, ErrTooLarge)
+}
+
+func TestWalkSymlinks(t *testing.T) {
+	root := makeTree(t, "a.pem", "real/b.pem")
+	require.NoError(t, os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "linked")))
+	require.NoError(t, os.Symlink(root, filepath.Join(root, "real", "loop")))
+
+	walkAll := func(opts Options) map[string]error {
+		files := make(map[string]error)
+		err := Walk(context.Background(), root, opts, func(f File) error {
+			files[f.RelPath] = f.Err
+			return nil
+		})
+		require.NoError(t, err)
+		return files
+	}
+
+	files := walkAll(Options{Recursive: true})
+	assert.Len(t, files, 4)
+	assert.NoError(t, files[filepath.Join("real", "b.pem")])
+	assert.ErrorIs(t, files["linked"], ErrSymlinkDir)
+	assert.ErrorIs(t, files[filepath.Join("real", "loop")], ErrSymlinkDir)
+
+	files = walkAll(Options{Recursive: true, FollowSymlinks: true})
+	assert.Len(t, files, 5)
+	assert.NoError(t, files[filepath.Join("linked", "b.pem")])
+	assert.ErrorIs(t, files[filepath.Join("linked", "loop")], ErrSymlinkLoop)
+	assert.ErrorIs(t, files[filepath.Join("real", "loop")], ErrSymlinkLoop)
+}
+
+func TestMap(t *testing.T) {
+	var paths []string
+	for i := range 50 {
+		paths = append(paths, filepath.Join(string(rune('a'+i%5)), string(rune('a'+i/5))+".pem"))
+	}
+	root := makeTree(t, paths...)
+
+	// Random delays make the workers finish out of order.
+	parse := func(f File) string {
+		data, err := os.ReadFile(f.Path)
+		require.NoError(t, err)
+		time.Sleep(time.Duration(rand.IntN(500)) * time.Microsecond)
+		return string(data)
+	}
+
+	sequential, err := Map(context.Background(), root, Options{Recursive: true, Jobs: 1}, parse)
+	require.NoError(t, err)
+	require.Len(t, sequential, len(paths))
+	assert.IsIncreasing(t, sequential)
+
+	for _, jobs := range []int{0, 4, 16} {
+		parallel, err := Map(context.Background(), root, Options{Recursive: true, Jobs: jobs}, parse)
+		require.NoError(t, err)
+		assert.Equal(t, sequential, parallel, "jobs %d", jobs)
+	}
+
+	empty, err := Map(context.Background(), t.TempDir(), Options{}, parse)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestMapCancel(t *testing.T) {
+	root := makeTree(t, "a.pem", "b.pem", "c.pem", "d.pem")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := Map(ctx, root, Options{Jobs: 1}, func(f File) string {
+		cancel()
+		return f.RelPath
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMapErrors(t *testing.T) {
+	root := makeTree(t, "a.pem")
+	require.NoError(t, os.Symlink(filepath.Join(root, "missing.pem"), filepath.Join(root, "dangling.pem")))
+
+	files, err := Map(context.Background(), root, Options{}, func(f File) File { return f })
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "a.pem", files[0].RelPath)
+	assert.Equal(t, "dangling.pem", files[1].RelPath)
+	_, err = os.ReadFile(files[1].Path)
+	assert.Error(t, err, "a dangling link is listed and fails to read")
+
+	_, err = Map(context.Background(), "/nonexistent/path", Options{}, func(f File) File { return f })
+	assert.Error(t, err)
+}
+
+func TestWalkArchives(t *testing.T) {
+	root := makeTree(t, "a.pem")
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"certs/server.pem", "certs/server.key", "README.md"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bundle.jar"), buf.Bytes(), 0644))
+
+	assert.Equal(t, []string{"a.pem", "bundle.jar"}, relPaths(t, root, Options{}), "archives are plain files by default")
+
+	var files []File
+	err := Walk(context.Background(), root, Options{Archives: true, Include: []string{"*.pem", "*.key"}, Exclude: []string{"*.key"}}, func(f File) error {
+		files = append(files, f)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "a.pem", files[0].RelPath)
+	assert.Nil(t, files[0].Data)
+	assert.Equal(t, "bundle.jar!/certs/server.pem", files[1].RelPath)
+	assert.Equal(t, filepath.Join(root, "bundle.jar"), files[1].Path)
+	data, err := files[1].ReadFile()
+	require.NoError(t, err)
+	assert.Equal(t, "certs/server.pem", string(data))
+
+	files = nil
+	err = Walk(context.Background(), filepath.Join(root, "bundle.jar"), Options{Archives: true, MaxFileSize: 10}, func(f File) error {
+		files = append(files, f)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 3, "an archive root is opened")
+	assert.ErrorIs(t, files[0].Err, ErrTooLarge)
+	assert.NoError(t, files[2].Err)
+}
+
+func TestSelects(t *testing.T) {
+	opts := Options{Include: []string{"*.pem", "*.crt"}, Exclude: []string{"node_modules", "/usr/share/doc/**"}}
+	assert.True(t, opts.Selects("etc/ssl/certs/ca.pem"))
+	assert.False(t, opts.Selects("etc/ssl/private/server.key"))
+	assert.False(t, opts.Selects("app/node_modules/x/ca.pem"), "a file below an excluded directory")
+	assert.False(t, opts.Selects("usr/share/doc/openssl/demo.crt"))
+	assert.True(t, Options{}.Selects("any/file"))
+}